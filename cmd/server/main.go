@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"go-backend/internal/authz"
 	"go-backend/internal/config"
 	"go-backend/internal/database"
 	"go-backend/internal/handlers"
@@ -51,11 +52,29 @@ func main() {
 		log.WithError(err).Fatal("Failed to seed database")
 	}
 
+	// Initialize authorization policy engine
+	policy := authz.DefaultPolicy()
+	if cfg.Authz.PolicyFile != "" {
+		if policy, err = authz.LoadPolicy(cfg.Authz.PolicyFile); err != nil {
+			log.WithError(err).Fatal("Failed to load authorization policy")
+		}
+	}
+	authz.SetDefault(authz.NewEngine(policy, cfg.Authz.Debug, log))
+	log.WithFields(logrus.Fields{
+		"rules": len(policy.Rules),
+		"debug": cfg.Authz.Debug,
+	}).Info("Authorization policy loaded")
+
 	// Initialize JWT service
 	jwtService := utils.NewJWTService(cfg)
 
 	// Initialize router
-	router := handlers.NewRouter(db, log, jwtService, cfg.CORS.Origins)
+	router := handlers.NewRouter(db, log, jwtService, cfg)
+
+	// Start background workers such as the scheduled post publisher
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	router.StartWorkers(workerCtx)
 
 	// Create HTTP server
 	server := &http.Server{
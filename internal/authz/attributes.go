@@ -0,0 +1,246 @@
+package authz
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+// Attribute scopes used in condition paths
+const (
+	scopeSubject     = "subject"
+	scopeResource    = "resource"
+	scopeEnvironment = "env"
+	scopeRelation    = "relation"
+)
+
+// Attributes holds the attributes of a subject, resource or environment
+type Attributes map[string]interface{}
+
+// Subject is the user performing an action
+type Subject struct {
+	ID   uint
+	Role models.Role
+	// Roles are additional roles held by the subject, e.g. through groups
+	Roles         []models.Role
+	Active        bool
+	EmailVerified bool
+}
+
+// RoleResolver returns the roles a user holds besides their own role
+type RoleResolver func(userID uint) ([]models.Role, error)
+
+var roleResolver atomic.Pointer[RoleResolver]
+
+// SetRoleResolver sets the resolver SubjectFromUser uses to find the extra
+// roles a user holds
+func SetRoleResolver(resolver RoleResolver) {
+	roleResolver.Store(&resolver)
+}
+
+// SubjectFromUser builds a subject from a user. A nil user is anonymous.
+func SubjectFromUser(user *models.User) *Subject {
+	if user == nil {
+		return nil
+	}
+
+	return &Subject{
+		ID:            user.ID,
+		Role:          user.Role,
+		Roles:         resolveRoles(user.ID),
+		Active:        user.IsActive && !user.IsSuspended(),
+		EmailVerified: user.EmailVerified,
+	}
+}
+
+// resolveRoles returns the roles a user holds besides their own role, or
+// none without a role resolver
+func resolveRoles(userID uint) []models.Role {
+	if resolver := roleResolver.Load(); resolver != nil {
+		if roles, err := (*resolver)(userID); err == nil {
+			return roles
+		}
+	}
+	return nil
+}
+
+// roleNames returns the names of the held roles and every role they inherit
+// from
+func roleNames(held []models.Role) []string {
+	seen := make(map[models.Role]bool)
+	names := make([]string, 0)
+	for _, role := range held {
+		for _, ancestor := range role.Ancestors() {
+			if !seen[ancestor] {
+				seen[ancestor] = true
+				names = append(names, string(ancestor))
+			}
+		}
+	}
+	return names
+}
+
+// attributes returns the subject attributes. Roles include every role the
+// subject holds and every role those roles inherit from.
+func (s *Subject) attributes() Attributes {
+	if s == nil {
+		return Attributes{
+			"authenticated": false,
+			"roles":         []string{},
+		}
+	}
+
+	return Attributes{
+		"authenticated":  true,
+		"id":             s.ID,
+		"role":           string(s.Role),
+		"roles":          roleNames(append([]models.Role{s.Role}, s.Roles...)),
+		"active":         s.Active,
+		"email_verified": s.EmailVerified,
+	}
+}
+
+// Resource is anything that can be the target of an action
+type Resource interface {
+	AuthzType() string
+	AuthzAttributes() Attributes
+}
+
+// Object is a generic resource described by its type and attributes
+type Object struct {
+	Type       string
+	Attributes Attributes
+}
+
+// AuthzType returns the resource type
+func (o Object) AuthzType() string {
+	return o.Type
+}
+
+// AuthzAttributes returns the resource attributes
+func (o Object) AuthzAttributes() Attributes {
+	if o.Attributes == nil {
+		return Attributes{}
+	}
+	return o.Attributes
+}
+
+// Kind is a resource type without a specific instance, used for checks such
+// as creating a post or bulk deleting posts
+type Kind string
+
+// AuthzType returns the resource type
+func (k Kind) AuthzType() string {
+	return string(k)
+}
+
+// AuthzAttributes returns no attributes
+func (k Kind) AuthzAttributes() Attributes {
+	return Attributes{}
+}
+
+// ResourceOf adapts a model to a Resource. Like a subject's, a user's roles
+// include their group roles and every role those roles inherit from.
+func ResourceOf(resource interface{}) (Resource, bool) {
+	switch r := resource.(type) {
+	case Resource:
+		return r, true
+	case *models.Post:
+		return Object{Type: "post", Attributes: Attributes{
+			"id":         r.ID,
+			"owner_id":   r.UserID,
+			"deleted_by": deletedBy(r.DeletedBy),
+			"published":  r.Published,
+			"status":     string(r.Status),
+		}}, true
+	case *models.Comment:
+		return Object{Type: "comment", Attributes: Attributes{
+			"id":         r.ID,
+			"owner_id":   r.UserID,
+			"deleted_by": deletedBy(r.DeletedBy),
+			"post_id":    r.PostID,
+			"status":     string(r.Status),
+		}}, true
+	case *models.FileUpload:
+		return Object{Type: "file", Attributes: Attributes{
+			"id":         r.ID,
+			"owner_id":   r.UserID,
+			"deleted_by": deletedBy(r.DeletedBy),
+			"is_public":  r.IsPublic,
+			"category":   r.Category,
+			"file_type":  r.FileType,
+		}}, true
+	case *models.User:
+		return Object{Type: "user", Attributes: Attributes{
+			"id":       r.ID,
+			"owner_id": r.ID,
+			"role":     string(r.Role),
+			"roles":    roleNames(append([]models.Role{r.Role}, resolveRoles(r.ID)...)),
+			"active":   r.IsActive,
+		}}, true
+	}
+	return nil, false
+}
+
+// deletedBy returns who moved a resource to the trash, or nil if it is not in
+// the trash or was deleted before deletions were recorded
+func deletedBy(id *uint) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// RelationResolver returns attributes describing how a subject relates to a
+// resource, such as the access the resource was shared with them at
+type RelationResolver func(ctx context.Context, subject *Subject, resource Resource) Attributes
+
+var relationResolver atomic.Pointer[RelationResolver]
+
+// SetRelationResolver sets the resolver for attributes under the "relation"
+// scope
+func SetRelationResolver(resolver RelationResolver) {
+	relationResolver.Store(&resolver)
+}
+
+// relationFrom returns the relation attributes between subject and resource
+func relationFrom(ctx context.Context, subject *Subject, resource Resource) Attributes {
+	resolver := relationResolver.Load()
+	if resolver == nil || subject == nil {
+		return Attributes{}
+	}
+	if relation := (*resolver)(ctx, subject, resource); relation != nil {
+		return relation
+	}
+	return Attributes{}
+}
+
+type environmentKey struct{}
+
+// WithEnvironment returns a context carrying request environment attributes,
+// such as the client IP, for use in conditions under the "env" scope
+func WithEnvironment(ctx context.Context, env Attributes) context.Context {
+	return context.WithValue(ctx, environmentKey{}, env)
+}
+
+// environmentFrom returns the environment attributes for a request. The
+// current time is always available as env.hour and env.weekday.
+func environmentFrom(ctx context.Context, now time.Time) Attributes {
+	env := Attributes{
+		"time":    now.Format(time.RFC3339),
+		"hour":    now.Hour(),
+		"weekday": now.Weekday().String(),
+	}
+
+	if ctx != nil {
+		if extra, ok := ctx.Value(environmentKey{}).(Attributes); ok {
+			for key, value := range extra {
+				env[key] = value
+			}
+		}
+	}
+
+	return env
+}
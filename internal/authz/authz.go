@@ -0,0 +1,115 @@
+// Package authz implements attribute-based access control. Declarative rules
+// loaded from a policy file are evaluated over the subject, resource, action,
+// the subject's relation to the resource and the request environment.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"go-backend/internal/models"
+)
+
+var defaultEngine atomic.Pointer[Engine]
+
+// SetDefault sets the engine used by the package-level functions
+func SetDefault(engine *Engine) {
+	defaultEngine.Store(engine)
+}
+
+// Default returns the engine used by the package-level functions. Until
+// SetDefault is called it evaluates the built-in policy.
+func Default() *Engine {
+	if engine := defaultEngine.Load(); engine != nil {
+		return engine
+	}
+	engine := NewEngine(DefaultPolicy(), false, nil)
+	defaultEngine.CompareAndSwap(nil, engine)
+	return defaultEngine.Load()
+}
+
+// Can reports whether the user may perform the action on the resource. The
+// user may be nil for anonymous access.
+func Can(ctx context.Context, user *models.User, action string, resource interface{}) bool {
+	return Explain(ctx, user, action, resource).Allowed
+}
+
+// Explain evaluates an access check and returns the full decision, including
+// the rule that decided it and how each applicable rule evaluated
+func Explain(ctx context.Context, user *models.User, action string, resource interface{}) Decision {
+	return ExplainSubject(ctx, SubjectFromUser(user), action, resource)
+}
+
+// ExplainSubject is like Explain for callers that only have the subject's
+// attributes, such as middleware reading them from a token
+func ExplainSubject(ctx context.Context, subject *Subject, action string, resource interface{}) Decision {
+	target, ok := ResourceOf(resource)
+	if !ok {
+		return Decision{
+			Action: action,
+			Reason: fmt.Sprintf("unsupported resource type %T", resource),
+		}
+	}
+
+	return Default().Evaluate(ctx, Request{
+		Subject:  subject,
+		Action:   action,
+		Resource: target,
+	})
+}
+
+// Entitlements evaluates each action the policy names on each resource type
+// it names, without a specific resource, and returns the decisions keyed by
+// "resource:action", e.g. "post:create". Wildcards are not expanded.
+func Entitlements(ctx context.Context, subject *Subject) map[string]bool {
+	engine := Default()
+
+	var actions, resources []string
+	seen := make(map[string]bool)
+	for _, rule := range engine.Policy().Rules {
+		for _, action := range rule.Actions {
+			if action != "*" && !seen["action:"+action] {
+				seen["action:"+action] = true
+				actions = append(actions, action)
+			}
+		}
+		for _, resource := range rule.Resources {
+			if resource != "*" && !seen["resource:"+resource] {
+				seen["resource:"+resource] = true
+				resources = append(resources, resource)
+			}
+		}
+	}
+
+	entitlements := make(map[string]bool, len(actions)*len(resources))
+	for _, resource := range resources {
+		for _, action := range actions {
+			decision := engine.Evaluate(ctx, Request{
+				Subject:  subject,
+				Action:   action,
+				Resource: Kind(resource),
+			})
+			entitlements[resource+":"+action] = decision.Allowed
+		}
+	}
+	return entitlements
+}
+
+// Check returns a *DeniedError if the user may not perform the action
+func Check(ctx context.Context, user *models.User, action string, resource interface{}) error {
+	decision := Explain(ctx, user, action, resource)
+	if !decision.Allowed {
+		return &DeniedError{Decision: decision}
+	}
+	return nil
+}
+
+// DeniedError is returned when an access check fails
+type DeniedError struct {
+	Decision Decision
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("access denied: %s", e.Decision.Reason)
+}
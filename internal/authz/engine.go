@@ -0,0 +1,279 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-backend/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Request describes an access check
+type Request struct {
+	Subject  *Subject
+	Action   string
+	Resource Resource
+}
+
+// RuleResult records how a single applicable rule evaluated
+type RuleResult struct {
+	Rule    string `json:"rule"`
+	Effect  string `json:"effect"`
+	Matched bool   `json:"matched"`
+	// Failed is the first condition that did not hold
+	Failed string `json:"failed,omitempty"`
+}
+
+// Decision is the outcome of an access check
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Action  string `json:"action"`
+	// Resource is the resource type
+	Resource string `json:"resource"`
+	// Rule is the rule that decided the outcome, empty for the default deny
+	Rule   string       `json:"rule,omitempty"`
+	Reason string       `json:"reason"`
+	Trace  []RuleResult `json:"trace,omitempty"`
+}
+
+// Engine evaluates access requests against a policy. Any matching deny rule
+// overrides matching allow rules, and requests matching no allow rule are
+// denied.
+type Engine struct {
+	policy *Policy
+	debug  bool
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewEngine creates a policy engine. In debug mode every decision is logged.
+func NewEngine(policy *Policy, debug bool, logger *logger.Logger) *Engine {
+	return &Engine{
+		policy: policy,
+		debug:  debug,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Debug reports whether the engine runs in debug mode
+func (e *Engine) Debug() bool {
+	return e.debug
+}
+
+// Policy returns the policy the engine evaluates
+func (e *Engine) Policy() *Policy {
+	return e.policy
+}
+
+// Evaluate decides whether the request is allowed
+func (e *Engine) Evaluate(ctx context.Context, req Request) Decision {
+	decision := Decision{Action: req.Action}
+	if req.Resource == nil {
+		decision.Reason = "no resource given"
+		return e.finish(req, decision)
+	}
+	decision.Resource = req.Resource.AuthzType()
+
+	attrs := map[string]Attributes{
+		scopeSubject:     req.Subject.attributes(),
+		scopeResource:    req.Resource.AuthzAttributes(),
+		scopeEnvironment: environmentFrom(ctx, e.now()),
+		scopeRelation:    relationFrom(ctx, req.Subject, req.Resource),
+	}
+
+	var allowedBy, deniedBy string
+	for _, rule := range e.policy.Rules {
+		if !rule.matches(req.Action, decision.Resource) {
+			continue
+		}
+
+		result := RuleResult{Rule: rule.ID, Effect: rule.Effect, Matched: true}
+		for _, cond := range rule.Conditions {
+			if ok, detail := evaluateCondition(cond, attrs); !ok {
+				result.Matched = false
+				result.Failed = detail
+				break
+			}
+		}
+		decision.Trace = append(decision.Trace, result)
+
+		if !result.Matched {
+			continue
+		}
+		if rule.Effect == EffectDeny && deniedBy == "" {
+			deniedBy = rule.ID
+		}
+		if rule.Effect == EffectAllow && allowedBy == "" {
+			allowedBy = rule.ID
+		}
+	}
+
+	switch {
+	case deniedBy != "":
+		decision.Rule = deniedBy
+		decision.Reason = fmt.Sprintf("denied by rule %s", deniedBy)
+	case allowedBy != "":
+		decision.Allowed = true
+		decision.Rule = allowedBy
+		decision.Reason = fmt.Sprintf("allowed by rule %s", allowedBy)
+	case len(decision.Trace) == 0:
+		decision.Reason = fmt.Sprintf("no rule covers %s on %s", req.Action, decision.Resource)
+	default:
+		decision.Reason = fmt.Sprintf("no rule allows %s on %s", req.Action, decision.Resource)
+	}
+
+	return e.finish(req, decision)
+}
+
+// finish logs the decision in debug mode
+func (e *Engine) finish(req Request, decision Decision) Decision {
+	if e.debug && e.logger != nil {
+		var subjectID uint
+		if req.Subject != nil {
+			subjectID = req.Subject.ID
+		}
+		e.logger.WithFields(logrus.Fields{
+			"subject_id": subjectID,
+			"action":     decision.Action,
+			"resource":   decision.Resource,
+			"allowed":    decision.Allowed,
+			"rule":       decision.Rule,
+			"reason":     decision.Reason,
+		}).Info("Authorization decision")
+	}
+	return decision
+}
+
+// evaluateCondition evaluates a condition, returning a description of the
+// comparison when it does not hold
+func evaluateCondition(cond Condition, attrs map[string]Attributes) (bool, string) {
+	actual, exists := lookup(attrs, cond.Attribute)
+
+	if cond.Operator == OpExists {
+		want := cond.Value == nil || cond.Value == true
+		if exists != want {
+			return false, fmt.Sprintf("%s: exists is %t", cond, exists)
+		}
+		return true, ""
+	}
+
+	if !exists {
+		return false, fmt.Sprintf("%s: %s is not set", cond, cond.Attribute)
+	}
+
+	expected := cond.Value
+	if cond.Ref != "" {
+		var ok bool
+		if expected, ok = lookup(attrs, cond.Ref); !ok {
+			return false, fmt.Sprintf("%s: %s is not set", cond, cond.Ref)
+		}
+	}
+
+	var ok bool
+	switch cond.Operator {
+	case OpEq:
+		ok = equal(actual, expected)
+	case OpNe:
+		ok = !equal(actual, expected)
+	case OpIn:
+		ok = contains(expected, actual)
+	case OpNotIn:
+		ok = !contains(expected, actual)
+	case OpIncludes:
+		ok = contains(actual, expected)
+	case OpExcludes:
+		ok = isList(actual) && !contains(actual, expected)
+	case OpGt, OpGte, OpLt, OpLte:
+		ok = compare(cond.Operator, actual, expected)
+	}
+
+	if !ok {
+		return false, fmt.Sprintf("%s: got %v, want %v", cond, actual, expected)
+	}
+	return true, ""
+}
+
+// lookup resolves a scope.name attribute path
+func lookup(attrs map[string]Attributes, path string) (interface{}, bool) {
+	scope, name, _ := strings.Cut(path, ".")
+	value, ok := attrs[scope][name]
+	return value, ok
+}
+
+// normalize converts numbers to float64 and string kinds to string so values
+// decoded from JSON compare equal to typed model attributes
+func normalize(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	return value
+}
+
+func equal(a, b interface{}) bool {
+	x, y := normalize(a), normalize(b)
+	if x == nil || y == nil {
+		return x == y
+	}
+	// Slices and maps cannot be compared with ==
+	if !reflect.TypeOf(x).Comparable() || !reflect.TypeOf(y).Comparable() {
+		return false
+	}
+	return x == y
+}
+
+// contains checks if list is a slice containing value
+func contains(list, value interface{}) bool {
+	if !isList(list) {
+		return false
+	}
+	v := reflect.ValueOf(list)
+	for i := 0; i < v.Len(); i++ {
+		if equal(v.Index(i).Interface(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isList reports whether value is a slice or array
+func isList(value interface{}) bool {
+	kind := reflect.ValueOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// compare orders two numbers
+func compare(op string, a, b interface{}) bool {
+	x, ok := normalize(a).(float64)
+	if !ok {
+		return false
+	}
+	y, ok := normalize(b).(float64)
+	if !ok {
+		return false
+	}
+
+	switch op {
+	case OpGt:
+		return x > y
+	case OpGte:
+		return x >= y
+	case OpLt:
+		return x < y
+	case OpLte:
+		return x <= y
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEngine(t *testing.T, policy string) *Engine {
+	t.Helper()
+	parsed, err := ParsePolicy([]byte(policy))
+	require.NoError(t, err)
+	return NewEngine(parsed, false, nil)
+}
+
+func TestEvaluateDenyOverridesAllow(t *testing.T) {
+	engine := testEngine(t, `{"rules": [
+		{"id": "allow-all", "effect": "allow", "actions": ["*"], "resources": ["*"]},
+		{"id": "deny-delete", "effect": "deny", "actions": ["delete"], "resources": ["post"]}
+	]}`)
+	subject := &Subject{ID: 1, Role: models.RoleUser, Active: true}
+
+	decision := engine.Evaluate(context.Background(), Request{Subject: subject, Action: "delete", Resource: Kind("post")})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "deny-delete", decision.Rule)
+
+	decision = engine.Evaluate(context.Background(), Request{Subject: subject, Action: "read", Resource: Kind("post")})
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "allow-all", decision.Rule)
+}
+
+func TestEvaluateDefaultDeny(t *testing.T) {
+	engine := testEngine(t, `{"rules": [
+		{"id": "owner-read", "effect": "allow", "actions": ["read"], "resources": ["post"], "conditions": [
+			{"attribute": "resource.owner_id", "operator": "eq", "ref": "subject.id"}
+		]}
+	]}`)
+	subject := &Subject{ID: 1, Active: true}
+
+	post, _ := ResourceOf(&models.Post{UserID: 2})
+	decision := engine.Evaluate(context.Background(), Request{Subject: subject, Action: "read", Resource: post})
+	assert.False(t, decision.Allowed)
+	assert.Empty(t, decision.Rule)
+	assert.Equal(t, "no rule allows read on post", decision.Reason)
+	require.Len(t, decision.Trace, 1)
+	assert.Equal(t, "resource.owner_id eq subject.id: got 2, want 1", decision.Trace[0].Failed)
+
+	decision = engine.Evaluate(context.Background(), Request{Subject: subject, Action: "read", Resource: Kind("file")})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no rule covers read on file", decision.Reason)
+
+	decision = engine.Evaluate(context.Background(), Request{Subject: subject, Action: "read"})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no resource given", decision.Reason)
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	attrs := map[string]Attributes{
+		scopeSubject: {
+			"id":    uint(7),
+			"roles": []string{"moderator", "user"},
+			"score": 3,
+		},
+		scopeResource: {
+			"owner_id": uint(7),
+			"role":     "user",
+			"tags":     []interface{}{"a", "b"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		cond Condition
+		want bool
+	}{
+		{"eq literal", Condition{Attribute: "resource.role", Operator: OpEq, Value: "user"}, true},
+		{"eq mismatch", Condition{Attribute: "resource.role", Operator: OpEq, Value: "admin"}, false},
+		{"eq number from JSON", Condition{Attribute: "subject.id", Operator: OpEq, Value: float64(7)}, true},
+		{"ne", Condition{Attribute: "resource.role", Operator: OpNe, Value: "admin"}, true},
+		{"ref", Condition{Attribute: "resource.owner_id", Operator: OpEq, Ref: "subject.id"}, true},
+		{"ref unset", Condition{Attribute: "resource.owner_id", Operator: OpEq, Ref: "subject.missing"}, false},
+		{"in", Condition{Attribute: "resource.role", Operator: OpIn, Value: []interface{}{"user", "moderator"}}, true},
+		{"in miss", Condition{Attribute: "resource.role", Operator: OpIn, Value: []interface{}{"admin"}}, false},
+		{"in non-list", Condition{Attribute: "resource.role", Operator: OpIn, Value: "user"}, false},
+		{"not_in", Condition{Attribute: "resource.role", Operator: OpNotIn, Value: []interface{}{"moderator", "admin"}}, true},
+		{"not_in hit", Condition{Attribute: "resource.role", Operator: OpNotIn, Value: []interface{}{"user"}}, false},
+		{"includes", Condition{Attribute: "subject.roles", Operator: OpIncludes, Value: "moderator"}, true},
+		{"includes miss", Condition{Attribute: "subject.roles", Operator: OpIncludes, Value: "admin"}, false},
+		{"includes interface list", Condition{Attribute: "resource.tags", Operator: OpIncludes, Value: "b"}, true},
+		{"includes on scalar", Condition{Attribute: "resource.role", Operator: OpIncludes, Value: "user"}, false},
+		{"excludes", Condition{Attribute: "subject.roles", Operator: OpExcludes, Value: "admin"}, true},
+		{"excludes hit", Condition{Attribute: "subject.roles", Operator: OpExcludes, Value: "moderator"}, false},
+		{"excludes on scalar", Condition{Attribute: "resource.role", Operator: OpExcludes, Value: "admin"}, false},
+		{"exists", Condition{Attribute: "resource.role", Operator: OpExists}, true},
+		{"exists false", Condition{Attribute: "resource.missing", Operator: OpExists, Value: false}, true},
+		{"exists missing", Condition{Attribute: "resource.missing", Operator: OpExists, Value: true}, false},
+		{"missing attribute", Condition{Attribute: "resource.missing", Operator: OpNe, Value: "x"}, false},
+		{"gt", Condition{Attribute: "subject.score", Operator: OpGt, Value: float64(2)}, true},
+		{"gte", Condition{Attribute: "subject.score", Operator: OpGte, Value: float64(3)}, true},
+		{"lt", Condition{Attribute: "subject.score", Operator: OpLt, Value: float64(3)}, false},
+		{"lte", Condition{Attribute: "subject.score", Operator: OpLte, Value: float64(3)}, true},
+		{"compare non-number", Condition{Attribute: "resource.role", Operator: OpGt, Value: float64(1)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, detail := evaluateCondition(tt.cond, attrs)
+			assert.Equal(t, tt.want, ok)
+			if ok {
+				assert.Empty(t, detail)
+			} else {
+				assert.NotEmpty(t, detail)
+			}
+		})
+	}
+}
+
+func TestParsePolicyValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{"no rules", `{"rules": []}`, "policy has no rules"},
+		{"missing id", `{"rules": [{"effect": "allow", "actions": ["read"], "resources": ["post"]}]}`, "rule 0: id is required"},
+		{"duplicate id", `{"rules": [
+			{"id": "a", "effect": "allow", "actions": ["read"], "resources": ["post"]},
+			{"id": "a", "effect": "deny", "actions": ["read"], "resources": ["post"]}
+		]}`, "rule a: duplicate id"},
+		{"bad effect", `{"rules": [{"id": "a", "effect": "maybe", "actions": ["read"], "resources": ["post"]}]}`, `effect must be "allow" or "deny"`},
+		{"no actions", `{"rules": [{"id": "a", "effect": "allow", "resources": ["post"]}]}`, "at least one action is required"},
+		{"no resources", `{"rules": [{"id": "a", "effect": "allow", "actions": ["read"]}]}`, "at least one resource is required"},
+		{"unknown operator", `{"rules": [{"id": "a", "effect": "allow", "actions": ["read"], "resources": ["post"], "conditions": [
+			{"attribute": "subject.id", "operator": "like", "value": 1}
+		]}]}`, `unknown operator "like"`},
+		{"bad scope", `{"rules": [{"id": "a", "effect": "allow", "actions": ["read"], "resources": ["post"], "conditions": [
+			{"attribute": "user.id", "operator": "eq", "value": 1}
+		]}]}`, `invalid attribute "user.id"`},
+		{"value and ref", `{"rules": [{"id": "a", "effect": "allow", "actions": ["read"], "resources": ["post"], "conditions": [
+			{"attribute": "resource.owner_id", "operator": "eq", "value": 1, "ref": "subject.id"}
+		]}]}`, "sets both value and ref"},
+		{"bad ref", `{"rules": [{"id": "a", "effect": "allow", "actions": ["read"], "resources": ["post"], "conditions": [
+			{"attribute": "resource.owner_id", "operator": "eq", "ref": "subject"}
+		]}]}`, `invalid ref "subject"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(tt.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	engine := NewEngine(DefaultPolicy(), false, nil)
+
+	admin := &Subject{ID: 1, Role: models.RoleAdmin, Active: true}
+	moderator := &Subject{ID: 2, Role: models.RoleModerator, Active: true}
+	user := &Subject{ID: 3, Role: models.RoleUser, Active: true}
+	other := &Subject{ID: 4, Role: models.RoleUser, Active: true}
+	suspendedAdmin := &Subject{ID: 5, Role: models.RoleAdmin, Active: false}
+	groupModerator := &Subject{ID: 6, Role: models.RoleUser, Roles: []models.Role{models.RoleModerator}, Active: true}
+
+	draft := &models.Post{ID: 10, UserID: user.ID, Status: models.PostStatusDraft}
+	published := &models.Post{ID: 11, UserID: user.ID, Status: models.PostStatusPublished, Published: true}
+	privateFile := &models.FileUpload{ID: 12, UserID: user.ID}
+	publicFile := &models.FileUpload{ID: 13, UserID: user.ID, IsPublic: true}
+	selfDeleted := &models.Post{ID: 14, UserID: user.ID, DeletedBy: &user.ID}
+	removed := &models.Post{ID: 15, UserID: user.ID, DeletedBy: &moderator.ID}
+	plainUser := &models.User{ID: user.ID, Role: models.RoleUser, IsActive: true}
+	moderatorUser := &models.User{ID: moderator.ID, Role: models.RoleModerator, IsActive: true}
+
+	tests := []struct {
+		name     string
+		subject  *Subject
+		action   string
+		resource interface{}
+		allowed  bool
+		rule     string
+	}{
+		{"admin deletes any post", admin, "delete", draft, true, "admin-full-access"},
+		{"suspended admin is denied", suspendedAdmin, "read", published, false, "deny-inactive-users"},
+		{"owner updates own draft", user, "update", draft, true, "owner-manage"},
+		{"owner cannot publish", user, "publish", draft, false, ""},
+		{"other user cannot read draft", other, "read", draft, false, ""},
+		{"other user reads published post", other, "read", published, true, "read-published-posts"},
+		{"anonymous reads published post", nil, "read", published, true, "read-published-posts"},
+		{"anonymous cannot read draft", nil, "read", draft, false, ""},
+		{"anonymous cannot create", nil, "create", Kind("post"), false, ""},
+		{"user creates post", user, "create", Kind("post"), true, "authenticated-create"},
+		{"moderator publishes post", moderator, "publish", draft, true, "moderator-publish-posts"},
+		{"group moderator publishes post", groupModerator, "publish", draft, true, "moderator-publish-posts"},
+		{"moderator moderates comments", moderator, "moderate", &models.Comment{UserID: user.ID}, true, "moderator-moderate-comments"},
+		{"user cannot moderate comments", other, "moderate", &models.Comment{UserID: user.ID}, false, ""},
+		{"moderator sanctions user", moderator, "moderate", plainUser, true, "moderator-sanction-users"},
+		{"moderator cannot sanction moderator", moderator, "moderate", moderatorUser, false, ""},
+		{"anonymous reads public file", nil, "read", publicFile, true, "read-public-files"},
+		{"other user cannot read private file", other, "read", privateFile, false, ""},
+		{"moderator reads private file", moderator, "read", privateFile, true, "moderator-manage-files"},
+		{"owner restores own deletion", user, "restore", selfDeleted, true, "owner-restore"},
+		{"owner cannot restore removed post", user, "restore", removed, false, ""},
+		{"owner cannot restore unrecorded deletion", user, "restore", draft, false, ""},
+		{"moderator restores removed post", moderator, "restore", removed, true, "moderator-manage-content"},
+		{"only admins export", moderator, "export", Kind("post"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, ok := ResourceOf(tt.resource)
+			require.True(t, ok)
+			decision := engine.Evaluate(context.Background(), Request{
+				Subject:  tt.subject,
+				Action:   tt.action,
+				Resource: resource,
+			})
+			assert.Equal(t, tt.allowed, decision.Allowed, decision.Reason)
+			assert.Equal(t, tt.rule, decision.Rule)
+		})
+	}
+}
+
+func TestDefaultPolicyInheritedRoles(t *testing.T) {
+	// editor inherits from moderator, and staff users hold moderator
+	// through a group
+	models.SetRoleHierarchy(map[models.Role]models.Role{
+		models.RoleAdmin:     models.RoleModerator,
+		"editor":             models.RoleModerator,
+		models.RoleModerator: models.RoleUser,
+		models.RoleUser:      "",
+	})
+	SetRoleResolver(func(userID uint) ([]models.Role, error) {
+		if userID == 4 {
+			return []models.Role{models.RoleModerator}, nil
+		}
+		return nil, nil
+	})
+	t.Cleanup(func() {
+		models.SetRoleHierarchy(map[models.Role]models.Role{
+			models.RoleAdmin:     models.RoleModerator,
+			models.RoleModerator: models.RoleUser,
+			models.RoleUser:      "",
+		})
+		roleResolver.Store(nil)
+	})
+
+	engine := NewEngine(DefaultPolicy(), false, nil)
+	moderator := &Subject{ID: 1, Role: models.RoleModerator, Active: true}
+
+	tests := []struct {
+		name    string
+		target  *models.User
+		allowed bool
+	}{
+		{"plain user", &models.User{ID: 2, Role: models.RoleUser, IsActive: true}, true},
+		{"editor inherits moderator", &models.User{ID: 3, Role: "editor", IsActive: true}, false},
+		{"group moderator", &models.User{ID: 4, Role: models.RoleUser, IsActive: true}, false},
+		{"admin", &models.User{ID: 5, Role: models.RoleAdmin, IsActive: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, ok := ResourceOf(tt.target)
+			require.True(t, ok)
+			decision := engine.Evaluate(context.Background(), Request{
+				Subject:  moderator,
+				Action:   "moderate",
+				Resource: resource,
+			})
+			assert.Equal(t, tt.allowed, decision.Allowed, decision.Reason)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package authz
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed default_policy.json
+var defaultPolicyJSON []byte
+
+// Rule effects
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Condition operators
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpIn       = "in"
+	OpNotIn    = "not_in"
+	OpIncludes = "includes"
+	OpExcludes = "excludes"
+	OpExists   = "exists"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+)
+
+var validOperators = map[string]bool{
+	OpEq: true, OpNe: true, OpIn: true, OpNotIn: true, OpIncludes: true,
+	OpExcludes: true, OpExists: true, OpGt: true, OpGte: true, OpLt: true, OpLte: true,
+}
+
+// Policy is a declarative set of access rules
+type Policy struct {
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule allows or denies a set of actions on a set of resource types when all
+// of its conditions hold. "*" matches any action or resource type.
+type Rule struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description,omitempty"`
+	Effect      string      `json:"effect"`
+	Actions     []string    `json:"actions"`
+	Resources   []string    `json:"resources"`
+	Conditions  []Condition `json:"conditions,omitempty"`
+}
+
+// Condition compares an attribute such as "resource.owner_id" with either a
+// literal value or another attribute referenced by Ref, e.g. "subject.id"
+type Condition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	Ref       string      `json:"ref,omitempty"`
+}
+
+// String returns a readable form of the condition
+func (c Condition) String() string {
+	if c.Ref != "" {
+		return fmt.Sprintf("%s %s %s", c.Attribute, c.Operator, c.Ref)
+	}
+	return fmt.Sprintf("%s %s %v", c.Attribute, c.Operator, c.Value)
+}
+
+// DefaultPolicy returns the policy shipped with the application
+func DefaultPolicy() *Policy {
+	policy, err := ParsePolicy(defaultPolicyJSON)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default authorization policy: %v", err))
+	}
+	return policy
+}
+
+// LoadPolicy reads and validates a policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ParsePolicy parses and validates a JSON policy document
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks that every rule is well formed
+func (p *Policy) Validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("policy has no rules")
+	}
+
+	seen := make(map[string]bool, len(p.Rules))
+	for i, rule := range p.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d: id is required", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("rule %s: duplicate id", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return fmt.Errorf("rule %s: effect must be %q or %q", rule.ID, EffectAllow, EffectDeny)
+		}
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("rule %s: at least one action is required", rule.ID)
+		}
+		if len(rule.Resources) == 0 {
+			return fmt.Errorf("rule %s: at least one resource is required", rule.ID)
+		}
+
+		for _, cond := range rule.Conditions {
+			if err := cond.validate(); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the condition can be evaluated
+func (c Condition) validate() error {
+	if !isAttributePath(c.Attribute) {
+		return fmt.Errorf("invalid attribute %q", c.Attribute)
+	}
+	if !validOperators[c.Operator] {
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	if c.Ref != "" {
+		if c.Value != nil {
+			return fmt.Errorf("condition on %s sets both value and ref", c.Attribute)
+		}
+		if !isAttributePath(c.Ref) {
+			return fmt.Errorf("invalid ref %q", c.Ref)
+		}
+	}
+	return nil
+}
+
+// isAttributePath checks for a scope.name attribute path
+func isAttributePath(path string) bool {
+	scope, name, ok := strings.Cut(path, ".")
+	if !ok || name == "" {
+		return false
+	}
+	switch scope {
+	case scopeSubject, scopeResource, scopeEnvironment, scopeRelation:
+		return true
+	}
+	return false
+}
+
+// matches checks if the rule applies to the action and resource type
+func (r Rule) matches(action, resourceType string) bool {
+	return matchesAny(r.Actions, action) && matchesAny(r.Resources, resourceType)
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+// Package bundle reads and writes post bundles: zip archives holding each
+// post as a Markdown file with YAML front matter, next to the files attached
+// to the post and its comments.
+//
+//	posts/hello-world.md
+//	attachments/hello-world/1-photo.jpg
+//
+// A post's Markdown links to its files relative to the post's file, so the
+// links keep working when a bundle is unpacked and browsed.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Directories of a bundle
+const (
+	PostsDir       = "posts"
+	AttachmentsDir = "attachments"
+)
+
+const delimiter = "---"
+
+var (
+	// ErrNoFrontMatter is returned for Markdown files that do not start with
+	// front matter
+	ErrNoFrontMatter = errors.New("missing front matter")
+	// ErrTooLarge is returned for files in a bundle larger than allowed
+	ErrTooLarge = errors.New("file too large")
+)
+
+// Post is a post in a bundle. Everything but the content is front matter.
+type Post struct {
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug"`
+	Author      Author     `yaml:"author"`
+	Status      string     `yaml:"status"`
+	CreatedAt   time.Time  `yaml:"created_at"`
+	PublishAt   *time.Time `yaml:"publish_at,omitempty"`
+	PublishedAt *time.Time `yaml:"published_at,omitempty"`
+
+	// Tags are tag names and Categories category slugs
+	Tags       []string `yaml:"tags,omitempty"`
+	Categories []string `yaml:"categories,omitempty"`
+
+	CommentsRequireApproval bool `yaml:"comments_require_approval,omitempty"`
+
+	Attachments []Attachment `yaml:"attachments,omitempty"`
+	Comments    []Comment    `yaml:"comments,omitempty"`
+
+	// Content is the Markdown body
+	Content string `yaml:"-"`
+}
+
+// Author identifies the author of a post or comment
+type Author struct {
+	Username string `yaml:"username"`
+	Email    string `yaml:"email,omitempty"`
+}
+
+// Attachment is a file attached to a post or comment, stored at Path in the
+// bundle
+type Attachment struct {
+	Path     string `yaml:"path"`
+	Name     string `yaml:"name"`
+	MimeType string `yaml:"mime_type,omitempty"`
+	Caption  string `yaml:"caption,omitempty"`
+}
+
+// Comment is a comment on a post. IDs are only meaningful within the post,
+// where they link replies to their parent.
+type Comment struct {
+	ID          uint         `yaml:"id"`
+	Parent      uint         `yaml:"parent,omitempty"`
+	Author      Author       `yaml:"author"`
+	Status      string       `yaml:"status"`
+	CreatedAt   time.Time    `yaml:"created_at"`
+	Content     string       `yaml:"content"`
+	Attachments []Attachment `yaml:"attachments,omitempty"`
+}
+
+// PostPath returns the path of a post's Markdown file in a bundle
+func PostPath(slug string) string {
+	return path.Join(PostsDir, fileName(slug)+".md")
+}
+
+// AttachmentPath returns the path in a bundle of the index-th file attached
+// to a post or its comments
+func AttachmentPath(slug string, index int, name string) string {
+	return path.Join(AttachmentsDir, fileName(slug), strconv.Itoa(index)+"-"+fileName(name))
+}
+
+// Link returns the link from a post's Markdown to a file at a path in the
+// bundle
+func Link(filePath string) string {
+	return "../" + filePath
+}
+
+// Marshal formats a post as Markdown with YAML front matter
+func Marshal(post *Post) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(post); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString(delimiter + "\n\n")
+	buf.WriteString(post.Content)
+	if !strings.HasSuffix(post.Content, "\n") {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses a post from Markdown with YAML front matter
+func Unmarshal(data []byte) (*Post, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+	if !strings.HasPrefix(text, delimiter+"\n") {
+		return nil, ErrNoFrontMatter
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	// Keep the newline before the closing delimiter so that empty front
+	// matter is found too
+	text = text[len(delimiter):]
+	end := strings.Index(text, "\n"+delimiter+"\n")
+	if end < 0 {
+		return nil, ErrNoFrontMatter
+	}
+
+	var post Post
+	if err := yaml.Unmarshal([]byte(text[:end+1]), &post); err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	post.Content = strings.TrimPrefix(text[end+len(delimiter)+2:], "\n")
+	return &post, nil
+}
+
+// Write writes posts to w as a bundle. Attached files are copied from the
+// readers open returns for their paths.
+func Write(w io.Writer, posts []Post, open func(filePath string) (io.ReadCloser, error)) error {
+	archive := zip.NewWriter(w)
+	for i := range posts {
+		post := &posts[i]
+		data, err := Marshal(post)
+		if err != nil {
+			return fmt.Errorf("failed to format post %q: %w", post.Slug, err)
+		}
+		if err := writeFile(archive, PostPath(post.Slug), post.CreatedAt, bytes.NewReader(data)); err != nil {
+			return err
+		}
+
+		attachments := post.Attachments
+		for _, comment := range post.Comments {
+			attachments = append(attachments, comment.Attachments...)
+		}
+		for _, attachment := range attachments {
+			if err := copyFile(archive, attachment.Path, post.CreatedAt, open); err != nil {
+				return err
+			}
+		}
+	}
+	return archive.Close()
+}
+
+// Read reads the posts of a bundle. A post without a slug takes the name of
+// its file. Markdown files larger than maxSize are rejected, as are reads
+// past the limit; an archive without any post is not a bundle and reads as
+// no posts.
+func Read(archive *zip.Reader, maxSize int64, limit *Limit) ([]Post, error) {
+	var posts []Post
+	for _, file := range archive.File {
+		if path.Dir(file.Name) != PostsDir || path.Ext(file.Name) != ".md" {
+			continue
+		}
+
+		data, err := limit.ReadFile(file, maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		post, err := Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		if post.Slug == "" {
+			post.Slug = strings.TrimSuffix(path.Base(file.Name), ".md")
+		}
+		posts = append(posts, *post)
+	}
+	return posts, nil
+}
+
+// Limit caps the bytes read from the files of an archive in total,
+// whatever sizes the archive claims, so that an archive of many small but
+// highly compressed files cannot expand without bound. It is not safe for
+// concurrent use.
+type Limit struct {
+	remaining int64
+}
+
+// NewLimit returns a limit of maxSize bytes
+func NewLimit(maxSize int64) *Limit {
+	return &Limit{remaining: maxSize}
+}
+
+// Open opens a file from a zip archive. Reads fail with ErrTooLarge once
+// the files opened have read more than the limit between them.
+func (l *Limit) Open(file *zip.File) (io.ReadCloser, error) {
+	if file.UncompressedSize64 > uint64(max(l.remaining, 0)) {
+		return nil, ErrTooLarge
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &limitedFile{ReadCloser: r, limit: l}, nil
+}
+
+// ReadFile reads a file from a zip archive within the limit, failing once
+// more than maxSize bytes have been read from it
+func (l *Limit) ReadFile(file *zip.File, maxSize int64) ([]byte, error) {
+	if file.UncompressedSize64 > uint64(maxSize) {
+		return nil, ErrTooLarge
+	}
+	r, err := l.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// limitedFile is a file opened within a limit
+type limitedFile struct {
+	io.ReadCloser
+	limit *Limit
+}
+
+func (f *limitedFile) Read(p []byte) (int, error) {
+	if f.limit.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// Read one byte past the limit to tell a file ending right at it from
+	// one running over
+	if int64(len(p)) > f.limit.remaining+1 {
+		p = p[:f.limit.remaining+1]
+	}
+	n, err := f.ReadCloser.Read(p)
+	f.limit.remaining -= int64(n)
+	if f.limit.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// copyFile copies a file from the reader open returns into the archive
+func copyFile(archive *zip.Writer, filePath string, modified time.Time, open func(string) (io.ReadCloser, error)) error {
+	r, err := open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer r.Close()
+	return writeFile(archive, filePath, modified, r)
+}
+
+// writeFile adds a file to the archive
+func writeFile(archive *zip.Writer, filePath string, modified time.Time, r io.Reader) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     filePath,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", filePath, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// fileName makes a slug or file name safe to use as a single path element
+func fileName(name string) string {
+	name = strings.NewReplacer("/", "-", "\\", "-").Replace(strings.TrimSpace(name))
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,193 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipArchive builds an archive of files
+func zipArchive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return archive
+}
+
+func TestWriteRead(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	published := created.Add(time.Hour)
+	photo := AttachmentPath("hello/world", 1, "photo.jpg")
+	notes := AttachmentPath("hello/world", 2, "notes.txt")
+	posts := []Post{{
+		Title:       "Hello: a \"post\"",
+		Slug:        "hello/world",
+		Author:      Author{Username: "ann", Email: "ann@example.com"},
+		Status:      "published",
+		CreatedAt:   created,
+		PublishedAt: &published,
+		Tags:        []string{"Go", "Web"},
+		Categories:  []string{"news"},
+		Attachments: []Attachment{{Path: photo, Name: "photo.jpg", MimeType: "image/jpeg", Caption: "A photo"}},
+		Comments: []Comment{
+			{ID: 1, Author: Author{Username: "bob"}, Status: "approved", CreatedAt: created, Content: "Nice"},
+			{ID: 2, Parent: 1, Author: Author{Username: "ann"}, Status: "approved", CreatedAt: created, Content: "Thanks",
+				Attachments: []Attachment{{Path: notes, Name: "notes.txt"}}},
+		},
+		Content: "---\nLooks like front matter\n\n![photo](" + Link(photo) + ")",
+	}}
+
+	files := map[string]string{photo: "jpeg bytes", notes: "some notes"}
+	var buf bytes.Buffer
+	err := Write(&buf, posts, func(filePath string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(files[filePath])), nil
+	})
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"posts/hello-world.md", "attachments/hello-world/1-photo.jpg", "attachments/hello-world/2-notes.txt"}, names)
+
+	read, err := Read(archive, 1<<20, NewLimit(1<<20))
+	require.NoError(t, err)
+	require.Len(t, read, 1)
+	posts[0].Content += "\n"
+	assert.Equal(t, posts[0].Title, read[0].Title)
+	assert.True(t, posts[0].CreatedAt.Equal(read[0].CreatedAt))
+	assert.True(t, posts[0].PublishedAt.Equal(*read[0].PublishedAt))
+	read[0].CreatedAt, read[0].PublishedAt = posts[0].CreatedAt, posts[0].PublishedAt
+	for i := range read[0].Comments {
+		read[0].Comments[i].CreatedAt = created
+	}
+	assert.Equal(t, posts[0], read[0])
+
+	data, err := NewLimit(1<<20).ReadFile(archive.File[1], 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg bytes", string(data))
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		title   string
+		content string
+		err     error
+	}{
+		{"front matter", "---\ntitle: Hello\n---\n\nBody\n", "Hello", "Body\n", nil},
+		{"CRLF and BOM", "\ufeff---\r\ntitle: Hello\r\n---\r\nBody", "Hello", "Body\n", nil},
+		{"empty front matter", "---\n---\nBody\n", "", "Body\n", nil},
+		{"no front matter", "# Hello\n", "", "", ErrNoFrontMatter},
+		{"unclosed front matter", "---\ntitle: Hello\n", "", "", ErrNoFrontMatter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := Unmarshal([]byte(tt.input))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.title, post.Title)
+			assert.Equal(t, tt.content, post.Content)
+		})
+	}
+
+	_, err := Unmarshal([]byte("---\ntitle: [\n---\n"))
+	assert.ErrorContains(t, err, "invalid front matter")
+}
+
+func TestRead(t *testing.T) {
+	archive := zipArchive(t, map[string]string{
+		"posts/hello.md":       "---\ntitle: Hello\n---\nBody\n",
+		"posts/nested/skip.md": "---\ntitle: Nested\n---\n",
+		"posts/notes.txt":      "not a post",
+		"attachments/a.md":     "not a post",
+	})
+	posts, err := Read(archive, 1<<10, NewLimit(1<<10))
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.Equal(t, "hello", posts[0].Slug, "slug defaults to the file name")
+
+	posts, err = Read(zipArchive(t, map[string]string{"readme.txt": "hi"}), 1<<10, NewLimit(1<<10))
+	require.NoError(t, err)
+	assert.Empty(t, posts)
+
+	_, err = Read(zipArchive(t, map[string]string{"posts/bad.md": "no front matter"}), 1<<10, NewLimit(1<<10))
+	assert.ErrorIs(t, err, ErrNoFrontMatter)
+	assert.ErrorContains(t, err, "posts/bad.md")
+}
+
+func TestLimit(t *testing.T) {
+	post := "---\ntitle: Hello\n---\n" + strings.Repeat("a", 100)
+	archive := zipArchive(t, map[string]string{
+		"posts/a.md": post,
+		"posts/b.md": post,
+		"posts/c.md": post,
+	})
+
+	// Each file fits, but not all of them together
+	_, err := Read(archive, int64(len(post)), NewLimit(int64(2*len(post))))
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Read(archive, int64(len(post)), NewLimit(int64(3*len(post))))
+	assert.NoError(t, err)
+
+	_, err = Read(archive, int64(len(post)-1), NewLimit(1<<20))
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestLimitOpen(t *testing.T) {
+	archive := zipArchive(t, map[string]string{"big.txt": strings.Repeat("x", 1000)})
+	file := archive.File[0]
+
+	// The limit spans every file opened within it
+	limit := NewLimit(1500)
+	r, err := limit.Open(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Len(t, data, 1000)
+	require.NoError(t, r.Close())
+	_, err = limit.Open(file)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = NewLimit(1000).ReadFile(file, 999)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	// A file ending right at the limit is read whole
+	data, err = NewLimit(1000).ReadFile(file, 1000)
+	require.NoError(t, err)
+	assert.Len(t, data, 1000)
+}
+
+func TestPaths(t *testing.T) {
+	assert.Equal(t, "posts/hello.md", PostPath("hello"))
+	assert.Equal(t, "posts/a-b.md", PostPath("a/b"))
+	assert.Equal(t, "posts/file.md", PostPath("..."))
+	assert.Equal(t, "attachments/hello/3-photo.jpg", AttachmentPath("hello", 3, "photo.jpg"))
+	assert.Equal(t, "attachments/hello/1-a-b.txt", AttachmentPath("hello", 1, `a\b.txt`))
+	assert.Equal(t, "attachments/hello/1--etc-passwd", AttachmentPath("hello", 1, "../etc/passwd"))
+	assert.Equal(t, "../attachments/hello/1-a.txt", Link("attachments/hello/1-a.txt"))
+}
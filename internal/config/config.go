@@ -12,16 +12,28 @@ import (
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Logging  LoggingConfig
-	CORS     CORSConfig
-	Email    EmailConfig
-	Redis    RedisConfig
-	Security SecurityConfig
-	App      AppConfig
-	File     FileConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Logging     LoggingConfig
+	CORS        CORSConfig
+	Email       EmailConfig
+	Redis       RedisConfig
+	Security    SecurityConfig
+	App         AppConfig
+	File        FileConfig
+	Authz       AuthzConfig
+	Tenancy     TenancyConfig
+	Comments    CommentsConfig
+	Revisions   RevisionsConfig
+	Publisher   PublisherConfig
+	Reactions   ReactionsConfig
+	Views       ViewsConfig
+	Feeds       FeedsConfig
+	Follows     FollowsConfig
+	Attachments AttachmentsConfig
+	Trash       TrashConfig
+	Imports     ImportsConfig
 }
 
 // ServerConfig holds server-specific configuration
@@ -109,6 +121,108 @@ type FileConfig struct {
 	StaticURL    string
 }
 
+// AuthzConfig holds authorization policy configuration
+type AuthzConfig struct {
+	PolicyFile string
+	Debug      bool
+}
+
+// TenancyConfig holds multi-tenancy configuration
+type TenancyConfig struct {
+	Enabled bool
+	// Header carries the organization ID or slug
+	Header string
+	// BaseDomain resolves tenants from subdomains, e.g. acme.example.com
+	BaseDomain string
+}
+
+// CommentsConfig holds comment threading and editing configuration
+type CommentsConfig struct {
+	// MaxDepth is the deepest reply level; top-level comments are depth 0
+	MaxDepth int
+	// EditWindow is how long authors can edit their comments after posting
+	EditWindow time.Duration
+}
+
+// RevisionsConfig holds post revision pruning configuration
+type RevisionsConfig struct {
+	// MaxPerPost is how many revisions are kept per post; 0 keeps all
+	MaxPerPost int
+	// MaxAge drops older revisions except a post's latest; 0 keeps all
+	MaxAge time.Duration
+}
+
+// PublisherConfig holds scheduled post publishing configuration
+type PublisherConfig struct {
+	// Interval is how often scheduled posts are checked for publishing
+	Interval time.Duration
+}
+
+// ReactionsConfig holds post and comment reaction configuration
+type ReactionsConfig struct {
+	// Emoji are the reactions users can choose from
+	Emoji []string
+}
+
+// ViewsConfig holds post view counting configuration
+type ViewsConfig struct {
+	// DedupWindow is how long repeat views of a post by one viewer count once
+	DedupWindow time.Duration
+	// Buffered counts views in Redis and writes them to the database every
+	// FlushInterval instead of on every view. It requires Redis.
+	Buffered      bool
+	FlushInterval time.Duration
+}
+
+// FeedsConfig holds syndication feed and sitemap configuration
+type FeedsConfig struct {
+	// Size is how many of the latest posts a feed lists
+	Size int
+	// MaxAge is how long clients and proxies may cache feeds and sitemaps
+	MaxAge time.Duration
+	// BaseURL is the public URL feeds and sitemaps are served at, used for
+	// their self links. It defaults to the frontend URL.
+	BaseURL string
+}
+
+// FollowsConfig holds follow and home feed configuration
+type FollowsConfig struct {
+	// TimelineThreshold is how many authors a user must follow to get a
+	// materialised home timeline instead of one assembled on every read.
+	// Zero disables materialised timelines.
+	TimelineThreshold int
+	// TimelineSize is how many of the latest posts are copied into a
+	// timeline when it is built
+	TimelineSize int
+	// NotifyPosts notifies followers who asked for it of new posts
+	NotifyPosts bool
+}
+
+// AttachmentsConfig holds post and comment attachment configuration
+type AttachmentsConfig struct {
+	// MaxPerTarget is how many files can be attached to one post or comment
+	MaxPerTarget int
+}
+
+// TrashConfig holds configuration for the trash of deleted posts, comments,
+// files and users
+type TrashConfig struct {
+	// Retention is how long deleted items stay in the trash before they are
+	// purged. Zero keeps them until purged by hand.
+	Retention time.Duration
+	// PurgeInterval is how often expired items are purged
+	PurgeInterval time.Duration
+}
+
+// ImportsConfig holds configuration for importing posts
+type ImportsConfig struct {
+	// MaxSize is the largest bundle or WordPress export accepted, in bytes
+	MaxSize int64
+	// MaxUnpackedSize is the most bytes read from the files of an imported
+	// archive in total once decompressed
+	MaxUnpackedSize int64
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -143,6 +257,82 @@ func Load() (*Config, error) {
 		CORS: CORSConfig{
 			Origins: getEnvAsSlice("CORS_ORIGINS", []string{"*"}),
 		},
+		Email: EmailConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvAsInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+			TLS:      getEnvAsBool("SMTP_TLS", true),
+		},
+		Redis: RedisConfig{
+			Enabled:  getEnvAsBool("REDIS_ENABLED", false),
+			Host:     getEnv("REDIS_HOST", "localhost"),
+			Port:     getEnvAsInt("REDIS_PORT", 6379),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getEnvAsInt("REDIS_DB", 0),
+		},
+		App: AppConfig{
+			Name:        getEnv("APP_NAME", "Go Backend"),
+			Version:     getEnv("APP_VERSION", "1.0.0"),
+			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+			AdminEmail:  getEnv("ADMIN_EMAIL", ""),
+		},
+		File: FileConfig{
+			MaxSize:      int64(getEnvAsInt("FILE_MAX_SIZE", 10<<20)),
+			AllowedTypes: getEnvAsSlice("FILE_ALLOWED_TYPES", []string{"jpg", "jpeg", "png", "gif", "webp", "pdf", "txt", "md", "csv", "zip"}),
+			UploadPath:   getEnv("UPLOAD_PATH", "./uploads"),
+			StaticURL:    getEnv("STATIC_URL", "/uploads"),
+		},
+		Authz: AuthzConfig{
+			PolicyFile: getEnv("AUTHZ_POLICY_FILE", ""),
+			Debug:      getEnvAsBool("AUTHZ_DEBUG", false),
+		},
+		Tenancy: TenancyConfig{
+			Enabled:    getEnvAsBool("TENANCY_ENABLED", false),
+			Header:     getEnv("TENANCY_HEADER", "X-Tenant-ID"),
+			BaseDomain: getEnv("TENANCY_BASE_DOMAIN", ""),
+		},
+		Comments: CommentsConfig{
+			MaxDepth:   getEnvAsInt("COMMENTS_MAX_DEPTH", 5),
+			EditWindow: getEnvAsDuration("COMMENTS_EDIT_WINDOW", 15*time.Minute),
+		},
+		Revisions: RevisionsConfig{
+			MaxPerPost: getEnvAsInt("REVISIONS_MAX_PER_POST", 50),
+			MaxAge:     getEnvAsDuration("REVISIONS_MAX_AGE", 0),
+		},
+		Publisher: PublisherConfig{
+			Interval: getEnvAsDuration("PUBLISHER_INTERVAL", time.Minute),
+		},
+		Reactions: ReactionsConfig{
+			Emoji: getEnvAsSlice("REACTIONS_EMOJI", []string{"👍", "❤️", "😂", "🎉", "😮", "😢"}),
+		},
+		Views: ViewsConfig{
+			DedupWindow:   getEnvAsDuration("VIEWS_DEDUP_WINDOW", 30*time.Minute),
+			Buffered:      getEnvAsBool("VIEWS_BUFFERED", false),
+			FlushInterval: getEnvAsDuration("VIEWS_FLUSH_INTERVAL", 30*time.Second),
+		},
+		Feeds: FeedsConfig{
+			Size:    getEnvAsInt("FEEDS_SIZE", 20),
+			MaxAge:  getEnvAsDuration("FEEDS_MAX_AGE", 15*time.Minute),
+			BaseURL: getEnv("FEEDS_BASE_URL", ""),
+		},
+		Follows: FollowsConfig{
+			TimelineThreshold: getEnvAsInt("FOLLOWS_TIMELINE_THRESHOLD", 0),
+			TimelineSize:      getEnvAsInt("FOLLOWS_TIMELINE_SIZE", 1000),
+			NotifyPosts:       getEnvAsBool("FOLLOWS_NOTIFY_POSTS", true),
+		},
+		Attachments: AttachmentsConfig{
+			MaxPerTarget: getEnvAsInt("ATTACHMENTS_MAX_PER_TARGET", 10),
+		},
+		Trash: TrashConfig{
+			Retention:     getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Imports: ImportsConfig{
+			MaxSize:         int64(getEnvAsInt("IMPORTS_MAX_SIZE", 100<<20)),
+			MaxUnpackedSize: int64(getEnvAsInt("IMPORTS_MAX_UNPACKED_SIZE", 1<<30)),
+		},
 	}
 
 	// Validate required configuration
@@ -217,6 +407,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -1,11 +1,15 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"go-backend/internal/config"
+	"go-backend/internal/markdown"
 	"go-backend/internal/models"
+	"go-backend/internal/search"
+	"go-backend/internal/tenant"
 
 	"github.com/glebarez/sqlite" // Pure Go SQLite driver
 	"gorm.io/driver/postgres"
@@ -45,6 +49,13 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Scope tenant-owned models to the organization in each query's context
+	if cfg.Tenancy.Enabled {
+		if err := db.Use(tenant.NewPlugin()); err != nil {
+			return nil, fmt.Errorf("failed to enable tenant scoping: %w", err)
+		}
+	}
+
 	return &Database{DB: db}, nil
 }
 
@@ -55,16 +66,113 @@ func (d *Database) Migrate() error {
 	err := d.DB.AutoMigrate(
 		&models.User{},
 		&models.Post{},
+		&models.PostSlugRedirect{},
+		&models.PostRevision{},
+		&models.Tag{},
+		&models.Category{},
+		&models.Reaction{},
+		&models.PostView{},
 		&models.Comment{},
+		&models.AuditLog{},
+		&models.RoleDefinition{},
+		&models.Permission{},
+		&models.RolePermission{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.GroupRole{},
+		&models.GroupPermission{},
+		&models.FileUpload{},
+		&models.ResourceShare{},
+		&models.ShareLink{},
+		&models.Report{},
+		&models.ModerationAction{},
+		&models.Notification{},
+		&models.Follow{},
+		&models.TimelineEntry{},
+		&models.Block{},
+		&models.Mention{},
+		&models.Attachment{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Posts published before the editorial workflow existed got the draft
+	// default status when the column was added
+	err = d.DB.WithContext(tenant.WithoutScope(context.Background())).
+		Model(&models.Post{}).
+		Where("published = ? AND status = ?", true, models.PostStatusDraft).
+		Update("status", models.PostStatusPublished).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate post statuses: %w", err)
+	}
+
+	// Feeds page through posts by publication time, which was not recorded
+	// for posts published that way
+	err = d.DB.WithContext(tenant.WithoutScope(context.Background())).
+		Model(&models.Post{}).
+		Where("status = ? AND published_at IS NULL", models.PostStatusPublished).
+		Update("published_at", gorm.Expr("created_at")).Error
+	if err != nil {
+		return fmt.Errorf("failed to migrate post publication times: %w", err)
+	}
+
+	// Content rendered by older rules, or before rendering existed
+	if err := d.renderStaleContent(); err != nil {
+		return err
+	}
+
+	// Full-text index over posts
+	index, err := search.NewIndex(d.DB.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	if err := index.Migrate(d.DB); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// renderStaleContent renders the Markdown of posts and comments whose cached
+// HTML is older than the current rendering rules
+func (d *Database) renderStaleContent() error {
+	db := d.DB.WithContext(tenant.WithoutScope(context.Background())).
+		Unscoped().Session(&gorm.Session{})
+
+	for _, model := range []interface{}{&models.Post{}, &models.Comment{}} {
+		var rows []struct {
+			ID      uint
+			Content string
+		}
+		err := db.Model(model).Select("id, content").
+			Where("render_version < ?", markdown.Version).
+			FindInBatches(&rows, 100, func(tx *gorm.DB, _ int) error {
+				for _, row := range rows {
+					contentHTML, err := markdown.Render(row.Content)
+					if err != nil {
+						return err
+					}
+					err = db.Model(model).Where("id = ?", row.ID).UpdateColumns(map[string]interface{}{
+						"content_html":   contentHTML,
+						"render_version": markdown.Version,
+					}).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to render content: %w", err)
+		}
+	}
+	return nil
+}
+
 // Seed creates initial data in the database
 func (d *Database) Seed() error {
 	// Check if admin user already exists
@@ -90,9 +198,76 @@ func (d *Database) Seed() error {
 		log.Println("Default admin user created: admin@example.com / admin123")
 	}
 
+	if err := d.seedRoles(); err != nil {
+		return err
+	}
+
+	return d.seedPermissions()
+}
+
+// seedRoles creates the built-in roles and their hierarchy if missing
+func (d *Database) seedRoles() error {
+	roleIDs := make(map[models.Role]uint)
+	for _, defaultRole := range models.DefaultRoles {
+		role := models.RoleDefinition{
+			Name:        defaultRole.Name,
+			DisplayName: defaultRole.DisplayName,
+			Description: defaultRole.Description,
+			IsSystem:    true,
+		}
+		if defaultRole.Parent != "" {
+			parentID := roleIDs[defaultRole.Parent]
+			role.ParentID = &parentID
+		}
+
+		if err := d.DB.Where("name = ?", role.Name).FirstOrCreate(&role).Error; err != nil {
+			return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+		}
+		roleIDs[role.Name] = role.ID
+	}
+
 	return nil
 }
 
+// seedPermissions creates any missing default permissions and grants each
+// newly created permission to its default roles
+func (d *Database) seedPermissions() error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		created := make(map[string]uint)
+		for _, permission := range models.DefaultPermissions {
+			permission := permission
+			permission.Resource, permission.Action, _ = models.ParsePermission(permission.Name)
+
+			result := tx.Where("name = ?", permission.Name).FirstOrCreate(&permission)
+			if result.Error != nil {
+				return fmt.Errorf("failed to create permission %s: %w", permission.Name, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				created[permission.Name] = permission.ID
+			}
+		}
+
+		for role, names := range models.DefaultRolePermissions {
+			for _, name := range names {
+				permissionID, isNew := created[name]
+				if !isNew {
+					continue
+				}
+
+				grant := &models.RolePermission{Role: role, PermissionID: permissionID}
+				if err := tx.Create(grant).Error; err != nil {
+					return fmt.Errorf("failed to grant %s to %s: %w", name, role, err)
+				}
+			}
+		}
+
+		if len(created) > 0 {
+			log.Printf("Created %d default permission(s)", len(created))
+		}
+		return nil
+	})
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
@@ -0,0 +1,56 @@
+// Package etag versions resources for optimistic concurrency control.
+// Versioned resources are served with their version as a strong entity tag.
+// A request that changes one may send the tag back in If-Match, and then only
+// applies if the resource has not changed since it was fetched.
+package etag
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+type expectedKey struct{}
+
+// Format returns the entity tag of a resource version
+func Format(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ParseIfMatch parses an If-Match header into the versions it accepts. any
+// is true for "*", which accepts every version. Weak and malformed tags are
+// skipped, as If-Match only matches strong tags, so a header can accept no
+// version at all.
+func ParseIfMatch(header string) (versions []uint, any bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 32)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, uint(version))
+	}
+	return versions, false
+}
+
+// WithExpected returns a context for a request that may only change a
+// resource at one of the versions
+func WithExpected(ctx context.Context, versions []uint) context.Context {
+	return context.WithValue(ctx, expectedKey{}, versions)
+}
+
+// Expected returns the versions the context's request may change a resource
+// at. ok is false when the request is unconditional.
+func Expected(ctx context.Context) (versions []uint, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	versions, ok = ctx.Value(expectedKey{}).([]uint)
+	return versions, ok
+}
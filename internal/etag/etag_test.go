@@ -0,0 +1,60 @@
+package etag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, `"1"`, Format(1))
+	assert.Equal(t, `"42"`, Format(42))
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		versions []uint
+		any      bool
+	}{
+		{"single", `"3"`, []uint{3}, false},
+		{"list", `"3", "4","5"`, []uint{3, 4, 5}, false},
+		{"any", `*`, nil, true},
+		{"any in list", `"3", *`, nil, true},
+		{"weak tag", `W/"3"`, nil, false},
+		{"weak tag in list", `W/"3", "4"`, []uint{4}, false},
+		{"unquoted", `3`, nil, false},
+		{"unterminated", `"3`, nil, false},
+		{"not a number", `"abc"`, nil, false},
+		{"negative", `"-1"`, nil, false},
+		{"too large", `"4294967296"`, nil, false},
+		{"empty tag", `""`, nil, false},
+		{"empty header", ``, nil, false},
+		{"malformed skipped", `"x", "7", garbage`, []uint{7}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, any := ParseIfMatch(tt.header)
+			assert.Equal(t, tt.versions, versions)
+			assert.Equal(t, tt.any, any)
+		})
+	}
+}
+
+func TestExpected(t *testing.T) {
+	_, ok := Expected(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithExpected(context.Background(), []uint{2, 3})
+	versions, ok := Expected(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []uint{2, 3}, versions)
+
+	// A header accepting no version still makes the request conditional
+	versions, ok = Expected(WithExpected(context.Background(), nil))
+	assert.True(t, ok)
+	assert.Empty(t, versions)
+}
@@ -0,0 +1,109 @@
+// Package feed writes syndication feeds in RSS 2.0, Atom 1.0 and JSON Feed
+// 1.1, and XML sitemaps.
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned for feed file names without a known extension
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Format is a feed format
+type Format string
+
+// Feed formats, named by their file extension
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// ParseFileName returns the format of a feed file name such as posts.rss
+// along with the name without its extension
+func ParseFileName(name string) (string, Format, error) {
+	ext := path.Ext(name)
+	format := Format(strings.TrimPrefix(ext, "."))
+	switch format {
+	case FormatRSS, FormatAtom, FormatJSON:
+		return strings.TrimSuffix(name, ext), format, nil
+	}
+	return "", "", ErrUnknownFormat
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	return f.mediaType() + "; charset=utf-8"
+}
+
+// mediaType returns the media type of the format without parameters
+func (f Format) mediaType() string {
+	switch f {
+	case FormatRSS:
+		return "application/rss+xml"
+	case FormatAtom:
+		return "application/atom+xml"
+	case FormatJSON:
+		return "application/feed+json"
+	}
+	return "application/octet-stream"
+}
+
+// Feed is a feed of items, newest first
+type Feed struct {
+	Title       string
+	Description string
+	Language    string
+
+	// Link is the page the feed is about and FeedURL the feed itself. The
+	// feed URL also identifies the feed.
+	Link    string
+	FeedURL string
+
+	// Updated is when any item last changed
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is an entry of a feed
+type Item struct {
+	// ID identifies the item for good, even if its title or link change
+	ID          string
+	Title       string
+	Link        string
+	Author      string
+	ContentHTML string
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// Write writes the feed in the given format
+func (f *Feed) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatRSS:
+		return writeRSS(w, f)
+	case FormatAtom:
+		return writeAtom(w, f)
+	case FormatJSON:
+		return writeJSON(w, f)
+	}
+	return ErrUnknownFormat
+}
+
+// TagURI returns a tag URI (RFC 4151) minted by the authority of baseURL on
+// the given date, such as tag:example.com,2024-01-31:posts/42. Tag URIs make
+// item IDs that survive changes to the item's link.
+func TagURI(baseURL string, date time.Time, specific string) string {
+	authority := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		authority = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:%s", authority, date.UTC().Format("2006-01-02"), specific)
+}
@@ -0,0 +1,57 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// SitemapMaxURLs is the most URLs a single sitemap may list
+const SitemapMaxURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapURL is a page listed in a sitemap, or a sitemap listed in a
+// sitemap index
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name       `xml:"urlset"`
+	NS      string         `xml:"xmlns,attr"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	NS       string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// WriteSitemap writes a sitemap of pages
+func WriteSitemap(w io.Writer, urls []SitemapURL) error {
+	return writeXML(w, urlSet{NS: sitemapNamespace, URLs: sitemapEntries(urls)})
+}
+
+// WriteSitemapIndex writes a sitemap index of sitemaps
+func WriteSitemapIndex(w io.Writer, sitemaps []SitemapURL) error {
+	return writeXML(w, sitemapIndex{NS: sitemapNamespace, Sitemaps: sitemapEntries(sitemaps)})
+}
+
+func sitemapEntries(urls []SitemapURL) []sitemapEntry {
+	entries := make([]sitemapEntry, len(urls))
+	for i, u := range urls {
+		entries[i].Loc = u.Loc
+		if !u.LastMod.IsZero() {
+			entries[i].LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AttachmentHandler handles files attached to posts and comments
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+	logger            *logger.Logger
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(attachmentService *services.AttachmentService, logger *logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		logger:            logger,
+	}
+}
+
+// ListPostAttachments lists the files attached to a post
+func (h *AttachmentHandler) ListPostAttachments(c *gin.Context) {
+	h.list(c, models.AttachmentTargetPost)
+}
+
+// ListCommentAttachments lists the files attached to a comment
+func (h *AttachmentHandler) ListCommentAttachments(c *gin.Context) {
+	h.list(c, models.AttachmentTargetComment)
+}
+
+// AttachToPost attaches a file to a post
+func (h *AttachmentHandler) AttachToPost(c *gin.Context) {
+	h.attach(c, models.AttachmentTargetPost)
+}
+
+// AttachToComment attaches a file to a comment
+func (h *AttachmentHandler) AttachToComment(c *gin.Context) {
+	h.attach(c, models.AttachmentTargetComment)
+}
+
+// ReorderPostAttachments changes the order of a post's attachments
+func (h *AttachmentHandler) ReorderPostAttachments(c *gin.Context) {
+	h.reorder(c, models.AttachmentTargetPost)
+}
+
+// ReorderCommentAttachments changes the order of a comment's attachments
+func (h *AttachmentHandler) ReorderCommentAttachments(c *gin.Context) {
+	h.reorder(c, models.AttachmentTargetComment)
+}
+
+// UpdateAttachment changes an attachment's caption or position
+func (h *AttachmentHandler) UpdateAttachment(c *gin.Context) {
+	attachmentID, ok := idFromParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	var req models.AttachmentUpdateRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	attachment, err := h.service(c).Update(userID, attachmentID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to update attachment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"attachment_id": attachmentID,
+		"user_id":       userID,
+	}).Info("Attachment updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment updated successfully",
+		"data":    h.response(attachment),
+	})
+}
+
+// DetachAttachment removes an attachment from its post or comment
+func (h *AttachmentHandler) DetachAttachment(c *gin.Context) {
+	attachmentID, ok := idFromParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).Detach(userID, attachmentID); err != nil {
+		h.respondWithError(c, err, "Failed to remove attachment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"attachment_id": attachmentID,
+		"user_id":       userID,
+	}).Info("Attachment removed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment removed successfully",
+	})
+}
+
+// DownloadAttachment serves an attachment's file. Images are shown inline;
+// other files are downloaded.
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, ok := idFromParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service(c).Download(c.GetUint("user_id"), attachmentID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to download attachment")
+		return
+	}
+
+	file := attachment.File
+	if file.FileType == "image" && !strings.Contains(file.MimeType, "svg") {
+		c.File(file.FilePath)
+		return
+	}
+	c.FileAttachment(file.FilePath, file.OriginalName)
+}
+
+// list lists the attachments of the :id post or comment
+func (h *AttachmentHandler) list(c *gin.Context, target models.AttachmentTarget) {
+	targetID, ok := idFromParam(c, "id", "Invalid "+string(target)+" ID")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service(c).List(c.GetUint("user_id"), target, targetID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.responses(attachments),
+	})
+}
+
+// attach attaches a file to the :id post or comment. A multipart request
+// uploads the file in its "file" field and attaches it in one call; a JSON
+// request attaches a file uploaded before.
+func (h *AttachmentHandler) attach(c *gin.Context, target models.AttachmentTarget) {
+	targetID, ok := idFromParam(c, "id", "Invalid "+string(target)+" ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	service := h.service(c)
+
+	var attachment *models.Attachment
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, formErr := c.FormFile("file")
+		if formErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Validation failed",
+				"errors": map[string]string{"file": "This field is required"},
+			})
+			return
+		}
+
+		var req models.AttachmentUploadRequest
+		if bindErr := c.ShouldBind(&req); bindErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Validation failed",
+				"errors": map[string]string{"binding": bindErr.Error()},
+			})
+			return
+		}
+		if errors := utils.NewValidator().ValidateStruct(&req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Validation failed",
+				"errors": errors,
+			})
+			return
+		}
+
+		attachment, err = service.Upload(userID, target, targetID, fileHeader, &req)
+	} else {
+		var req models.AttachmentCreateRequest
+		if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Validation failed",
+				"errors": errors,
+			})
+			return
+		}
+
+		attachment, err = service.Attach(userID, target, targetID, &req)
+	}
+	if err != nil {
+		h.respondWithError(c, err, "Failed to attach file")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"attachment_id": attachment.ID,
+		"file_id":       attachment.FileID,
+		"target_type":   target,
+		"target_id":     targetID,
+		"user_id":       userID,
+	}).Info("File attached successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "File attached successfully",
+		"data":    h.response(attachment),
+	})
+}
+
+// reorder puts the attachments of the :id post or comment in a new order
+func (h *AttachmentHandler) reorder(c *gin.Context, target models.AttachmentTarget) {
+	targetID, ok := idFromParam(c, "id", "Invalid "+string(target)+" ID")
+	if !ok {
+		return
+	}
+
+	var req models.AttachmentOrderRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	attachments, err := h.service(c).Reorder(userID, target, targetID, req.IDs)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to reorder attachments")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"target_type": target,
+		"target_id":   targetID,
+		"user_id":     userID,
+	}).Info("Attachments reordered successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachments reordered successfully",
+		"data":    h.responses(attachments),
+	})
+}
+
+// response converts an attachment to its response with its download URL
+func (h *AttachmentHandler) response(attachment *models.Attachment) models.AttachmentResponse {
+	return attachment.ToResponse(models.AttachmentURL(attachment.ID))
+}
+
+// responses converts attachments to their responses
+func (h *AttachmentHandler) responses(attachments []models.Attachment) []models.AttachmentResponse {
+	responses := make([]models.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = h.response(&attachments[i])
+	}
+	return responses
+}
+
+// service returns the attachment service scoped to the request context
+func (h *AttachmentHandler) service(c *gin.Context) *services.AttachmentService {
+	return h.attachmentService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps attachment service errors to responses
+func (h *AttachmentHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	var invalidFile services.FileValidationError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not found",
+		})
+	case errors.As(err, &invalidFile):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": map[string]string{invalidFile.Field: invalidFile.Message},
+		})
+	case errors.Is(err, services.ErrTooManyAttachments), errors.Is(err, services.ErrInvalidAttachmentOrder):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrAlreadyAttached):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthzHandler exposes the current user's effective access so clients do not
+// have to duplicate authorization logic
+type AuthzHandler struct {
+	accessService *services.AccessService
+	logger        *logger.Logger
+}
+
+// NewAuthzHandler creates a new authorization handler
+func NewAuthzHandler(accessService *services.AccessService, logger *logger.Logger) *AuthzHandler {
+	return &AuthzHandler{
+		accessService: accessService,
+		logger:        logger,
+	}
+}
+
+// GetMyPermissions returns the current user's effective roles, permissions
+// and entitlements, resolved the same way the authorization middleware does
+func (h *AuthzHandler) GetMyPermissions(c *gin.Context) {
+	roles := middleware.UserRoles(c)
+
+	permissions := []string{}
+	if granted, ok := c.Value("user_permissions").(services.PermissionSet); ok {
+		permissions = granted.List()
+	}
+
+	ctx := authz.WithEnvironment(c.Request.Context(), authz.Attributes{"ip": c.ClientIP()})
+	entitlements := authz.Entitlements(ctx, middleware.SubjectFromContext(c))
+	entitlements["admin:access"] = middleware.HasAnyRole(roles, models.RoleAdmin)
+	entitlements["moderator:access"] = middleware.HasAnyRole(roles, models.RoleModerator)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"roles":        roles,
+			"permissions":  permissions,
+			"entitlements": entitlements,
+		},
+	})
+}
+
+// CheckAccess answers a batch of "can I do this action on this resource"
+// checks for the current user
+func (h *AuthzHandler) CheckAccess(c *gin.Context) {
+	var req models.AccessCheckRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	results, err := h.accessService.WithContext(c.Request.Context()).CheckAll(c.GetUint("user_id"), req.Checks)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to check access")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check access",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": results,
+	})
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/etag"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// CommentHandler handles comment and comment moderation requests
+type CommentHandler struct {
+	commentService *services.CommentService
+	logger         *logger.Logger
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService *services.CommentService, logger *logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+// ListPostComments lists the comment threads of a post
+func (h *CommentHandler) ListPostComments(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	comments, err := h.service(c).GetPostComments(postID, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithError(c, err, "Post not found", "Failed to fetch comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comments,
+	})
+}
+
+// CreateComment adds a comment or reply to a post
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var req models.CommentCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	comment, err := h.service(c).CreateComment(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Post not found", "Failed to create comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id": comment.ID,
+		"post_id":    comment.PostID,
+		"parent_id":  comment.ParentID,
+		"status":     comment.Status,
+		"user_id":    comment.UserID,
+	}).Info("Comment created successfully")
+
+	c.Header("ETag", etag.Format(comment.Version))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Comment created successfully",
+		"data":    comment,
+	})
+}
+
+// UpdateComment edits a comment (author within the edit window, moderators)
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	commentID, ok := idFromParam(c, "id", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	var req models.CommentUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	comment, err := h.service(c).UpdateComment(commentID, c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to update comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id": comment.ID,
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Comment updated successfully")
+
+	c.Header("ETag", etag.Format(comment.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment updated successfully",
+		"data":    comment,
+	})
+}
+
+// DeleteComment deletes a comment and its replies (author, moderators)
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	commentID, ok := idFromParam(c, "id", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).DeleteComment(commentID, c.GetUint("user_id")); err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to delete comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id": commentID,
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Comment deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment deleted successfully",
+	})
+}
+
+// GetModerationQueue lists comments awaiting moderation, or with the status
+// given in ?status=
+func (h *CommentHandler) GetModerationQueue(c *gin.Context) {
+	status := models.CommentStatus(c.DefaultQuery("status", string(models.CommentStatusPending)))
+	if !status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid comment status",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := h.service(c).GetModerationQueue(c.GetUint("user_id"), status, options)
+	if err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to fetch moderation queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"comments": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// ApproveComment approves a comment
+func (h *CommentHandler) ApproveComment(c *gin.Context) {
+	h.moderate(c, models.CommentStatusApproved)
+}
+
+// RejectComment rejects a comment
+func (h *CommentHandler) RejectComment(c *gin.Context) {
+	h.moderate(c, models.CommentStatusRejected)
+}
+
+// MarkCommentSpam marks a comment as spam
+func (h *CommentHandler) MarkCommentSpam(c *gin.Context) {
+	h.moderate(c, models.CommentStatusSpam)
+}
+
+// BulkModerateComments sets the status of several comments at once
+func (h *CommentHandler) BulkModerateComments(c *gin.Context) {
+	var req models.CommentBulkModerateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	count, err := h.service(c).ModerateComments(req.IDs, req.Status, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to moderate comments")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"count":        count,
+		"status":       req.Status,
+		"moderated_by": c.GetUint("user_id"),
+	}).Info("Comments moderated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comments moderated successfully",
+		"data": gin.H{
+			"updated": count,
+		},
+	})
+}
+
+// moderate sets the status of the :id comment
+func (h *CommentHandler) moderate(c *gin.Context, status models.CommentStatus) {
+	commentID, ok := idFromParam(c, "id", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	if _, err := h.service(c).GetByID(commentID); err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to moderate comment")
+		return
+	}
+
+	if _, err := h.service(c).ModerateComments([]uint{commentID}, status, c.GetUint("user_id")); err != nil {
+		h.respondWithError(c, err, "Comment not found", "Failed to moderate comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id":   commentID,
+		"status":       status,
+		"moderated_by": c.GetUint("user_id"),
+	}).Info("Comment moderated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment moderated successfully",
+		"data": gin.H{
+			"id":     commentID,
+			"status": status,
+		},
+	})
+}
+
+// service returns the comment service scoped to the request context
+func (h *CommentHandler) service(c *gin.Context) *services.CommentService {
+	return h.commentService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps comment service errors to responses
+func (h *CommentHandler) respondWithError(c *gin.Context, err error, notFound, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": notFound,
+		})
+	case errors.Is(err, services.ErrParentNotFound), errors.Is(err, services.ErrCommentTooDeep):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrEditWindowClosed), errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrPreconditionFailed):
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
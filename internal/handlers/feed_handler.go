@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/feed"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FeedHandler serves syndication feeds and sitemaps of published posts
+type FeedHandler struct {
+	feedService *services.FeedService
+	maxAge      time.Duration
+	tenancy     config.TenancyConfig
+	logger      *logger.Logger
+}
+
+// NewFeedHandler creates a new feed handler. Responses may be cached for
+// maxAge and, with tenancy on, vary by the tenancy header.
+func NewFeedHandler(feedService *services.FeedService, maxAge time.Duration, tenancy config.TenancyConfig, logger *logger.Logger) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		maxAge:      maxAge,
+		tenancy:     tenancy,
+		logger:      logger,
+	}
+}
+
+// PostsFeed serves the latest published posts as /feeds/posts.{rss,atom,json}
+func (h *FeedHandler) PostsFeed(c *gin.Context) {
+	h.serveFeed(c, func(s *services.FeedService, feedURL string) (*feed.Feed, error) {
+		return s.PostsFeed(feedURL)
+	})
+}
+
+// AuthorFeed serves the latest posts published by the :username user
+func (h *FeedHandler) AuthorFeed(c *gin.Context) {
+	h.serveFeed(c, func(s *services.FeedService, feedURL string) (*feed.Feed, error) {
+		return s.AuthorFeed(c.Param("username"), feedURL)
+	})
+}
+
+// TagFeed serves the latest published posts tagged :slug
+func (h *FeedHandler) TagFeed(c *gin.Context) {
+	h.serveFeed(c, func(s *services.FeedService, feedURL string) (*feed.Feed, error) {
+		return s.TagFeed(c.Param("slug"), feedURL)
+	})
+}
+
+// SitemapIndex serves the index of the post sitemaps
+func (h *FeedHandler) SitemapIndex(c *gin.Context) {
+	feedService, err := h.service(c)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build sitemap index")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build sitemap index",
+		})
+		return
+	}
+
+	sitemaps, err := feedService.SitemapIndex(func(page int) string {
+		return feedService.URL(fmt.Sprintf("/sitemaps/posts-%d.xml", page))
+	})
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build sitemap index")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build sitemap index",
+		})
+		return
+	}
+
+	var lastModified time.Time
+	if len(sitemaps) > 0 {
+		lastModified = sitemaps[0].LastMod
+	}
+	h.serve(c, "application/xml; charset=utf-8", lastModified, func(w io.Writer) error {
+		return feed.WriteSitemapIndex(w, sitemaps)
+	})
+}
+
+// Sitemap serves a page of the post sitemap as /sitemaps/posts-:page.xml
+func (h *FeedHandler) Sitemap(c *gin.Context) {
+	name := strings.TrimSuffix(c.Param("file"), ".xml")
+	page, err := strconv.Atoi(strings.TrimPrefix(name, "posts-"))
+	if !strings.HasPrefix(name, "posts-") || err != nil || page < 1 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Sitemap not found",
+		})
+		return
+	}
+
+	feedService, err := h.service(c)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build sitemap")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build sitemap",
+		})
+		return
+	}
+
+	urls, err := feedService.Sitemap(page)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Sitemap not found",
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build sitemap")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build sitemap",
+		})
+		return
+	}
+
+	var lastModified time.Time
+	for _, u := range urls {
+		if u.LastMod.After(lastModified) {
+			lastModified = u.LastMod
+		}
+	}
+	h.serve(c, "application/xml; charset=utf-8", lastModified, func(w io.Writer) error {
+		return feed.WriteSitemap(w, urls)
+	})
+}
+
+// serveFeed builds a posts feed in the format named by the :feed file name
+func (h *FeedHandler) serveFeed(c *gin.Context, build func(s *services.FeedService, feedURL string) (*feed.Feed, error)) {
+	name, format, err := feed.ParseFileName(c.Param("feed"))
+	if err != nil || name != "posts" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Feed not found",
+		})
+		return
+	}
+
+	feedService, err := h.service(c)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build feed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build feed",
+		})
+		return
+	}
+
+	f, err := build(feedService, feedService.URL(c.Request.URL.Path))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Feed not found",
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build feed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build feed",
+		})
+		return
+	}
+
+	h.serve(c, format.ContentType(), f.Updated, func(w io.Writer) error {
+		return f.Write(w, format)
+	})
+}
+
+// service returns the feed service for the request's organization
+func (h *FeedHandler) service(c *gin.Context) (*services.FeedService, error) {
+	return h.feedService.WithContext(c.Request.Context()).ForTenant()
+}
+
+// serve writes a cacheable response. The ETag is a hash of the body, so
+// conditional requests answered with 304 Not Modified still build it but
+// spare the transfer.
+func (h *FeedHandler) serve(c *gin.Context, contentType string, lastModified time.Time, write func(w io.Writer) error) {
+	var body bytes.Buffer
+	if err := write(&body); err != nil {
+		h.logger.WithError(err).Error("Failed to write feed")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to write feed",
+		})
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge.Seconds())))
+	if h.tenancy.Enabled && h.tenancy.Header != "" {
+		// Each organization has its own feeds at the same URLs
+		c.Header("Vary", h.tenancy.Header)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body.Bytes())
+}
+
+// notModified reports whether a conditional GET can be answered with 304.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.IsZero() {
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
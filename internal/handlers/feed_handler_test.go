@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedLinksIgnoreRequestHost(t *testing.T) {
+	router, db := newTestRouter(t, map[string]string{
+		"FRONTEND_URL":   "https://blog.example.com",
+		"FEEDS_BASE_URL": "https://api.example.com/",
+	})
+
+	author := &models.User{Email: "author@example.com", Username: "author", Password: "secret123", FirstName: "Ann", LastName: "Author", Role: models.RoleUser, IsActive: true}
+	require.NoError(t, db.Create(author).Error)
+	now := time.Now()
+	post := &models.Post{Title: "Hello", Content: "Hello world", Slug: "hello", UserID: author.ID, Published: true, Status: models.PostStatusPublished, PublishedAt: &now}
+	require.NoError(t, db.Create(post).Error)
+
+	for path, want := range map[string]string{
+		"/feeds/posts.atom": `href="https://api.example.com/feeds/posts.atom"`,
+		"/sitemap.xml":      "<loc>https://api.example.com/sitemaps/posts-1.xml</loc>",
+	} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Host = "evil.example.net"
+			req.Header.Set("X-Forwarded-Proto", "http")
+			w := serve(router, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			assert.Contains(t, w.Body.String(), want)
+			assert.NotContains(t, w.Body.String(), "evil.example.net")
+			assert.Empty(t, w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestFeedsVaryByTenant(t *testing.T) {
+	router, db := newTestRouter(t, map[string]string{"TENANCY_ENABLED": "true"})
+
+	org := &models.Organization{Name: "Acme", Slug: "acme", IsActive: true}
+	require.NoError(t, db.Create(org).Error)
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/posts.rss", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := serve(router, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "X-Tenant-ID", w.Header().Get("Vary"))
+	assert.Contains(t, w.Header().Get("Cache-Control"), "public")
+}
+
+func TestFeedLinksPointAtTenantSubdomain(t *testing.T) {
+	router, db := newTestRouter(t, map[string]string{
+		"TENANCY_ENABLED":     "true",
+		"TENANCY_BASE_DOMAIN": "example.com",
+		"FRONTEND_URL":        "https://example.com:8443/blog",
+		"FEEDS_BASE_URL":      "https://api.example.com",
+	})
+
+	author := &models.User{Email: "author@example.com", Username: "author", Password: "secret123", FirstName: "Ann", LastName: "Author", Role: models.RoleUser, IsActive: true}
+	require.NoError(t, db.Create(author).Error)
+	now := time.Now()
+	for _, slug := range []string{"acme", "globex"} {
+		org := &models.Organization{Name: slug, Slug: slug, IsActive: true}
+		require.NoError(t, db.Create(org).Error)
+		post := &models.Post{Title: slug, Content: "content", Slug: slug + "-post", UserID: author.ID, Published: true, Status: models.PostStatusPublished, PublishedAt: &now}
+		require.NoError(t, db.WithContext(tenant.WithTenant(context.Background(), org.ID)).Create(post).Error)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		contains []string
+		excludes []string
+	}{
+		{
+			"feed by subdomain", "/feeds/posts.atom", map[string]string{"Host": "acme.example.com"},
+			[]string{`href="https://acme.example.com/feeds/posts.atom"`, `href="https://acme.example.com:8443/blog/posts/acme-post"`},
+			[]string{"globex", "api.example.com"},
+		},
+		{
+			"feed by header", "/feeds/posts.atom", map[string]string{"X-Tenant-ID": "globex"},
+			[]string{`href="https://globex.example.com/feeds/posts.atom"`, `href="https://globex.example.com:8443/blog/posts/globex-post"`},
+			[]string{"acme", "api.example.com"},
+		},
+		{
+			"sitemap index", "/sitemap.xml", map[string]string{"Host": "acme.example.com"},
+			[]string{"<loc>https://acme.example.com/sitemaps/posts-1.xml</loc>"},
+			nil,
+		},
+		{
+			"sitemap", "/sitemaps/posts-1.xml", map[string]string{"Host": "acme.example.com"},
+			[]string{"<loc>https://acme.example.com:8443/blog/posts/acme-post</loc>"},
+			[]string{"globex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, value := range tt.headers {
+				if key == "Host" {
+					req.Host = value
+				} else {
+					req.Header.Set(key, value)
+				}
+			}
+			w := serve(router, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			for _, want := range tt.contains {
+				assert.Contains(t, w.Body.String(), want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, w.Body.String(), unwanted)
+			}
+		})
+	}
+}
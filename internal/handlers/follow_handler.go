@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// FollowHandler handles following users and the home feed
+type FollowHandler struct {
+	followService *services.FollowService
+	logger        *logger.Logger
+}
+
+// NewFollowHandler creates a new follow handler
+func NewFollowHandler(followService *services.FollowService, logger *logger.Logger) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+		logger:        logger,
+	}
+}
+
+// Follow follows the :id user. The optional body chooses whether to be
+// notified of their new posts.
+func (h *FollowHandler) Follow(c *gin.Context) {
+	followeeID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req models.FollowRequest
+	if c.Request.ContentLength != 0 {
+		if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Validation failed",
+				"errors": errors,
+			})
+			return
+		}
+	}
+
+	userID := c.GetUint("user_id")
+	follow, err := h.service(c).Follow(userID, followeeID, req.Notify)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to follow user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"follower_id": userID,
+		"followee_id": followeeID,
+		"notify":      follow.Notify,
+	}).Info("User followed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User followed successfully",
+		"data":    follow,
+	})
+}
+
+// Unfollow stops following the :id user
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	followeeID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).Unfollow(userID, followeeID); err != nil {
+		h.respondWithError(c, err, "Failed to unfollow user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"follower_id": userID,
+		"followee_id": followeeID,
+	}).Info("User unfollowed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unfollowed successfully",
+	})
+}
+
+// Block blocks the :id user
+func (h *FollowHandler) Block(c *gin.Context) {
+	blockedID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	block, err := h.service(c).Block(userID, blockedID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to block user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"blocker_id": userID,
+		"blocked_id": blockedID,
+	}).Info("User blocked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User blocked successfully",
+		"data":    block,
+	})
+}
+
+// Unblock removes the block of the :id user
+func (h *FollowHandler) Unblock(c *gin.Context) {
+	blockedID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).Unblock(userID, blockedID); err != nil {
+		h.respondWithError(c, err, "Failed to unblock user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"blocker_id": userID,
+		"blocked_id": blockedID,
+	}).Info("User unblocked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unblocked successfully",
+	})
+}
+
+// GetBlocks lists the users the current user has blocked
+func (h *FollowHandler) GetBlocks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := h.service(c).Blocked(c.GetUint("user_id"), options)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch blocked users")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"users": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// GetFollowers lists the users following the :id user
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	h.listUsers(c, (*services.FollowService).Followers)
+}
+
+// GetFollowing lists the users the :id user follows
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	h.listUsers(c, (*services.FollowService).Following)
+}
+
+// GetFollowStats counts the :id user's followers and followed users
+func (h *FollowHandler) GetFollowStats(c *gin.Context) {
+	userID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	stats, err := h.service(c).Stats(userID, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch follow counts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stats,
+	})
+}
+
+// GetFeed returns the user's home feed of posts by the authors they follow.
+// ?cursor= takes the next_cursor of the previous page.
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	feed, err := h.service(c).Feed(c.GetUint("user_id"), c.Query("cursor"), limit)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": feed,
+	})
+}
+
+// listUsers lists the followers or followed users of the :id user
+func (h *FollowHandler) listUsers(c *gin.Context, list func(*services.FollowService, uint, services.QueryOptions) (*services.PaginatedResult[models.FollowUser], error)) {
+	userID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := list(h.service(c), userID, options)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch users")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"users": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// service returns the follow service scoped to the request context
+func (h *FollowHandler) service(c *gin.Context) *services.FollowService {
+	return h.followService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps follow service errors to responses
+func (h *FollowHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+	case errors.Is(err, services.ErrCannotFollowSelf), errors.Is(err, services.ErrCannotBlockSelf),
+		errors.Is(err, services.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrBlocked), errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GroupHandler handles group, membership and group grant requests
+type GroupHandler struct {
+	groupService *services.GroupService
+	logger       *logger.Logger
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(groupService *services.GroupService, logger *logger.Logger) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+		logger:       logger,
+	}
+}
+
+// ListGroups lists all groups (admin only)
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groupService.ListGroups()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list groups")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch groups",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": groups,
+	})
+}
+
+// GetGroup gets a group by ID (admin only)
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	group, err := h.groupService.GetGroup(groupID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": group,
+	})
+}
+
+// CreateGroup creates a new group (admin only)
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req models.GroupCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create group")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   group.ID,
+		"name":       group.Name,
+		"created_by": c.GetUint("user_id"),
+	}).Info("Group created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Group created successfully",
+		"data":    group,
+	})
+}
+
+// UpdateGroup updates a group (admin only)
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.GroupUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(c.GetUint("user_id"), groupID, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update group")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   group.ID,
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Group updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Group updated successfully",
+		"data":    group,
+	})
+}
+
+// DeleteGroup deletes a group (admin only)
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(c.GetUint("user_id"), groupID); err != nil {
+		h.logger.WithError(err).Error("Failed to delete group")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Group deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Group deleted successfully",
+	})
+}
+
+// ListMembers lists a group's direct members (admin only)
+func (h *GroupHandler) ListMembers(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.groupService.ListMembers(groupID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": members,
+	})
+}
+
+// AddMember adds a user to a group (admin only)
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.GroupMemberRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	if err := h.groupService.AddMember(c.GetUint("user_id"), groupID, req.UserID); err != nil {
+		h.logger.WithError(err).Error("Failed to add group member")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id": groupID,
+		"user_id":  req.UserID,
+		"added_by": c.GetUint("user_id"),
+	}).Info("Group member added successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Member added successfully",
+	})
+}
+
+// RemoveMember removes a user from a group (admin only)
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := idFromParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.RemoveMember(c.GetUint("user_id"), groupID, userID); err != nil {
+		h.logger.WithError(err).Error("Failed to remove group member")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"user_id":    userID,
+		"removed_by": c.GetUint("user_id"),
+	}).Info("Group member removed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// GrantRole grants a role to a group (admin only)
+func (h *GroupHandler) GrantRole(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.GroupRoleRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	if err := h.groupService.GrantRole(c.GetUint("user_id"), groupID, req.Role); err != nil {
+		h.logger.WithError(err).Error("Failed to grant group role")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"role":       req.Role,
+		"granted_by": c.GetUint("user_id"),
+	}).Info("Group role granted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role granted successfully",
+	})
+}
+
+// RevokeRole revokes a role from a group (admin only)
+func (h *GroupHandler) RevokeRole(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	role := models.Role(c.Param("role"))
+	if err := h.groupService.RevokeRole(c.GetUint("user_id"), groupID, role); err != nil {
+		h.logger.WithError(err).Error("Failed to revoke group role")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"role":       role,
+		"revoked_by": c.GetUint("user_id"),
+	}).Info("Group role revoked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role revoked successfully",
+	})
+}
+
+// GrantPermission grants a permission directly to a group (admin only)
+func (h *GroupHandler) GrantPermission(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.RolePermissionRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	if err := h.groupService.GrantPermission(c.GetUint("user_id"), groupID, req.Permission); err != nil {
+		h.logger.WithError(err).Error("Failed to grant group permission")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"permission": req.Permission,
+		"granted_by": c.GetUint("user_id"),
+	}).Info("Group permission granted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission granted successfully",
+	})
+}
+
+// RevokePermission revokes a permission from a group (admin only)
+func (h *GroupHandler) RevokePermission(c *gin.Context) {
+	groupID, ok := idFromParam(c, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	permission := c.Param("permission")
+	if err := h.groupService.RevokePermission(c.GetUint("user_id"), groupID, permission); err != nil {
+		h.logger.WithError(err).Error("Failed to revoke group permission")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_id":   groupID,
+		"permission": permission,
+		"revoked_by": c.GetUint("user_id"),
+	}).Info("Group permission revoked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission revoked successfully",
+	})
+}
+
+// GetMyGroups lists the groups the current user belongs to
+func (h *GroupHandler) GetMyGroups(c *gin.Context) {
+	groups, err := h.groupService.ListUserGroups(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list user groups")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch groups",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": groups,
+	})
+}
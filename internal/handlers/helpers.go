@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithNoTenant answers requests that reached tenant-scoped data
+// without resolving an organization
+func respondWithNoTenant(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "Organization is required",
+	})
+}
+
+// idFromParam reads and validates a numeric ID path parameter, responding
+// with message if it is invalid
+func idFromParam(c *gin.Context, param, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": message,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportExportHandler handles exporting posts as bundles and importing them
+// from bundles and WordPress exports
+type ImportExportHandler struct {
+	exportService *services.ExportService
+	importService *services.ImportService
+	logger        *logger.Logger
+}
+
+// NewImportExportHandler creates a new import/export handler
+func NewImportExportHandler(exportService *services.ExportService, importService *services.ImportService, logger *logger.Logger) *ImportExportHandler {
+	return &ImportExportHandler{
+		exportService: exportService,
+		importService: importService,
+		logger:        logger,
+	}
+}
+
+// ExportPosts downloads the user's posts, another user's with ?user_id=
+// or everyone's with ?all=true (both admin only), as a zip bundle of
+// Markdown files
+func (h *ImportExportHandler) ExportPosts(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	all := c.Query("all") == "true"
+
+	var targetID uint
+	if target := c.Query("user_id"); target != "" {
+		id, err := strconv.ParseUint(target, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID",
+			})
+			return
+		}
+		targetID = uint(id)
+	}
+
+	export, err := h.exportService.WithContext(c.Request.Context()).Export(userID, targetID, all)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to export posts")
+		return
+	}
+
+	fileName := fmt.Sprintf("posts-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+
+	// The status is sent with the first byte, so a failure now can only
+	// cut the download short
+	if err := export.Write(c.Writer); err != nil {
+		h.logger.WithError(err).Error("Failed to write post export")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"target_id": targetID,
+		"all":       all,
+		"posts":     len(export.Posts),
+	}).Info("Posts exported successfully")
+}
+
+// ImportPosts imports the posts of a bundle or WordPress export uploaded in
+// the "file" field. With dry_run set it only reports what it would do.
+func (h *ImportExportHandler) ImportPosts(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": map[string]string{"file": "This field is required"},
+		})
+		return
+	}
+
+	var req models.ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": map[string]string{"binding": err.Error()},
+		})
+		return
+	}
+	if errors := utils.NewValidator().ValidateStruct(&req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	report, err := h.importService.WithContext(c.Request.Context()).Import(userID, fileHeader, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to import posts")
+		return
+	}
+
+	message := "Posts imported successfully"
+	if req.DryRun {
+		message = "Import checked successfully"
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"format":    report.Format,
+		"dry_run":   report.DryRun,
+		"created":   report.Created,
+		"skipped":   report.Skipped,
+		"conflicts": report.Conflicts,
+	}).Info(message)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    report,
+	})
+}
+
+// respondWithError maps import and export errors to responses
+func (h *ImportExportHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	var invalidFile services.FileValidationError
+	switch {
+	case errors.As(err, &invalidFile):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": map[string]string{invalidFile.Field: invalidFile.Message},
+		})
+	case errors.Is(err, services.ErrInvalidImport):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrExportUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InboxHandler handles the current user's in-app notifications and mentions
+type InboxHandler struct {
+	inboxService   *services.InboxService
+	mentionService *services.MentionService
+	logger         *logger.Logger
+}
+
+// NewInboxHandler creates a new inbox handler
+func NewInboxHandler(inboxService *services.InboxService, mentionService *services.MentionService, logger *logger.Logger) *InboxHandler {
+	return &InboxHandler{
+		inboxService:   inboxService,
+		mentionService: mentionService,
+		logger:         logger,
+	}
+}
+
+// ListNotifications lists the user's notifications, newest first. Only
+// unread ones are listed with ?unread=true.
+func (h *InboxHandler) ListNotifications(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	userID := c.GetUint("user_id")
+	service := h.inboxService.WithContext(c.Request.Context())
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := service.List(userID, unreadOnly, options)
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch notifications")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch notifications",
+		})
+		return
+	}
+
+	unread, err := service.UnreadCount(userID)
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count unread notifications")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch notifications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"notifications": result.Data,
+			"unread":        unread,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// MarkNotificationRead marks one of the user's notifications as read
+func (h *InboxHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, ok := idFromParam(c, "id", "Invalid notification ID")
+	if !ok {
+		return
+	}
+
+	err := h.inboxService.WithContext(c.Request.Context()).MarkRead(c.GetUint("user_id"), notificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Notification not found",
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to mark notification as read")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to mark notification as read",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllNotificationsRead marks all of the user's notifications as read
+func (h *InboxHandler) MarkAllNotificationsRead(c *gin.Context) {
+	count, err := h.inboxService.WithContext(c.Request.Context()).MarkAllRead(c.GetUint("user_id"))
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to mark notifications as read")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to mark notifications as read",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read",
+		"data": gin.H{
+			"count": count,
+		},
+	})
+}
+
+// ListMentions lists the posts and comments the user was mentioned in,
+// newest first
+func (h *InboxHandler) ListMentions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := h.mentionService.WithContext(c.Request.Context()).ForUser(c.GetUint("user_id"), options)
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch mentions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch mentions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"mentions": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
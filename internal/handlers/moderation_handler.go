@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ModerationHandler handles reports and moderation actions
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+	logger            *logger.Logger
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(moderationService *services.ModerationService, logger *logger.Logger) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		logger:            logger,
+	}
+}
+
+// CreateReport reports a post, comment or user to the moderators
+func (h *ModerationHandler) CreateReport(c *gin.Context) {
+	var req models.ReportCreateRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	report, err := h.service(c).CreateReport(userID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to create report")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"report_id":   report.ID,
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"reporter_id": userID,
+	}).Info("Report created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Report submitted successfully",
+		"data":    report,
+	})
+}
+
+// ListReports lists the report queue. ?status= defaults to open and in
+// review reports; ?assignee= takes a user ID or "me".
+func (h *ModerationHandler) ListReports(c *gin.Context) {
+	filter := services.ReportFilter{
+		Status:     models.ReportStatus(c.Query("status")),
+		TargetType: models.ReportTarget(c.Query("target_type")),
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid report status",
+		})
+		return
+	}
+	if assignee := c.Query("assignee"); assignee != "" {
+		assigneeID := c.GetUint("user_id")
+		if assignee != "me" {
+			id, err := strconv.ParseUint(assignee, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid assignee",
+				})
+				return
+			}
+			assigneeID = uint(id)
+		}
+		filter.AssigneeID = &assigneeID
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	options := services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+	result, err := h.service(c).ListReports(c.GetUint("user_id"), filter, options)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch reports")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"reports": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// GetReport gets a report with the actions taken on it
+func (h *ModerationHandler) GetReport(c *gin.Context) {
+	reportID, ok := idFromParam(c, "id", "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	report, err := h.service(c).GetReport(c.GetUint("user_id"), reportID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// UpdateReport assigns a report or changes its status
+func (h *ModerationHandler) UpdateReport(c *gin.Context) {
+	reportID, ok := idFromParam(c, "id", "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	var req models.ReportUpdateRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	report, err := h.service(c).UpdateReport(userID, reportID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to update report")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"report_id":    report.ID,
+		"status":       report.Status,
+		"assignee_id":  report.AssigneeID,
+		"moderated_by": userID,
+	}).Info("Report updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Report updated successfully",
+		"data":    report,
+	})
+}
+
+// ActOnReport takes action against the target of a report
+func (h *ModerationHandler) ActOnReport(c *gin.Context) {
+	reportID, ok := idFromParam(c, "id", "Invalid report ID")
+	if !ok {
+		return
+	}
+	h.takeAction(c, &reportID)
+}
+
+// TakeAction takes action against a post, comment or user
+func (h *ModerationHandler) TakeAction(c *gin.Context) {
+	h.takeAction(c, nil)
+}
+
+// takeAction takes the requested action, on the report's target if a
+// report is given
+func (h *ModerationHandler) takeAction(c *gin.Context, reportID *uint) {
+	var req models.ModerationActionRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+	if reportID != nil {
+		req.ReportID = reportID
+	}
+
+	userID := c.GetUint("user_id")
+	action, err := h.service(c).TakeAction(userID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to take moderation action")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"action_id":      action.ID,
+		"action":         action.Action,
+		"target_type":    action.TargetType,
+		"target_id":      action.TargetID,
+		"target_user_id": action.TargetUserID,
+		"moderated_by":   userID,
+	}).Info("Moderation action taken successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Moderation action taken successfully",
+		"data":    action,
+	})
+}
+
+// service returns the moderation service scoped to the request context
+func (h *ModerationHandler) service(c *gin.Context) *services.ModerationService {
+	return h.moderationService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps moderation service errors to responses
+func (h *ModerationHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not found",
+		})
+	case errors.Is(err, services.ErrAlreadyReported):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrCannotReportSelf), errors.Is(err, services.ErrInvalidAssignee),
+		errors.Is(err, services.ErrActionTarget), errors.Is(err, services.ErrCannotHideUser),
+		errors.Is(err, services.ErrSuspensionDays):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OrganizationHandler handles organization and membership requests
+type OrganizationHandler struct {
+	orgService  *services.OrganizationService
+	userService *services.UserService
+	jwtService  *utils.JWTService
+	logger      *logger.Logger
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgService *services.OrganizationService, userService *services.UserService, jwtService *utils.JWTService, logger *logger.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgService:  orgService,
+		userService: userService,
+		jwtService:  jwtService,
+		logger:      logger,
+	}
+}
+
+// ListOrganizations lists the current user's organizations and roles
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	memberships, err := h.orgService.ListUserOrganizations(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list organizations")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch organizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": memberships,
+	})
+}
+
+// CreateOrganization creates an organization owned by the current user
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.OrganizationCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	org, err := h.orgService.CreateOrganization(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create organization")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"organization_id": org.ID,
+		"slug":            org.Slug,
+		"created_by":      c.GetUint("user_id"),
+	}).Info("Organization created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Organization created successfully",
+		"data":    org,
+	})
+}
+
+// GetOrganization gets an organization the current user belongs to
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	org, err := h.orgService.GetMemberOrganization(c.GetUint("user_id"), orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": org,
+	})
+}
+
+// UpdateOrganization updates an organization (owners and admins)
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req models.OrganizationUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	org, err := h.orgService.UpdateOrganization(c.GetUint("user_id"), orgID, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update organization")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organization updated successfully",
+		"data":    org,
+	})
+}
+
+// ListMembers lists an organization's members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.orgService.ListMembers(c.GetUint("user_id"), orgID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": members,
+	})
+}
+
+// AddMember adds a user to an organization (owners and admins)
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req models.MemberAddRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	member, err := h.orgService.AddMember(c.GetUint("user_id"), orgID, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to add organization member")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"organization_id": orgID,
+		"user_id":         member.UserID,
+		"role":            member.Role,
+		"added_by":        c.GetUint("user_id"),
+	}).Info("Organization member added successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Member added successfully",
+		"data":    member,
+	})
+}
+
+// UpdateMember changes a member's role (owners and admins)
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := idFromParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req models.MemberUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	member, err := h.orgService.UpdateMemberRole(c.GetUint("user_id"), orgID, userID, req.Role)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update organization member")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"organization_id": orgID,
+		"user_id":         member.UserID,
+		"role":            member.Role,
+		"updated_by":      c.GetUint("user_id"),
+	}).Info("Organization member updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member updated successfully",
+		"data":    member,
+	})
+}
+
+// RemoveMember removes a user from an organization
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := idFromParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.orgService.RemoveMember(c.GetUint("user_id"), orgID, userID); err != nil {
+		h.logger.WithError(err).Error("Failed to remove organization member")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"organization_id": orgID,
+		"user_id":         userID,
+		"removed_by":      c.GetUint("user_id"),
+	}).Info("Organization member removed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// IssueToken issues a token scoped to an organization the user belongs to
+func (h *OrganizationHandler) IssueToken(c *gin.Context) {
+	orgID, ok := idFromParam(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if _, err := h.orgService.GetMembership(orgID, userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	token, err := h.jwtService.GenerateTenantToken(user, orgID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate tenant token")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"token":           token,
+			"organization_id": orgID,
+		},
+	})
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PermissionHandler handles permission and role grant management requests
+type PermissionHandler struct {
+	permissionService *services.PermissionService
+	logger            *logger.Logger
+}
+
+// NewPermissionHandler creates a new permission handler
+func NewPermissionHandler(permissionService *services.PermissionService, logger *logger.Logger) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+		logger:            logger,
+	}
+}
+
+// ListPermissions lists all permissions (admin only)
+func (h *PermissionHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.ListPermissions()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list permissions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch permissions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": permissions,
+	})
+}
+
+// CreatePermission creates a new permission (admin only)
+func (h *PermissionHandler) CreatePermission(c *gin.Context) {
+	var req models.PermissionCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	permission, err := h.permissionService.CreatePermission(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create permission")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"permission": permission.Name,
+		"created_by": c.GetUint("user_id"),
+	}).Info("Permission created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Permission created successfully",
+		"data":    permission,
+	})
+}
+
+// DeletePermission deletes a permission and its grants (admin only)
+func (h *PermissionHandler) DeletePermission(c *gin.Context) {
+	id, ok := idFromParam(c, "id", "Invalid permission ID")
+	if !ok {
+		return
+	}
+
+	if err := h.permissionService.DeletePermission(c.GetUint("user_id"), id); err != nil {
+		h.logger.WithError(err).Error("Failed to delete permission")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"permission_id": id,
+		"deleted_by":    c.GetUint("user_id"),
+	}).Info("Permission deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission deleted successfully",
+	})
+}
+
+// GetRolePermissions lists the permissions granted to a role (admin only)
+func (h *PermissionHandler) GetRolePermissions(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.permissionService.GetRolePermissions(role)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get role permissions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch role permissions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"role":        role,
+			"permissions": permissions,
+		},
+	})
+}
+
+// GrantRolePermission grants a permission to a role (admin only)
+func (h *PermissionHandler) GrantRolePermission(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.RolePermissionRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	if err := h.permissionService.GrantPermission(c.GetUint("user_id"), role, req.Permission); err != nil {
+		h.logger.WithError(err).Error("Failed to grant permission")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"role":       role,
+		"permission": req.Permission,
+		"granted_by": c.GetUint("user_id"),
+	}).Info("Permission granted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission granted successfully",
+	})
+}
+
+// RevokeRolePermission revokes a permission from a role (admin only)
+func (h *PermissionHandler) RevokeRolePermission(c *gin.Context) {
+	role, ok := h.roleFromParam(c)
+	if !ok {
+		return
+	}
+
+	permission := c.Param("permission")
+	if err := h.permissionService.RevokePermission(c.GetUint("user_id"), role, permission); err != nil {
+		h.logger.WithError(err).Error("Failed to revoke permission")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"role":       role,
+		"permission": permission,
+		"revoked_by": c.GetUint("user_id"),
+	}).Info("Permission revoked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission revoked successfully",
+	})
+}
+
+// roleFromParam reads and validates the :name role parameter
+func (h *PermissionHandler) roleFromParam(c *gin.Context) (models.Role, bool) {
+	role := models.Role(c.Param("name"))
+	if !role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid role",
+		})
+		return "", false
+	}
+	return role, true
+}
@@ -0,0 +1,588 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/etag"
+	"go-backend/internal/models"
+	"go-backend/internal/search"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// PostHandler handles post-related requests
+type PostHandler struct {
+	postService     *services.PostService
+	revisionService *services.RevisionService
+	viewService     *services.ViewService
+	logger          *logger.Logger
+}
+
+// NewPostHandler creates a new post handler
+func NewPostHandler(postService *services.PostService, revisionService *services.RevisionService, viewService *services.ViewService, logger *logger.Logger) *PostHandler {
+	return &PostHandler{
+		postService:     postService,
+		revisionService: revisionService,
+		viewService:     viewService,
+		logger:          logger,
+	}
+}
+
+// ListPosts lists published posts with pagination
+func (h *PostHandler) ListPosts(c *gin.Context) {
+	options := h.queryOptions(c)
+
+	result, err := h.service(c).GetPublishedPosts(options)
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list posts")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch posts",
+		})
+		return
+	}
+
+	h.respondWithPage(c, result)
+}
+
+// SearchPosts searches published posts by title and content, best matches
+// first. The query supports "exact phrases" and prefix* matching.
+func (h *PostHandler) SearchPosts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Search query is required",
+		})
+		return
+	}
+
+	result, err := h.service(c).SearchPosts(query, h.queryOptions(c))
+	if errors.Is(err, search.ErrEmptyQuery) || errors.Is(err, search.ErrTooManyTerms) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to search posts")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search posts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"posts": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// ListPostsByTag lists published posts carrying the :slug tag
+func (h *PostHandler) ListPostsByTag(c *gin.Context) {
+	result, err := h.service(c).GetPublishedPostsByTag(c.Param("slug"), h.queryOptions(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Tag not found",
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list posts by tag")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch posts",
+		})
+		return
+	}
+
+	h.respondWithPage(c, result)
+}
+
+// ListPostsByCategory lists published posts filed under the :slug category
+// or its subcategories
+func (h *PostHandler) ListPostsByCategory(c *gin.Context) {
+	result, err := h.service(c).GetPublishedPostsByCategory(c.Param("slug"), h.queryOptions(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Category not found",
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list posts by category")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch posts",
+		})
+		return
+	}
+
+	h.respondWithPage(c, result)
+}
+
+// GetPost gets a post by ID
+func (h *PostHandler) GetPost(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	post, err := h.service(c).GetPostForUser(postID, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch post")
+		return
+	}
+	h.recordView(c, post)
+
+	c.Header("ETag", etag.Format(post.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": post,
+	})
+}
+
+// GetPostBySlug gets a post by its slug
+func (h *PostHandler) GetPostBySlug(c *gin.Context) {
+	post, err := h.service(c).GetPostBySlugForUser(c.Param("slug"), c.GetUint("user_id"))
+	var moved *services.SlugMovedError
+	if errors.As(err, &moved) {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/posts/by-slug/"+url.PathEscape(moved.Slug))
+		return
+	}
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch post")
+		return
+	}
+	h.recordView(c, post)
+
+	c.Header("ETag", etag.Format(post.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"data": post,
+	})
+}
+
+// CreatePost creates a new post
+func (h *PostHandler) CreatePost(c *gin.Context) {
+	var req models.PostCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	post, err := h.service(c).CreatePost(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to create post")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id": post.ID,
+		"slug":    post.Slug,
+		"status":  post.Status,
+		"user_id": post.UserID,
+	}).Info("Post created successfully")
+
+	c.Header("ETag", etag.Format(post.Version))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Post created successfully",
+		"data":    post,
+	})
+}
+
+// UpdatePost updates a post (author, editors it is shared with, moderators)
+func (h *PostHandler) UpdatePost(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	var req models.PostUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	post, err := h.service(c).UpdatePost(postID, c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to update post")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":    post.ID,
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Post updated successfully")
+
+	c.Header("ETag", etag.Format(post.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post updated successfully",
+		"data":    post,
+	})
+}
+
+// ChangeStatus moves a post through the editorial workflow
+func (h *PostHandler) ChangeStatus(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	var req models.PostStatusChangeRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	post, err := h.service(c).ChangeStatus(postID, c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to change post status")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":    post.ID,
+		"status":     post.Status,
+		"publish_at": post.PublishAt,
+		"changed_by": c.GetUint("user_id"),
+	}).Info("Post status changed successfully")
+
+	c.Header("ETag", etag.Format(post.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post status changed successfully",
+		"data":    post,
+	})
+}
+
+// DeletePost deletes a post (author, moderators)
+func (h *PostHandler) DeletePost(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).DeletePost(postID, c.GetUint("user_id")); err != nil {
+		h.respondWithError(c, err, "Failed to delete post")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":    postID,
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Post deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post deleted successfully",
+	})
+}
+
+// BulkDeletePosts deletes several posts at once (admin only)
+func (h *PostHandler) BulkDeletePosts(c *gin.Context) {
+	var req models.PostBulkDeleteRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	if err := h.service(c).BulkDeletePosts(req.IDs, c.GetUint("user_id")); err != nil {
+		h.respondWithError(c, err, "Failed to delete posts")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"count":      len(req.IDs),
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Posts deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Posts deleted successfully",
+	})
+}
+
+// ListRevisions lists the revisions of a post
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	revisions, err := h.revisions(c).ListRevisions(postID, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": revisions,
+	})
+}
+
+// GetRevision gets a revision of a post by its number
+func (h *PostHandler) GetRevision(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+	number, ok := h.revisionNumberFromParam(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.revisions(c).GetRevision(postID, number, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithRevisionError(c, err, "Failed to fetch revision")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": revision,
+	})
+}
+
+// DiffRevisions compares the ?from= and ?to= revisions of a post as a
+// unified diff or, with ?mode=words, word by word
+func (h *PostHandler) DiffRevisions(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	from, fromErr := strconv.Atoi(c.Query("from"))
+	to, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to revision numbers are required",
+		})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", services.DiffModeUnified)
+	diff, err := h.revisions(c).Diff(postID, from, to, mode, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithRevisionError(c, err, "Failed to diff revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": diff,
+	})
+}
+
+// RestoreRevision restores a post to one of its revisions
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+	number, ok := h.revisionNumberFromParam(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.service(c).RestoreRevision(postID, number, c.GetUint("user_id"))
+	if err != nil {
+		h.respondWithRevisionError(c, err, "Failed to restore revision")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":     post.ID,
+		"revision":    number,
+		"restored_by": c.GetUint("user_id"),
+	}).Info("Revision restored successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Revision restored successfully",
+		"data":    post,
+	})
+}
+
+// service returns the post service scoped to the request context
+func (h *PostHandler) service(c *gin.Context) *services.PostService {
+	return h.postService.WithContext(c.Request.Context())
+}
+
+// revisions returns the revision service scoped to the request context
+func (h *PostHandler) revisions(c *gin.Context) *services.RevisionService {
+	return h.revisionService.WithContext(c.Request.Context())
+}
+
+// recordView counts a view of the post by the requesting user or, for
+// anonymous visitors, by their IP address and user agent. Authors viewing
+// their own posts are not counted. Failures are logged but do not fail the
+// request.
+func (h *PostHandler) recordView(c *gin.Context, post *models.Post) {
+	userID := c.GetUint("user_id")
+	if userID != 0 && userID == post.UserID {
+		return
+	}
+
+	viewerKey := "user:" + strconv.FormatUint(uint64(userID), 10)
+	if userID == 0 {
+		sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+		viewerKey = "anon:" + hex.EncodeToString(sum[:16])
+	}
+
+	counted, err := h.viewService.WithContext(c.Request.Context()).RecordView(post.ID, viewerKey)
+	if err != nil {
+		h.logger.WithError(err).WithField("post_id", post.ID).Warn("Failed to record post view")
+		return
+	}
+	if counted {
+		post.ViewCount++
+	}
+}
+
+// queryOptions reads page and limit query parameters
+func (h *PostHandler) queryOptions(c *gin.Context) services.QueryOptions {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	return services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+		Sort:       []services.SortOptions{{Field: "created_at", Direction: "desc"}},
+	}
+}
+
+// respondWithPage writes a page of posts
+func (h *PostHandler) respondWithPage(c *gin.Context, result *services.PaginatedResult[models.Post]) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"posts": result.Data,
+			"pagination": gin.H{
+				"page":  result.Page,
+				"limit": result.PageSize,
+				"total": result.Total,
+				"pages": result.TotalPages,
+			},
+		},
+	})
+}
+
+// respondWithError maps post service errors to responses
+func (h *PostHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Post not found",
+		})
+	case errors.Is(err, services.ErrSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrPreconditionFailed):
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrPublishRequiresReview):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidSlug), errors.Is(err, services.ErrPublishAtRequired):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
+
+// respondWithRevisionError maps revision errors to responses
+func (h *PostHandler) respondWithRevisionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Revision not found",
+		})
+	case errors.Is(err, services.ErrInvalidDiffMode):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.respondWithError(c, err, message)
+	}
+}
+
+// revisionNumberFromParam reads and validates the :number revision parameter
+func (h *PostHandler) revisionNumberFromParam(c *gin.Context) (int, bool) {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid revision number",
+		})
+		return 0, false
+	}
+	return number, true
+}
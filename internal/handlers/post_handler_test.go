@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicPayloadsHideAuthorAccounts(t *testing.T) {
+	router, db := newTestRouter(t, nil)
+
+	phone := "+15550100"
+	author := &models.User{
+		Email:       "author@example.com",
+		Username:    "author",
+		Password:    "secret123",
+		FirstName:   "Ann",
+		LastName:    "Author",
+		Role:        models.RoleUser,
+		IsActive:    true,
+		PhoneNumber: phone,
+	}
+	require.NoError(t, db.Create(author).Error)
+
+	now := time.Now()
+	post := &models.Post{
+		Title:       "Hello",
+		Content:     "Hello world",
+		Slug:        "hello",
+		UserID:      author.ID,
+		Published:   true,
+		Status:      models.PostStatusPublished,
+		PublishedAt: &now,
+	}
+	require.NoError(t, db.Create(post).Error)
+	comment := &models.Comment{Content: "First", UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+	reply := &models.Comment{Content: "Reply", UserID: author.ID, PostID: post.ID, ParentID: &comment.ID, Depth: 1, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(reply).Error)
+	file := &models.FileUpload{UserID: author.ID, OriginalName: "hello.txt", FileName: "hello-1.txt", FilePath: "hello-1.txt", IsPublic: true}
+	require.NoError(t, db.Create(file).Error)
+
+	for _, path := range []string{
+		"/api/v1/posts",
+		fmt.Sprintf("/api/v1/posts/%d", post.ID),
+		"/api/v1/posts/by-slug/hello",
+		fmt.Sprintf("/api/v1/posts/%d/comments", post.ID),
+		"/api/v1/posts/search?q=hello",
+		"/api/v1/search?q=hello",
+		"/api/v1/search?q=first",
+	} {
+		t.Run(path, func(t *testing.T) {
+			w := serve(router, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			body := w.Body.String()
+			assert.Contains(t, body, `"username":"author"`)
+			for _, private := range []string{author.Email, phone, `"email"`, `"phone_number"`, `"role"`, `"two_factor_enabled"`} {
+				assert.NotContains(t, body, private)
+			}
+		})
+	}
+}
+
+func TestPostsAreScopedToTenant(t *testing.T) {
+	router, db := newTestRouter(t, map[string]string{"TENANCY_ENABLED": "true"})
+
+	author := &models.User{Email: "author@example.com", Username: "author", Password: "secret123", FirstName: "Ann", LastName: "Author", Role: models.RoleUser, IsActive: true}
+	require.NoError(t, db.Create(author).Error)
+	now := time.Now()
+	for _, slug := range []string{"acme", "globex"} {
+		org := &models.Organization{Name: slug, Slug: slug, IsActive: true}
+		require.NoError(t, db.Create(org).Error)
+		post := &models.Post{Title: slug, Content: "content", Slug: slug + "-post", UserID: author.ID, Published: true, Status: models.PostStatusPublished, PublishedAt: &now}
+		require.NoError(t, db.WithContext(tenant.WithTenant(context.Background(), org.ID)).Create(post).Error)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := serve(router, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "acme-post")
+	assert.NotContains(t, w.Body.String(), "globex-post")
+
+	w = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/posts/by-slug/globex-post", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "requests without an organization cannot reach tenant data")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/posts/by-slug/globex-post", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	w = serve(router, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevisionsHideEditorAccounts(t *testing.T) {
+	router, db := newTestRouter(t, nil)
+
+	author := &models.User{Email: "author@example.com", Username: "author", Password: "secret123", FirstName: "Ann", LastName: "Author", Role: models.RoleUser, IsActive: true, PhoneNumber: "+15550100"}
+	require.NoError(t, db.Create(author).Error)
+	post := &models.Post{Title: "Hello", Content: "Hello world", Slug: "hello", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+	require.NoError(t, db.Create(&models.PostRevision{PostID: post.ID, Number: 1, Title: "Hello", Content: "Hello", EditorID: author.ID}).Error)
+	token, err := router.jwtService.GenerateToken(author)
+	require.NoError(t, err)
+
+	for _, path := range []string{
+		fmt.Sprintf("/api/v1/posts/%d/revisions", post.ID),
+		fmt.Sprintf("/api/v1/posts/%d/revisions/1", post.ID),
+	} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := serve(router, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			body := w.Body.String()
+			assert.Contains(t, body, `"username":"author"`)
+			for _, private := range []string{author.Email, author.PhoneNumber, `"email"`, `"phone_number"`, `"role"`, `"two_factor_enabled"`} {
+				assert.NotContains(t, body, private)
+			}
+		})
+	}
+}
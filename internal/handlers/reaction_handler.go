@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ReactionHandler handles emoji reactions to posts and comments
+type ReactionHandler struct {
+	reactionService *services.ReactionService
+	logger          *logger.Logger
+}
+
+// NewReactionHandler creates a new reaction handler
+func NewReactionHandler(reactionService *services.ReactionService, logger *logger.Logger) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+		logger:          logger,
+	}
+}
+
+// ListEmoji lists the emoji users can react with
+func (h *ReactionHandler) ListEmoji(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.reactionService.Emoji(),
+	})
+}
+
+// ReactToPost adds the user's :emoji reaction to a post
+func (h *ReactionHandler) ReactToPost(c *gin.Context) {
+	h.react(c, models.ReactionTargetPost, true)
+}
+
+// UnreactToPost removes the user's :emoji reaction from a post
+func (h *ReactionHandler) UnreactToPost(c *gin.Context) {
+	h.react(c, models.ReactionTargetPost, false)
+}
+
+// ReactToComment adds the user's :emoji reaction to a comment
+func (h *ReactionHandler) ReactToComment(c *gin.Context) {
+	h.react(c, models.ReactionTargetComment, true)
+}
+
+// UnreactToComment removes the user's :emoji reaction from a comment
+func (h *ReactionHandler) UnreactToComment(c *gin.Context) {
+	h.react(c, models.ReactionTargetComment, false)
+}
+
+// react adds or removes a reaction to the :id target. Both are idempotent.
+func (h *ReactionHandler) react(c *gin.Context, target models.ReactionTarget, add bool) {
+	targetID, ok := idFromParam(c, "id", "Invalid "+string(target)+" ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	emoji := c.Param("emoji")
+	service := h.reactionService.WithContext(c.Request.Context())
+
+	var counts map[string]int64
+	var err error
+	if add {
+		counts, err = service.React(userID, target, targetID, emoji)
+	} else {
+		counts, err = service.Unreact(userID, target, targetID, emoji)
+	}
+
+	var denied *authz.DeniedError
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not found",
+		})
+		return
+	case errors.Is(err, services.ErrUnknownReaction):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+		return
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	default:
+		h.logger.WithError(err).Error("Failed to update reaction")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update reaction",
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"target_type": target,
+		"target_id":   targetID,
+		"emoji":       emoji,
+		"added":       add,
+		"user_id":     userID,
+	}).Info("Reaction updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reaction updated successfully",
+		"data": gin.H{
+			"reactions": counts,
+		},
+	})
+}
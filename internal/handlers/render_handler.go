@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/markdown"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RenderHandler handles Markdown rendering requests
+type RenderHandler struct {
+	logger *logger.Logger
+}
+
+// NewRenderHandler creates a new render handler
+func NewRenderHandler(logger *logger.Logger) *RenderHandler {
+	return &RenderHandler{
+		logger: logger,
+	}
+}
+
+// Preview renders Markdown the way post and comment content is rendered,
+// without saving it
+func (h *RenderHandler) Preview(c *gin.Context) {
+	var req models.RenderPreviewRequest
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	contentHTML, err := markdown.Render(req.Content)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to render preview")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to render preview",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"content":      req.Content,
+			"content_html": contentHTML,
+		},
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RoleHandler handles role management requests
+type RoleHandler struct {
+	roleService *services.RoleService
+	logger      *logger.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *services.RoleService, logger *logger.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// ListRoles lists all roles (admin only)
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list roles")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch roles",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": roles,
+	})
+}
+
+// GetRole gets a role by name (admin only)
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetRole(models.Role(c.Param("name")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": role,
+	})
+}
+
+// CreateRole creates a new role (admin only)
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create role")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"role":       role.Name,
+		"created_by": c.GetUint("user_id"),
+	}).Info("Role created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole updates a role (admin only)
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.RoleUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.GetUint("user_id"), models.Role(c.Param("name")), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update role")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"role":       role.Name,
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Role updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole deletes a role (admin only)
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := models.Role(c.Param("name"))
+
+	if err := h.roleService.DeleteRole(c.GetUint("user_id"), name); err != nil {
+		h.logger.WithError(err).Error("Failed to delete role")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"role":       name,
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Role deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"context"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
 	"go-backend/internal/database"
 	"go-backend/internal/middleware"
+	"go-backend/internal/models"
+	"go-backend/internal/search"
 	"go-backend/internal/services"
+	"go-backend/internal/tenant"
 	"go-backend/internal/utils"
 	"go-backend/pkg/logger"
 
@@ -16,40 +23,165 @@ type Router struct {
 	db         *database.Database
 	logger     *logger.Logger
 	jwtService *utils.JWTService
+	config     *config.Config
 
 	// Handlers
-	userHandler   *UserHandler
-	healthHandler *HealthHandler
+	userHandler         *UserHandler
+	healthHandler       *HealthHandler
+	permissionHandler   *PermissionHandler
+	roleHandler         *RoleHandler
+	orgHandler          *OrganizationHandler
+	groupHandler        *GroupHandler
+	sharingHandler      *SharingHandler
+	authzHandler        *AuthzHandler
+	postHandler         *PostHandler
+	commentHandler      *CommentHandler
+	taxonomyHandler     *TaxonomyHandler
+	searchHandler       *SearchHandler
+	reactionHandler     *ReactionHandler
+	renderHandler       *RenderHandler
+	feedHandler         *FeedHandler
+	moderationHandler   *ModerationHandler
+	inboxHandler        *InboxHandler
+	followHandler       *FollowHandler
+	attachmentHandler   *AttachmentHandler
+	trashHandler        *TrashHandler
+	importExportHandler *ImportExportHandler
 
 	// Services
-	userService *services.UserService
+	userService       *services.UserService
+	auditService      *services.AuditService
+	permissionService *services.PermissionService
+	roleService       *services.RoleService
+	orgService        *services.OrganizationService
+	groupService      *services.GroupService
+	sharingService    *services.SharingService
+
+	// Background workers
+	postPublisher *services.PostPublisher
+	viewFlusher   *services.ViewFlusher
+	trashPurger   *services.TrashPurger
 }
 
 // NewRouter creates a new router with all dependencies
-func NewRouter(db *database.Database, logger *logger.Logger, jwtService *utils.JWTService, corsOrigins []string) *Router {
+func NewRouter(db *database.Database, logger *logger.Logger, jwtService *utils.JWTService, cfg *config.Config) *Router {
 	// Initialize Gin in release mode for production
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 
 	// Initialize services
 	userService := services.NewUserService(db.GetDB(), jwtService)
+	auditService := services.NewAuditService(db.GetDB())
+	orgService := services.NewOrganizationService(db.GetDB(), auditService)
+
+	// Role and permission changes apply to every tenant, so they are audited
+	// outside any tenant scope
+	platformAudit := auditService.WithContext(tenant.WithoutScope(context.Background()))
+	permissionService := services.NewPermissionService(db.GetDB(), platformAudit)
+	roleService := services.NewRoleService(db.GetDB(), platformAudit, permissionService)
+	groupService := services.NewGroupService(db.GetDB(), platformAudit, permissionService)
+
+	sharingService := services.NewSharingService(db.GetDB(), auditService, permissionService)
+
+	// Policy checks see the roles users hold through their groups and the
+	// posts and files shared with them
+	authz.SetRoleResolver(permissionService.GroupRoles)
+	authz.SetRelationResolver(sharingService.Relation)
+
+	// Load custom roles and their inheritance from the roles table
+	if err := roleService.LoadHierarchy(); err != nil {
+		logger.WithError(err).Error("Failed to load role hierarchy")
+	}
 
 	// Initialize handlers
 	userHandler := NewUserHandler(userService, logger)
 	healthHandler := NewHealthHandler()
+	permissionHandler := NewPermissionHandler(permissionService, logger)
+	roleHandler := NewRoleHandler(roleService, logger)
+	orgHandler := NewOrganizationHandler(orgService, userService, jwtService, logger)
+	groupHandler := NewGroupHandler(groupService, logger)
+	sharingHandler := NewSharingHandler(sharingService, logger)
+	authzHandler := NewAuthzHandler(services.NewAccessService(db.GetDB()), logger)
+	revisionService := services.NewRevisionService(db.GetDB(), cfg.Revisions)
+	searchIndex, err := search.NewIndex(db.GetDB().Dialector.Name())
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize search index")
+	}
+	notificationService := services.NewNotificationService(db.GetDB(), cfg, logger, auditService)
+	if err := notificationService.Setup(); err != nil {
+		logger.WithError(err).Fatal("Failed to set up notifications")
+	}
+	followService := services.NewFollowService(db.GetDB(), notificationService, cfg.Follows, logger)
+	mentionService := services.NewMentionService(db.GetDB(), notificationService, cfg.App, logger)
+	postService := services.NewPostService(db.GetDB(), auditService, revisionService, searchIndex, followService, mentionService)
+	// Views are buffered in Redis when it is available
+	var cacheService *services.CacheService
+	if cfg.Redis.Enabled {
+		cacheService = services.NewCacheService(services.CacheConfig{
+			Host:      cfg.Redis.Host,
+			Port:      cfg.Redis.Port,
+			Password:  cfg.Redis.Password,
+			DB:        cfg.Redis.DB,
+			KeyPrefix: "app",
+		})
+	} else if cfg.Views.Buffered {
+		logger.Warn("VIEWS_BUFFERED requires REDIS_ENABLED, counting views directly")
+	}
+	viewService := services.NewViewService(db.GetDB(), cacheService, cfg.Views)
+	postHandler := NewPostHandler(postService, revisionService, viewService, logger)
+	fileService := services.NewFileService(db.GetDB(), services.FileUploadConfig{
+		UploadPath:   cfg.File.UploadPath,
+		MaxFileSize:  cfg.File.MaxSize,
+		AllowedTypes: cfg.File.AllowedTypes,
+		StaticURL:    cfg.File.StaticURL,
+	}, auditService)
+	attachmentService := services.NewAttachmentService(db.GetDB(), fileService, auditService, cfg.Attachments)
+	trashService := services.NewTrashService(db.GetDB(), postService, auditService, cfg.Trash)
+	commentHandler := NewCommentHandler(services.NewCommentService(db.GetDB(), auditService, mentionService, cfg.Comments), logger)
+	importService := services.NewImportService(db.GetDB(), postService, fileService, auditService, cfg.Imports, cfg.Comments, cfg.Attachments)
 
 	router := &Router{
-		engine:        engine,
-		db:            db,
-		logger:        logger,
-		jwtService:    jwtService,
-		userHandler:   userHandler,
-		healthHandler: healthHandler,
-		userService:   userService,
+		engine:            engine,
+		db:                db,
+		logger:            logger,
+		jwtService:        jwtService,
+		config:            cfg,
+		userHandler:       userHandler,
+		healthHandler:     healthHandler,
+		permissionHandler: permissionHandler,
+		roleHandler:       roleHandler,
+		orgHandler:        orgHandler,
+		groupHandler:      groupHandler,
+		sharingHandler:    sharingHandler,
+		authzHandler:      authzHandler,
+		postHandler:       postHandler,
+		commentHandler:    commentHandler,
+		taxonomyHandler:   NewTaxonomyHandler(services.NewTaxonomyService(db.GetDB(), auditService), logger),
+		searchHandler:     NewSearchHandler(services.NewSearchService(db.GetDB(), searchIndex), logger),
+		reactionHandler:   NewReactionHandler(services.NewReactionService(db.GetDB(), cfg.Reactions), logger),
+		renderHandler:     NewRenderHandler(logger),
+		feedHandler: NewFeedHandler(services.NewFeedService(db.GetDB(), cfg.App, cfg.Feeds, cfg.Tenancy),
+			cfg.Feeds.MaxAge, cfg.Tenancy, logger),
+		moderationHandler:   NewModerationHandler(services.NewModerationService(db.GetDB(), auditService), logger),
+		inboxHandler:        NewInboxHandler(services.NewInboxService(db.GetDB()), mentionService, logger),
+		followHandler:       NewFollowHandler(followService, logger),
+		attachmentHandler:   NewAttachmentHandler(attachmentService, logger),
+		trashHandler:        NewTrashHandler(trashService, logger),
+		importExportHandler: NewImportExportHandler(services.NewExportService(db.GetDB(), auditService), importService, logger),
+		userService:         userService,
+		auditService:        auditService,
+		permissionService:   permissionService,
+		roleService:         roleService,
+		orgService:          orgService,
+		groupService:        groupService,
+		sharingService:      sharingService,
+		postPublisher:       services.NewPostPublisher(postService, cfg.Publisher.Interval, logger),
+		viewFlusher:         services.NewViewFlusher(viewService, cfg.Views.FlushInterval, logger),
+		trashPurger:         services.NewTrashPurger(trashService, cfg.Trash.PurgeInterval, logger),
 	}
 
 	// Setup middleware
-	router.setupMiddleware(corsOrigins)
+	router.setupMiddleware(cfg.CORS.Origins)
 
 	// Setup routes
 	router.setupRoutes()
@@ -67,6 +199,7 @@ func (r *Router) setupMiddleware(corsOrigins []string) {
 	r.engine.Use(middleware.LoggerMiddleware(r.logger))
 	r.engine.Use(middleware.CORSMiddleware(corsOrigins))
 	r.engine.Use(middleware.SecurityHeadersMiddleware())
+	r.engine.Use(middleware.IfMatchMiddleware())
 	r.engine.Use(middleware.RateLimitMiddleware())
 }
 
@@ -76,6 +209,18 @@ func (r *Router) setupRoutes() {
 	r.engine.GET("/health", r.healthHandler.HealthCheck)
 	r.engine.GET("/ready", r.healthHandler.ReadinessCheck)
 
+	// Syndication feeds and sitemaps of published posts (no auth required)
+	feeds := r.engine.Group("",
+		middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+	)
+	{
+		feeds.GET("/feeds/:feed", r.feedHandler.PostsFeed)
+		feeds.GET("/feeds/authors/:username/:feed", r.feedHandler.AuthorFeed)
+		feeds.GET("/feeds/tags/:slug/:feed", r.feedHandler.TagFeed)
+		feeds.GET("/sitemap.xml", r.feedHandler.SitemapIndex)
+		feeds.GET("/sitemaps/:file", r.feedHandler.Sitemap)
+	}
+
 	// API v1 routes
 	v1 := r.engine.Group("/api/v1")
 	{
@@ -86,8 +231,64 @@ func (r *Router) setupRoutes() {
 			auth.POST("/login", r.userHandler.Login)
 		}
 
+		// Public share link resolution
+		v1.GET("/shared/:token",
+			middleware.OptionalAuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+			r.sharingHandler.ResolveLink,
+		)
+
+		// Public post routes (anonymous or authenticated)
+		publicPosts := v1.Group("/posts",
+			middleware.OptionalAuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+		)
+		{
+			publicPosts.GET("", r.postHandler.ListPosts)
+			publicPosts.GET("/search", r.postHandler.SearchPosts)
+			publicPosts.GET("/by-slug/:slug", r.postHandler.GetPostBySlug)
+			publicPosts.GET("/:id", r.postHandler.GetPost)
+			publicPosts.GET("/:id/comments", r.commentHandler.ListPostComments)
+			publicPosts.GET("/:id/attachments", r.attachmentHandler.ListPostAttachments)
+		}
+
+		// Public attachment routes, visible to whoever can see the post
+		attachments := v1.Group("",
+			middleware.OptionalAuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+		)
+		{
+			attachments.GET("/comments/:id/attachments", r.attachmentHandler.ListCommentAttachments)
+			attachments.GET("/attachments/:id/download", r.attachmentHandler.DownloadAttachment)
+		}
+
+		// Public tag and category routes
+		taxonomy := v1.Group("",
+			middleware.OptionalAuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+		)
+		{
+			taxonomy.GET("/tags", r.taxonomyHandler.ListTags)
+			taxonomy.GET("/tags/:slug/posts", r.postHandler.ListPostsByTag)
+			taxonomy.GET("/categories", r.taxonomyHandler.GetCategoryTree)
+			taxonomy.GET("/categories/:slug/posts", r.postHandler.ListPostsByCategory)
+		}
+
+		// Emoji available for reactions
+		v1.GET("/reactions", r.reactionHandler.ListEmoji)
+
+		// Global search, filtered by what the caller may read
+		v1.GET("/search",
+			middleware.OptionalAuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+			r.searchHandler.Search,
+		)
+
 		// Protected routes (require authentication)
-		protected := v1.Group("", middleware.AuthMiddleware(r.jwtService))
+		protected := v1.Group("",
+			middleware.AuthMiddleware(r.jwtService, r.userService, r.permissionService),
+			middleware.TenantMiddleware(r.orgService, r.config.Tenancy),
+		)
 		{
 			// User profile routes (authenticated users)
 			user := protected.Group("/user")
@@ -95,6 +296,113 @@ func (r *Router) setupRoutes() {
 				user.GET("/profile", r.userHandler.GetProfile)
 				user.PUT("/profile", r.userHandler.UpdateUser) // Will need to extract ID from token
 				user.POST("/change-password", r.userHandler.ChangePassword)
+				user.GET("/groups", r.groupHandler.GetMyGroups)
+				user.GET("/shared-with-me", r.sharingHandler.SharedWithMe)
+				user.GET("/permissions", r.authzHandler.GetMyPermissions)
+				user.GET("/notifications", r.inboxHandler.ListNotifications)
+				user.POST("/notifications/read-all", r.inboxHandler.MarkAllNotificationsRead)
+				user.POST("/notifications/:id/read", r.inboxHandler.MarkNotificationRead)
+				user.GET("/mentions", r.inboxHandler.ListMentions)
+				user.GET("/blocks", r.followHandler.GetBlocks)
+			}
+
+			// Home feed of posts by followed authors
+			protected.GET("/feed", r.followHandler.GetFeed)
+
+			// Reporting posts, comments and users to moderators
+			protected.POST("/reports", r.moderationHandler.CreateReport)
+
+			// Markdown preview for editors
+			protected.POST("/render/preview", r.renderHandler.Preview)
+
+			// Authorization checks for the current user
+			protected.POST("/authz/check", r.authzHandler.CheckAccess)
+
+			// Organization (tenant) and membership routes
+			orgs := protected.Group("/orgs")
+			{
+				orgs.GET("", r.orgHandler.ListOrganizations)
+				orgs.POST("", r.orgHandler.CreateOrganization)
+				orgs.GET("/:id", r.orgHandler.GetOrganization)
+				orgs.PUT("/:id", r.orgHandler.UpdateOrganization)
+				orgs.POST("/:id/token", r.orgHandler.IssueToken)
+				orgs.GET("/:id/members", r.orgHandler.ListMembers)
+				orgs.POST("/:id/members", r.orgHandler.AddMember)
+				orgs.PUT("/:id/members/:user_id", r.orgHandler.UpdateMember)
+				orgs.DELETE("/:id/members/:user_id", r.orgHandler.RemoveMember)
+			}
+
+			// Post authoring routes
+			posts := protected.Group("/posts")
+			{
+				posts.POST("", middleware.RequirePermission(models.PermPostCreate), r.postHandler.CreatePost)
+				posts.PUT("/:id", r.postHandler.UpdatePost)
+				posts.POST("/:id/status", r.postHandler.ChangeStatus)
+				posts.PUT("/:id/tags", r.taxonomyHandler.SetPostTags)
+				posts.PUT("/:id/categories", r.taxonomyHandler.SetPostCategories)
+				posts.PUT("/:id/reactions/:emoji", r.reactionHandler.ReactToPost)
+				posts.DELETE("/:id/reactions/:emoji", r.reactionHandler.UnreactToPost)
+				posts.POST("/:id/attachments", r.attachmentHandler.AttachToPost)
+				posts.PUT("/:id/attachments/order", r.attachmentHandler.ReorderPostAttachments)
+				posts.DELETE("/:id", r.postHandler.DeletePost)
+				posts.POST("/bulk-delete", middleware.RequirePermission(models.PermPostBulkDelete), r.postHandler.BulkDeletePosts)
+
+				// Markdown bundles of posts, and imports of them and of
+				// WordPress exports
+				posts.GET("/export", r.importExportHandler.ExportPosts)
+				posts.POST("/import", middleware.RequirePermission(models.PermPostCreate), r.importExportHandler.ImportPosts)
+
+				// Revision history (users who can edit the post)
+				posts.GET("/:id/revisions", r.postHandler.ListRevisions)
+				posts.GET("/:id/revisions/diff", r.postHandler.DiffRevisions)
+				posts.GET("/:id/revisions/:number", r.postHandler.GetRevision)
+				posts.POST("/:id/revisions/:number/restore", r.postHandler.RestoreRevision)
+			}
+
+			// Comment routes
+			comments := protected.Group("/comments")
+			{
+				comments.POST("", middleware.RequirePermission(models.PermCommentCreate), r.commentHandler.CreateComment)
+				comments.PUT("/:id", r.commentHandler.UpdateComment)
+				comments.DELETE("/:id", r.commentHandler.DeleteComment)
+				comments.PUT("/:id/reactions/:emoji", r.reactionHandler.ReactToComment)
+				comments.DELETE("/:id/reactions/:emoji", r.reactionHandler.UnreactToComment)
+				comments.POST("/:id/attachments", r.attachmentHandler.AttachToComment)
+				comments.PUT("/:id/attachments/order", r.attachmentHandler.ReorderCommentAttachments)
+			}
+
+			// Captions, positions and removal of attachments
+			protectedAttachments := protected.Group("/attachments")
+			{
+				protectedAttachments.PUT("/:id", r.attachmentHandler.UpdateAttachment)
+				protectedAttachments.DELETE("/:id", r.attachmentHandler.DetachAttachment)
+			}
+
+			// Restoring and purging deleted posts, comments and files
+			trash := protected.Group("/trash")
+			{
+				trash.GET("/posts", r.trashHandler.ListPosts)
+				trash.POST("/posts/:id/restore", r.trashHandler.RestorePost)
+				trash.DELETE("/posts/:id", r.trashHandler.PurgePost)
+				trash.GET("/comments", r.trashHandler.ListComments)
+				trash.POST("/comments/:id/restore", r.trashHandler.RestoreComment)
+				trash.DELETE("/comments/:id", r.trashHandler.PurgeComment)
+				trash.GET("/files", r.trashHandler.ListFiles)
+				trash.POST("/files/:id/restore", r.trashHandler.RestoreFile)
+				trash.DELETE("/files/:id", r.trashHandler.PurgeFile)
+			}
+
+			// Sharing posts and files with users, groups and links
+			shares := protected.Group("/shares")
+			{
+				shares.GET("", r.sharingHandler.ListShares)
+				shares.POST("", r.sharingHandler.CreateShare)
+				shares.DELETE("/:id", r.sharingHandler.RevokeShare)
+			}
+			shareLinks := protected.Group("/share-links")
+			{
+				shareLinks.POST("", r.sharingHandler.CreateLink)
+				shareLinks.DELETE("/:id", r.sharingHandler.RevokeLink)
 			}
 
 			// Admin routes
@@ -103,10 +411,57 @@ func (r *Router) setupRoutes() {
 				// User management (admin only)
 				users := admin.Group("/users")
 				{
-					users.GET("", r.userHandler.GetUsers)
-					users.GET("/:id", r.userHandler.GetUser)
-					users.PUT("/:id", r.userHandler.UpdateUser)
-					users.DELETE("/:id", r.userHandler.DeleteUser)
+					users.GET("", middleware.RequirePermission(models.PermUserRead), r.userHandler.GetUsers)
+					users.GET("/:id", middleware.RequirePermission(models.PermUserRead), r.userHandler.GetUser)
+					users.PUT("/:id", middleware.RequirePermission(models.PermUserUpdate), r.userHandler.UpdateUser)
+					users.DELETE("/:id", middleware.RequirePermission(models.PermUserDelete), r.userHandler.DeleteUser)
+				}
+
+				// Deleted users
+				trashedUsers := admin.Group("/trash/users")
+				{
+					trashedUsers.GET("", middleware.RequirePermission(models.PermUserRead), r.trashHandler.ListUsers)
+					trashedUsers.POST("/:id/restore", middleware.RequirePermission(models.PermUserUpdate), r.trashHandler.RestoreUser)
+					trashedUsers.DELETE("/:id", middleware.RequirePermission(models.PermUserDelete), r.trashHandler.PurgeUser)
+				}
+
+				// Permission and role grant management
+				permissions := admin.Group("/permissions", middleware.RequirePermission(models.PermPermissionManage))
+				{
+					permissions.GET("", r.permissionHandler.ListPermissions)
+					permissions.POST("", r.permissionHandler.CreatePermission)
+					permissions.DELETE("/:id", r.permissionHandler.DeletePermission)
+				}
+
+				// Role management and role grants
+				roles := admin.Group("/roles")
+				{
+					roles.GET("", middleware.RequirePermission(models.PermRoleManage), r.roleHandler.ListRoles)
+					roles.POST("", middleware.RequirePermission(models.PermRoleManage), r.roleHandler.CreateRole)
+					roles.GET("/:name", middleware.RequirePermission(models.PermRoleManage), r.roleHandler.GetRole)
+					roles.PUT("/:name", middleware.RequirePermission(models.PermRoleManage), r.roleHandler.UpdateRole)
+					roles.DELETE("/:name", middleware.RequirePermission(models.PermRoleManage), r.roleHandler.DeleteRole)
+
+					roles.GET("/:name/permissions", middleware.RequirePermission(models.PermPermissionManage), r.permissionHandler.GetRolePermissions)
+					roles.POST("/:name/permissions", middleware.RequirePermission(models.PermPermissionManage), r.permissionHandler.GrantRolePermission)
+					roles.DELETE("/:name/permissions/:permission", middleware.RequirePermission(models.PermPermissionManage), r.permissionHandler.RevokeRolePermission)
+				}
+
+				// Groups, group membership and group grants
+				groups := admin.Group("/groups", middleware.RequirePermission(models.PermGroupManage))
+				{
+					groups.GET("", r.groupHandler.ListGroups)
+					groups.POST("", r.groupHandler.CreateGroup)
+					groups.GET("/:id", r.groupHandler.GetGroup)
+					groups.PUT("/:id", r.groupHandler.UpdateGroup)
+					groups.DELETE("/:id", r.groupHandler.DeleteGroup)
+					groups.GET("/:id/members", r.groupHandler.ListMembers)
+					groups.POST("/:id/members", r.groupHandler.AddMember)
+					groups.DELETE("/:id/members/:user_id", r.groupHandler.RemoveMember)
+					groups.POST("/:id/roles", r.groupHandler.GrantRole)
+					groups.DELETE("/:id/roles/:role", r.groupHandler.RevokeRole)
+					groups.POST("/:id/permissions", middleware.RequirePermission(models.PermPermissionManage), r.groupHandler.GrantPermission)
+					groups.DELETE("/:id/permissions/:permission", middleware.RequirePermission(models.PermPermissionManage), r.groupHandler.RevokePermission)
 				}
 			}
 
@@ -114,13 +469,59 @@ func (r *Router) setupRoutes() {
 			mod := protected.Group("/mod", middleware.RequireModerator())
 			{
 				// Add moderator-specific routes here
-				mod.GET("/users", r.userHandler.GetUsers) // Moderators can view users
+				mod.GET("/users", middleware.RequirePermission(models.PermUserRead), r.userHandler.GetUsers) // Moderators can view users
+
+				// Comment moderation queue
+				modComments := mod.Group("/comments", middleware.RequirePermission(models.PermCommentModerate))
+				{
+					modComments.GET("", r.commentHandler.GetModerationQueue)
+					modComments.POST("/bulk", r.commentHandler.BulkModerateComments)
+					modComments.POST("/:id/approve", r.commentHandler.ApproveComment)
+					modComments.POST("/:id/reject", r.commentHandler.RejectComment)
+					modComments.POST("/:id/spam", r.commentHandler.MarkCommentSpam)
+				}
+
+				// Tag and category management
+				modTags := mod.Group("/tags", middleware.RequirePermission(models.PermTaxonomyManage))
+				{
+					modTags.POST("", r.taxonomyHandler.CreateTag)
+					modTags.POST("/merge", r.taxonomyHandler.MergeTags)
+					modTags.PUT("/:id", r.taxonomyHandler.RenameTag)
+					modTags.DELETE("/:id", r.taxonomyHandler.DeleteTag)
+				}
+				modCategories := mod.Group("/categories", middleware.RequirePermission(models.PermTaxonomyManage))
+				{
+					modCategories.POST("", r.taxonomyHandler.CreateCategory)
+					modCategories.PUT("/:id", r.taxonomyHandler.UpdateCategory)
+					modCategories.DELETE("/:id", r.taxonomyHandler.DeleteCategory)
+				}
+
+				// Report queue and moderator actions
+				modReports := mod.Group("/reports", middleware.RequirePermission(models.PermReportModerate))
+				{
+					modReports.GET("", r.moderationHandler.ListReports)
+					modReports.GET("/:id", r.moderationHandler.GetReport)
+					modReports.PUT("/:id", r.moderationHandler.UpdateReport)
+					modReports.POST("/:id/actions", r.moderationHandler.ActOnReport)
+				}
+				mod.POST("/actions", middleware.RequirePermission(models.PermReportModerate), r.moderationHandler.TakeAction)
 			}
 
 			// Owner or admin routes (for user-specific resources)
 			users := protected.Group("/users")
 			{
 				users.PUT("/:id", middleware.RequireOwnerOrAdmin(r.userHandler.GetUserIDFromParam), r.userHandler.UpdateUser)
+
+				// Following users
+				users.POST("/:id/follow", r.followHandler.Follow)
+				users.DELETE("/:id/follow", r.followHandler.Unfollow)
+				users.GET("/:id/followers", r.followHandler.GetFollowers)
+				users.GET("/:id/following", r.followHandler.GetFollowing)
+				users.GET("/:id/follow-stats", r.followHandler.GetFollowStats)
+
+				// Blocking users
+				users.POST("/:id/block", r.followHandler.Block)
+				users.DELETE("/:id/block", r.followHandler.Unblock)
 			}
 		}
 	}
@@ -133,6 +534,13 @@ func (r *Router) setupRoutes() {
 	})
 }
 
+// StartWorkers starts the background workers, which stop when ctx is done
+func (r *Router) StartWorkers(ctx context.Context) {
+	go r.postPublisher.Run(ctx)
+	go r.viewFlusher.Run(ctx)
+	go r.trashPurger.Run(ctx)
+}
+
 // GetEngine returns the Gin engine
 func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
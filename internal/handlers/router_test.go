@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/database"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestRouter returns a router over a fresh SQLite database. env sets
+// configuration variables on top of the defaults.
+func newTestRouter(t *testing.T, env map[string]string) (*Router, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	t.Setenv("SQLITE_PATH", filepath.Join(dir, "test.db"))
+	t.Setenv("UPLOAD_PATH", filepath.Join(dir, "uploads"))
+	t.Setenv("NODE_ENV", "test")
+	t.Setenv("LOG_LEVEL", "error")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	db, err := database.NewDatabase(cfg)
+	require.NoError(t, err)
+	db.DB.Logger = gormlogger.Default.LogMode(gormlogger.Silent)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Migrate())
+
+	log := logger.NewLogger(cfg.Logging.Level, cfg.Logging.Format)
+	return NewRouter(db, log, utils.NewJWTService(cfg), cfg), db.DB
+}
+
+// serve sends a request through the router
+func serve(r *Router, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.engine.ServeHTTP(w, req)
+	return w
+}
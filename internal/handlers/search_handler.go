@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/search"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles global search requests
+type SearchHandler struct {
+	searchService *services.SearchService
+	logger        *logger.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService *services.SearchService, logger *logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// Search searches users, posts, comments and files the caller may read. The
+// optional ?type= takes a comma-separated list of types to return.
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Search query is required",
+		})
+		return
+	}
+
+	var types []string
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	results, err := h.searchService.WithContext(c.Request.Context()).
+		Search(c.GetUint("user_id"), query, types, services.PaginationOptions{Page: page, PageSize: limit})
+	if errors.Is(err, search.ErrEmptyQuery) || errors.Is(err, search.ErrTooManyTerms) ||
+		errors.Is(err, services.ErrUnknownSearchType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to search")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"hits":   results.Data,
+			"facets": results.Facets,
+			"pagination": gin.H{
+				"page":  results.Page,
+				"limit": results.PageSize,
+				"total": results.Total,
+				"pages": results.TotalPages,
+			},
+		},
+	})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SharingHandler handles resource sharing and share link requests
+type SharingHandler struct {
+	sharingService *services.SharingService
+	logger         *logger.Logger
+}
+
+// NewSharingHandler creates a new sharing handler
+func NewSharingHandler(sharingService *services.SharingService, logger *logger.Logger) *SharingHandler {
+	return &SharingHandler{
+		sharingService: sharingService,
+		logger:         logger,
+	}
+}
+
+// CreateShare shares a post or file with a user or group
+func (h *SharingHandler) CreateShare(c *gin.Context) {
+	var req models.ShareCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	share, err := h.service(c).ShareResource(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to share resource")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"resource_type": share.ResourceType,
+		"resource_id":   share.ResourceID,
+		"grantee_type":  share.GranteeType,
+		"grantee_id":    share.GranteeID,
+		"permission":    share.Permission,
+		"shared_by":     c.GetUint("user_id"),
+	}).Info("Resource shared successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Resource shared successfully",
+		"data":    share,
+	})
+}
+
+// ListShares lists the shares and links of a resource
+func (h *SharingHandler) ListShares(c *gin.Context) {
+	resourceType := c.Query("resource_type")
+	resourceID, err := strconv.ParseUint(c.Query("resource_id"), 10, 32)
+	if resourceType == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "resource_type and resource_id are required",
+		})
+		return
+	}
+
+	shares, links, err := h.service(c).ListShares(c.GetUint("user_id"), resourceType, uint(resourceID))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"shares": shares,
+			"links":  links,
+		},
+	})
+}
+
+// RevokeShare removes a share
+func (h *SharingHandler) RevokeShare(c *gin.Context) {
+	shareID, ok := idFromParam(c, "id", "Invalid share ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).RevokeShare(c.GetUint("user_id"), shareID); err != nil {
+		h.logger.WithError(err).Error("Failed to revoke share")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"share_id":   shareID,
+		"revoked_by": c.GetUint("user_id"),
+	}).Info("Share revoked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Share revoked successfully",
+	})
+}
+
+// CreateLink creates a share link for a post or file
+func (h *SharingHandler) CreateLink(c *gin.Context) {
+	var req models.ShareLinkCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	link, err := h.service(c).CreateLink(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create share link")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"link_id":       link.ID,
+		"resource_type": link.ResourceType,
+		"resource_id":   link.ResourceID,
+		"created_by":    c.GetUint("user_id"),
+	}).Info("Share link created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Share link created successfully",
+		"data":    link,
+	})
+}
+
+// RevokeLink deletes a share link
+func (h *SharingHandler) RevokeLink(c *gin.Context) {
+	linkID, ok := idFromParam(c, "id", "Invalid share link ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).RevokeLink(c.GetUint("user_id"), linkID); err != nil {
+		h.logger.WithError(err).Error("Failed to revoke share link")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"link_id":    linkID,
+		"revoked_by": c.GetUint("user_id"),
+	}).Info("Share link revoked successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Share link revoked successfully",
+	})
+}
+
+// ResolveLink returns the resource behind a share link (no auth required)
+func (h *SharingHandler) ResolveLink(c *gin.Context) {
+	item, err := h.service(c).ResolveLink(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": item,
+	})
+}
+
+// SharedWithMe lists the posts and files shared with the current user
+func (h *SharingHandler) SharedWithMe(c *gin.Context) {
+	items, err := h.service(c).SharedWithUser(c.GetUint("user_id"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list shared resources")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch shared resources",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+	})
+}
+
+// service returns the sharing service scoped to the request context
+func (h *SharingHandler) service(c *gin.Context) *services.SharingService {
+	return h.sharingService.WithContext(c.Request.Context())
+}
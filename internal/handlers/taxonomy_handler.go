@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TaxonomyHandler handles tag and category requests
+type TaxonomyHandler struct {
+	taxonomyService *services.TaxonomyService
+	logger          *logger.Logger
+}
+
+// NewTaxonomyHandler creates a new taxonomy handler
+func NewTaxonomyHandler(taxonomyService *services.TaxonomyService, logger *logger.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		taxonomyService: taxonomyService,
+		logger:          logger,
+	}
+}
+
+// ListTags lists all tags with their published post counts
+func (h *TaxonomyHandler) ListTags(c *gin.Context) {
+	tags, err := h.service(c).ListTags()
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list tags")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tags,
+	})
+}
+
+// CreateTag creates a new tag (moderators)
+func (h *TaxonomyHandler) CreateTag(c *gin.Context) {
+	var req models.TagCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	tag, err := h.service(c).CreateTag(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to create tag")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"tag_id":     tag.ID,
+		"slug":       tag.Slug,
+		"created_by": c.GetUint("user_id"),
+	}).Info("Tag created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tag created successfully",
+		"data":    tag,
+	})
+}
+
+// RenameTag renames a tag (moderators)
+func (h *TaxonomyHandler) RenameTag(c *gin.Context) {
+	tagID, ok := idFromParam(c, "id", "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req models.TagUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	tag, err := h.service(c).RenameTag(c.GetUint("user_id"), tagID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to rename tag")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"tag_id":     tag.ID,
+		"slug":       tag.Slug,
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Tag renamed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag renamed successfully",
+		"data":    tag,
+	})
+}
+
+// DeleteTag deletes a tag and removes it from all posts (moderators)
+func (h *TaxonomyHandler) DeleteTag(c *gin.Context) {
+	tagID, ok := idFromParam(c, "id", "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).DeleteTag(c.GetUint("user_id"), tagID); err != nil {
+		h.respondWithError(c, err, "Failed to delete tag")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"tag_id":     tagID,
+		"deleted_by": c.GetUint("user_id"),
+	}).Info("Tag deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag deleted successfully",
+	})
+}
+
+// MergeTags merges tags into a target tag (moderators)
+func (h *TaxonomyHandler) MergeTags(c *gin.Context) {
+	var req models.TagMergeRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	tag, err := h.service(c).MergeTags(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to merge tags")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"tag_id":    tag.ID,
+		"merged":    req.SourceIDs,
+		"merged_by": c.GetUint("user_id"),
+	}).Info("Tags merged successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tags merged successfully",
+		"data":    tag,
+	})
+}
+
+// SetPostTags replaces the tags of a post
+func (h *TaxonomyHandler) SetPostTags(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	var req models.PostTagsRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	tags, err := h.service(c).SetPostTags(postID, c.GetUint("user_id"), req.Tags)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to set post tags")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":    postID,
+		"tags":       len(tags),
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Post tags updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post tags updated successfully",
+		"data":    tags,
+	})
+}
+
+// GetCategoryTree lists all categories as a tree
+func (h *TaxonomyHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.service(c).GetCategoryTree()
+	if errors.Is(err, tenant.ErrNoTenant) {
+		respondWithNoTenant(c)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list categories")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch categories",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tree,
+	})
+}
+
+// CreateCategory creates a new category (moderators)
+func (h *TaxonomyHandler) CreateCategory(c *gin.Context) {
+	var req models.CategoryCreateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	category, err := h.service(c).CreateCategory(c.GetUint("user_id"), &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to create category")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"category_id": category.ID,
+		"slug":        category.Slug,
+		"created_by":  c.GetUint("user_id"),
+	}).Info("Category created successfully")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Category created successfully",
+		"data":    category,
+	})
+}
+
+// UpdateCategory updates or moves a category (moderators)
+func (h *TaxonomyHandler) UpdateCategory(c *gin.Context) {
+	categoryID, ok := idFromParam(c, "id", "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	var req models.CategoryUpdateRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	category, err := h.service(c).UpdateCategory(c.GetUint("user_id"), categoryID, &req)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to update category")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"category_id": category.ID,
+		"updated_by":  c.GetUint("user_id"),
+	}).Info("Category updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category updated successfully",
+		"data":    category,
+	})
+}
+
+// DeleteCategory deletes a category, moving its children up a level
+// (moderators)
+func (h *TaxonomyHandler) DeleteCategory(c *gin.Context) {
+	categoryID, ok := idFromParam(c, "id", "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service(c).DeleteCategory(c.GetUint("user_id"), categoryID); err != nil {
+		h.respondWithError(c, err, "Failed to delete category")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"category_id": categoryID,
+		"deleted_by":  c.GetUint("user_id"),
+	}).Info("Category deleted successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category deleted successfully",
+	})
+}
+
+// SetPostCategories replaces the categories of a post
+func (h *TaxonomyHandler) SetPostCategories(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	var req models.PostCategoriesRequest
+
+	// Bind and validate request
+	if errors := utils.BindAndValidate(c, &req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Validation failed",
+			"errors": errors,
+		})
+		return
+	}
+
+	categories, err := h.service(c).SetPostCategories(postID, c.GetUint("user_id"), req.CategoryIDs)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to set post categories")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id":    postID,
+		"categories": len(categories),
+		"updated_by": c.GetUint("user_id"),
+	}).Info("Post categories updated successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post categories updated successfully",
+		"data":    categories,
+	})
+}
+
+// service returns the taxonomy service scoped to the request context
+func (h *TaxonomyHandler) service(c *gin.Context) *services.TaxonomyService {
+	return h.taxonomyService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps taxonomy service errors to responses. Validation
+// errors from the service, such as duplicate names, are client errors.
+func (h *TaxonomyHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Post not found",
+		})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TrashHandler handles the trash of deleted posts, comments, files and users
+type TrashHandler struct {
+	trashService *services.TrashService
+	logger       *logger.Logger
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *services.TrashService, logger *logger.Logger) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+		logger:       logger,
+	}
+}
+
+// ListPosts lists the deleted posts the user can restore
+func (h *TrashHandler) ListPosts(c *gin.Context) {
+	result, err := h.service(c).Posts(c.GetUint("user_id"), h.queryOptions(c))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch deleted posts")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": trashPage("posts", result)})
+}
+
+// ListComments lists the deleted comments the user can restore
+func (h *TrashHandler) ListComments(c *gin.Context) {
+	result, err := h.service(c).Comments(c.GetUint("user_id"), h.queryOptions(c))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch deleted comments")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": trashPage("comments", result)})
+}
+
+// ListFiles lists the deleted files the user can restore
+func (h *TrashHandler) ListFiles(c *gin.Context) {
+	result, err := h.service(c).Files(c.GetUint("user_id"), h.queryOptions(c))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch deleted files")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": trashPage("files", result)})
+}
+
+// ListUsers lists the deleted users (admin only)
+func (h *TrashHandler) ListUsers(c *gin.Context) {
+	result, err := h.service(c).Users(c.GetUint("user_id"), h.queryOptions(c))
+	if err != nil {
+		h.respondWithError(c, err, "Failed to fetch deleted users")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": trashPage("users", result)})
+}
+
+// RestorePost takes a post out of the trash
+func (h *TrashHandler) RestorePost(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	post, err := h.service(c).RestorePost(userID, postID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to restore post")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id": postID,
+		"user_id": userID,
+	}).Info("Post restored successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post restored successfully",
+		"data":    post,
+	})
+}
+
+// RestoreComment takes a comment and the replies deleted with it out of the
+// trash
+func (h *TrashHandler) RestoreComment(c *gin.Context) {
+	commentID, ok := idFromParam(c, "id", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	comment, err := h.service(c).RestoreComment(userID, commentID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to restore comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id": commentID,
+		"user_id":    userID,
+	}).Info("Comment restored successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment restored successfully",
+		"data":    comment,
+	})
+}
+
+// RestoreFile takes a file out of the trash
+func (h *TrashHandler) RestoreFile(c *gin.Context) {
+	fileID, ok := idFromParam(c, "id", "Invalid file ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	file, err := h.service(c).RestoreFile(userID, fileID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to restore file")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"file_id": fileID,
+		"user_id": userID,
+	}).Info("File restored successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "File restored successfully",
+		"data":    file,
+	})
+}
+
+// RestoreUser takes a user out of the trash (admin only)
+func (h *TrashHandler) RestoreUser(c *gin.Context) {
+	userID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	user, err := h.service(c).RestoreUser(adminID, userID)
+	if err != nil {
+		h.respondWithError(c, err, "Failed to restore user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"admin_id": adminID,
+	}).Info("User restored successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User restored successfully",
+		"data":    user.ToResponse(),
+	})
+}
+
+// PurgePost permanently deletes a post in the trash
+func (h *TrashHandler) PurgePost(c *gin.Context) {
+	postID, ok := idFromParam(c, "id", "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).PurgePost(userID, postID); err != nil {
+		h.respondWithError(c, err, "Failed to purge post")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"post_id": postID,
+		"user_id": userID,
+	}).Info("Post purged successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post purged successfully",
+	})
+}
+
+// PurgeComment permanently deletes a comment in the trash and its replies
+func (h *TrashHandler) PurgeComment(c *gin.Context) {
+	commentID, ok := idFromParam(c, "id", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).PurgeComment(userID, commentID); err != nil {
+		h.respondWithError(c, err, "Failed to purge comment")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"comment_id": commentID,
+		"user_id":    userID,
+	}).Info("Comment purged successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment purged successfully",
+	})
+}
+
+// PurgeFile permanently deletes a file in the trash and removes it from disk
+func (h *TrashHandler) PurgeFile(c *gin.Context) {
+	fileID, ok := idFromParam(c, "id", "Invalid file ID")
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service(c).PurgeFile(userID, fileID); err != nil {
+		h.respondWithError(c, err, "Failed to purge file")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"file_id": fileID,
+		"user_id": userID,
+	}).Info("File purged successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "File purged successfully",
+	})
+}
+
+// PurgeUser permanently deletes a user in the trash with their content
+// (admin only)
+func (h *TrashHandler) PurgeUser(c *gin.Context) {
+	userID, ok := idFromParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	if err := h.service(c).PurgeUser(adminID, userID); err != nil {
+		h.respondWithError(c, err, "Failed to purge user")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"admin_id": adminID,
+	}).Info("User purged successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User purged successfully",
+	})
+}
+
+// trashPage formats a page of trashed items under key with its pagination
+func trashPage[T any](key string, result *services.PaginatedResult[T]) gin.H {
+	return gin.H{
+		key: result.Data,
+		"pagination": gin.H{
+			"page":  result.Page,
+			"limit": result.PageSize,
+			"total": result.Total,
+			"pages": result.TotalPages,
+		},
+	}
+}
+
+// queryOptions reads the page and limit query parameters
+func (h *TrashHandler) queryOptions(c *gin.Context) services.QueryOptions {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return services.QueryOptions{
+		Pagination: services.PaginationOptions{Page: page, PageSize: limit},
+	}
+}
+
+// service returns the trash service scoped to the request context
+func (h *TrashHandler) service(c *gin.Context) *services.TrashService {
+	return h.trashService.WithContext(c.Request.Context())
+}
+
+// respondWithError maps trash service errors to responses
+func (h *TrashHandler) respondWithError(c *gin.Context, err error, message string) {
+	var denied *authz.DeniedError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Not found in trash",
+		})
+	case errors.Is(err, services.ErrParentInTrash), errors.Is(err, services.ErrRestoreConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, tenant.ErrNoTenant):
+		respondWithNoTenant(c)
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"go-backend/internal/etag"
 	"go-backend/internal/models"
 	"go-backend/internal/services"
 	"go-backend/internal/utils"
@@ -144,6 +146,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": user.ToResponse(),
 	})
@@ -169,6 +172,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"data": user.ToResponse(),
 	})
@@ -238,7 +242,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	// Update user
-	user, err := h.userService.UpdateUser(uint(id), &req)
+	user, err := h.userService.WithContext(c.Request.Context()).UpdateUser(uint(id), &req)
+	if errors.Is(err, services.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update user")
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -252,6 +262,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		"updated_by": c.GetUint("user_id"),
 	}).Info("User updated successfully")
 
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
 		"data":    user.ToResponse(),
@@ -278,7 +289,13 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.DeleteUser(uint(id))
+	err = h.userService.WithContext(c.Request.Context()).DeleteUser(uint(id))
+	if errors.Is(err, services.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete user")
 		c.JSON(http.StatusNotFound, gin.H{
@@ -0,0 +1,126 @@
+// Package markdown renders the Markdown of posts and comments to HTML that
+// is safe to embed in a page.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Version identifies the rendering rules. Bump it whenever they change so
+// that stored HTML rendered by older rules is rendered again.
+const Version = 1
+
+var (
+	// Raw HTML is passed through by the renderer and left to the sanitizer
+	renderer = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(headingAnchors{}, 1000)),
+		),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	sanitizer = newPolicy()
+
+	// A mention is an @ that does not continue a word or an email address
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.-]*)`)
+)
+
+// newPolicy returns the allow-list of elements and attributes rendered HTML
+// may contain. It extends the user generated content policy with the code
+// language classes used by syntax highlighters, heading anchors and task
+// list checkboxes.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\w-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^anchor$`)).OnElements("a")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render renders Markdown to sanitized HTML
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return sanitizer.SanitizeReader(&buf).String(), nil
+}
+
+// Mentions returns the usernames @mentioned in Markdown, in order of first
+// mention. Mentions in code and links are not counted.
+func Mentions(source string) []string {
+	src := []byte(source)
+	doc := renderer.Parser().Parse(text.NewReader(src))
+
+	// Collect the text outside code and links, keeping separate runs of text
+	// apart so a mention cannot span them
+	var prose strings.Builder
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Text:
+			prose.Write(node.Segment.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				prose.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		case *ast.CodeSpan, *ast.Link, *ast.AutoLink, *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			prose.WriteByte(' ')
+			return ast.WalkSkipChildren, nil
+		}
+		prose.WriteByte(' ')
+		return ast.WalkContinue, nil
+	})
+
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(prose.String(), -1) {
+		// Trailing punctuation ends the sentence, not the username
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// headingAnchors adds a link to itself at the start of every heading, so
+// readers can link to a section
+type headingAnchors struct{}
+
+// Transform implements parser.ASTTransformer
+func (headingAnchors) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		if id, ok := heading.AttributeString("id"); ok {
+			link := ast.NewLink()
+			link.Destination = append([]byte("#"), id.([]byte)...)
+			link.SetAttributeString("class", []byte("anchor"))
+			heading.InsertBefore(heading, heading.FirstChild(), link)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{"emphasis", "Hello *world*", []string{"<p>Hello <em>world</em></p>"}, nil},
+		{"script", "Hi <script>alert(1)</script>", []string{"Hi"}, []string{"<script", "alert(1)"}},
+		{"script block", "<script>\nalert(1)\n</script>", nil, []string{"<script", "alert(1)"}},
+		{"javascript link", "[x](javascript:alert(1))", []string{"<p>x</p>"}, []string{"href", "javascript:"}},
+		{"javascript link mixed case", "[x](JaVaScRiPt:alert(1))", nil, []string{"href", "alert"}},
+		{"javascript raw link", `<a href="javascript:alert(1)">x</a>`, nil, []string{"href", "javascript:"}},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", nil, []string{"href", "data:"}},
+		{"data image", "![x](data:image/svg+xml;base64,PHN2Zz4=)", nil, []string{"src", "data:"}},
+		{"onerror", `<img src="x.png" onerror="alert(1)">`, []string{`<img src="x.png">`}, []string{"onerror", "alert"}},
+		{"onclick", `<a href="/x" onclick="alert(1)">x</a>`, []string{`href="/x"`}, []string{"onclick", "alert"}},
+		{"iframe", `<iframe src="https://example.com"></iframe>`, nil, []string{"<iframe", "example.com"}},
+		{"style element", "<style>body { display: none }</style>", nil, []string{"<style", "display"}},
+		{"style attribute", `<p style="position: fixed">x</p>`, []string{"<p>x</p>"}, []string{"style", "fixed"}},
+		{"links are nofollow", "[x](https://example.com)", []string{`<a href="https://example.com" rel="nofollow">x</a>`}, nil},
+		{"code language", "```go\nfmt.Println()\n```", []string{`<code class="language-go">`}, nil},
+		{"code language injection", "```go\" onclick=\"alert(1)\nx\n```", nil, []string{"onclick", "alert"}},
+		{"raw class", `<code class="evil">x</code>`, []string{"<code>x</code>"}, []string{"evil"}},
+		{"task list", "- [x] done", []string{`<input checked="" disabled="" type="checkbox">`}, nil},
+		{"raw input", `<input type="text" value="x">`, nil, []string{"text", "value"}},
+		{"heading anchor", "## Hello World", []string{`<h2 id="hello-world"><a href="#hello-world" class="anchor" rel="nofollow"></a>Hello World</h2>`}, nil},
+		{"heading anchor markup", `# "><img src=x onerror=alert(1)>`, []string{`<h1 id="img-srcx-onerroralert1"><a href="#img-srcx-onerroralert1" class="anchor"`}, []string{"onerror="}},
+		{"heading anchor script", "# <script>alert(1)</script>", []string{`id="scriptalert1script"`}, []string{"<script"}},
+		{"heading attribute syntax", `# Title {#x" onmouseover="alert(1)}`, []string{`id="title-x-onmouseoveralert1"`}, []string{`onmouseover="`}},
+		{"raw heading", `<h2 id="x" onclick="alert(1)">T</h2>`, []string{">T</h2>"}, []string{"onclick"}},
+		{"raw anchor class", `<span class="anchor">x</span>`, nil, []string{"anchor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := Render(tt.source)
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, html, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, html, unwanted)
+			}
+		})
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"none", "Hello world", nil},
+		{"one", "Thanks @alice!", []string{"alice"}},
+		{"start of text", "@alice thanks", []string{"alice"}},
+		{"order of first mention", "@bob and @alice, then @bob again", []string{"bob", "alice"}},
+		{"dots and hyphens", "cc @jane.doe and @john-smith.", []string{"jane.doe", "john-smith"}},
+		{"emphasis", "*@alice* and **@bob**", []string{"alice", "bob"}},
+		{"email", "Mail alice@example.com", nil},
+		{"within a word", "foo@bar and @@baz", nil},
+		{"code span", "Run `@alice` not @bob", []string{"bob"}},
+		{"fenced code", "```\n@alice\n```\n@bob", []string{"bob"}},
+		{"indented code", "    @alice\n\n@bob", []string{"bob"}},
+		{"link text", "[@alice](https://example.com/alice) and @bob", []string{"bob"}},
+		{"autolink", "<https://example.com/@alice> and @bob", []string{"bob"}},
+		{"raw html attribute", `<abbr title="@alice">A</abbr> @bob`, []string{"bob"}},
+		{"raw html text", "<span>@alice</span>", []string{"alice"}},
+		{"html block", "<div>\n@alice\n</div>\n\n@bob", []string{"bob"}},
+		{"lines", "Hi @alice\n@bob", []string{"alice", "bob"}},
+		{"list", "- @alice\n- @bob", []string{"alice", "bob"}},
+		{"bare at", "Meet @ noon", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Mentions(tt.source))
+		})
+	}
+}
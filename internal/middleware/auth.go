@@ -1,20 +1,23 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"go-backend/internal/authz"
 	"go-backend/internal/models"
+	"go-backend/internal/services"
 	"go-backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens, checks the account is still active
+// and resolves the user's permissions
+func AuthMiddleware(jwtService *utils.JWTService, userService *services.UserService, permissionService *services.PermissionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if c.GetHeader("Authorization") == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authorization header is required",
 			})
@@ -22,38 +25,136 @@ func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if !authenticate(c, jwtService, userService, permissionService) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware authenticates the request when it carries a token
+// and lets anonymous requests through. Invalid tokens are still rejected.
+func OptionalAuthMiddleware(jwtService *utils.JWTService, userService *services.UserService, permissionService *services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !authenticate(c, jwtService, userService, permissionService) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticate validates the bearer token and sets the user's information,
+// roles and permissions in the context. It aborts the request and returns
+// false on failure.
+func authenticate(c *gin.Context, jwtService *utils.JWTService, userService *services.UserService, permissionService *services.PermissionService) bool {
+	// Extract token from "Bearer <token>"
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid authorization header format",
+		})
+		c.Abort()
+		return false
+	}
+
+	token := parts[1]
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or expired token",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Tokens outlive deactivation, suspension and deletion, so the account
+	// is checked on every request
+	if userService != nil {
+		if err := userService.WithContext(c.Request.Context()).CheckActive(claims.UserID); err != nil {
+			switch {
+			case errors.Is(err, services.ErrAccountNotFound):
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid or expired token",
+				})
+			case errors.Is(err, services.ErrAccountDeactivated), errors.Is(err, services.ErrAccountSuspended):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": err.Error(),
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to load account",
+				})
+			}
+			c.Abort()
+			return false
+		}
+	}
+
+	// Set user information in context
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_username", claims.Username)
+	c.Set("user_role", claims.Role)
+	c.Set("claims", claims)
+
+	// Resolve the roles and permissions granted directly and through groups
+	if permissionService != nil {
+		effective, err := permissionService.PermissionsForUser(claims.UserID, claims.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to resolve permissions",
+			})
+			c.Abort()
+			return false
+		}
+		c.Set("user_roles", effective.Roles)
+		c.Set("user_permissions", effective.Permissions)
+	}
+
+	return true
+}
+
+// RequirePermission middleware checks if the user has all of the given
+// resource:action permissions, e.g. RequirePermission("post:delete")
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user_permissions")
+		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
+				"error": "User permissions not found in context",
 			})
 			c.Abort()
 			return
 		}
 
-		token := parts[1]
-		claims, err := jwtService.ValidateToken(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
+		granted, ok := value.(services.PermissionSet)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Invalid user permissions type",
 			})
 			c.Abort()
 			return
 		}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_username", claims.Username)
-		c.Set("user_role", claims.Role)
-		c.Set("claims", claims)
+		for _, permission := range permissions {
+			if !granted.Has(permission) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "Insufficient permissions",
+					"permission": permission,
+				})
+				c.Abort()
+				return
+			}
+		}
 
 		c.Next()
 	}
 }
 
-// RequireRole middleware checks if user has required role
+// RequireRole middleware checks if user has required role, either directly
+// or through one of their groups
 func RequireRole(requiredRoles ...models.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
@@ -65,8 +166,7 @@ func RequireRole(requiredRoles ...models.Role) gin.HandlerFunc {
 			return
 		}
 
-		role, ok := userRole.(models.Role)
-		if !ok {
+		if _, ok := userRole.(models.Role); !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Invalid user role type",
 			})
@@ -75,15 +175,7 @@ func RequireRole(requiredRoles ...models.Role) gin.HandlerFunc {
 		}
 
 		// Check if user has any of the required roles
-		hasPermission := false
-		for _, requiredRole := range requiredRoles {
-			if hasRolePermission(role, requiredRole) {
-				hasPermission = true
-				break
-			}
-		}
-
-		if !hasPermission {
+		if !HasAnyRole(UserRoles(c), requiredRoles...) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Insufficient permissions",
 			})
@@ -100,12 +192,13 @@ func RequireAdmin() gin.HandlerFunc {
 	return RequireRole(models.RoleAdmin)
 }
 
-// RequireModerator middleware checks if user has moderator or admin role
+// RequireModerator middleware checks if user has a role inheriting moderator
 func RequireModerator() gin.HandlerFunc {
-	return RequireRole(models.RoleAdmin, models.RoleModerator)
+	return RequireRole(models.RoleModerator)
 }
 
-// RequireOwnerOrAdmin middleware checks if user is the owner of the resource or admin
+// RequireOwnerOrAdmin middleware checks the authorization policy allows the
+// user to manage the resource owned by the user ID returned by getUserIDFunc
 func RequireOwnerOrAdmin(getUserIDFunc func(*gin.Context) uint) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
@@ -117,8 +210,7 @@ func RequireOwnerOrAdmin(getUserIDFunc func(*gin.Context) uint) gin.HandlerFunc
 			return
 		}
 
-		currentUserID, ok := userID.(uint)
-		if !ok {
+		if _, ok := userID.(uint); !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Invalid user ID type",
 			})
@@ -135,8 +227,7 @@ func RequireOwnerOrAdmin(getUserIDFunc func(*gin.Context) uint) gin.HandlerFunc
 			return
 		}
 
-		role, ok := userRole.(models.Role)
-		if !ok {
+		if _, ok := userRole.(models.Role); !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Invalid user role type",
 			})
@@ -144,36 +235,72 @@ func RequireOwnerOrAdmin(getUserIDFunc func(*gin.Context) uint) gin.HandlerFunc
 			return
 		}
 
-		// Admin can access everything
-		if role == models.RoleAdmin {
-			c.Next()
+		subject := SubjectFromContext(c)
+		resource := authz.Object{Type: "resource", Attributes: authz.Attributes{
+			"owner_id": getUserIDFunc(c),
+		}}
+
+		ctx := authz.WithEnvironment(c.Request.Context(), authz.Attributes{"ip": c.ClientIP()})
+		decision := authz.ExplainSubject(ctx, subject, "manage", resource)
+		if !decision.Allowed {
+			response := gin.H{
+				"error": "Access denied: you can only access your own resources",
+			}
+			if authz.Default().Debug() {
+				response["reason"] = decision.Reason
+				response["trace"] = decision.Trace
+			}
+			c.JSON(http.StatusForbidden, response)
+			c.Abort()
 			return
 		}
 
-		// Check if user is the owner
-		resourceUserID := getUserIDFunc(c)
-		if currentUserID == resourceUserID {
-			c.Next()
-			return
+		c.Next()
+	}
+}
+
+// UserRoles returns the user's direct and group roles resolved by
+// AuthMiddleware, falling back to the direct role alone
+func UserRoles(c *gin.Context) []models.Role {
+	if value, exists := c.Get("user_roles"); exists {
+		if roles, ok := value.([]models.Role); ok && len(roles) > 0 {
+			return roles
 		}
+	}
+	if role, ok := c.Value("user_role").(models.Role); ok {
+		return []models.Role{role}
+	}
+	return nil
+}
 
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Access denied: you can only access your own resources",
-		})
-		c.Abort()
+// HasAnyRole checks if any of the roles is or inherits from any of the
+// required roles
+func HasAnyRole(roles []models.Role, requiredRoles ...models.Role) bool {
+	for _, requiredRole := range requiredRoles {
+		for _, role := range roles {
+			if hasRolePermission(role, requiredRole) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
-// hasRolePermission checks if a user role has permission for a required role
-func hasRolePermission(userRole, requiredRole models.Role) bool {
-	switch requiredRole {
-	case models.RoleAdmin:
-		return userRole == models.RoleAdmin
-	case models.RoleModerator:
-		return userRole == models.RoleAdmin || userRole == models.RoleModerator
-	case models.RoleUser:
-		return true // All authenticated users have user permission
-	default:
-		return false
+// SubjectFromContext builds the authorization subject for the authenticated
+// user from the token claims and the roles resolved by AuthMiddleware. It
+// returns nil for anonymous requests. AuthMiddleware rejects deactivated and
+// suspended accounts, so the subject is always active.
+func SubjectFromContext(c *gin.Context) *authz.Subject {
+	userID, ok := c.Value("user_id").(uint)
+	if !ok {
+		return nil
 	}
+	role, _ := c.Value("user_role").(models.Role)
+
+	return &authz.Subject{ID: userID, Role: role, Roles: UserRoles(c), Active: true}
+}
+
+// hasRolePermission checks if a user role is or inherits from a required role
+func hasRolePermission(userRole, requiredRole models.Role) bool {
+	return userRole.Includes(requiredRole)
 }
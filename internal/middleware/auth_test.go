@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermission(t *testing.T) {
+	withPermissions := func(granted interface{}) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if granted != nil {
+				c.Set("user_permissions", granted)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		granted  interface{}
+		required []string
+		status   int
+	}{
+		{"granted", services.PermissionSet{"post:create": true}, []string{"post:create"}, http.StatusOK},
+		{"wildcard", services.PermissionSet{"post:*": true}, []string{"post:create", "post:delete"}, http.StatusOK},
+		{"missing one", services.PermissionSet{"post:create": true}, []string{"post:create", "post:delete"}, http.StatusForbidden},
+		{"none", services.PermissionSet{}, []string{"post:create"}, http.StatusForbidden},
+		{"unauthenticated", nil, []string{"post:create"}, http.StatusUnauthorized},
+		{"wrong type", []string{"post:create"}, []string{"post:create"}, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTestEngine(withPermissions(tt.granted), RequirePermission(tt.required...))
+			assert.Equal(t, tt.status, get(engine, "", nil).Code)
+		})
+	}
+}
+
+func TestRequirePermissionSeesRevokes(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	user := createTestUser(t, db, "author", models.RoleUser)
+	jwtService := newTestJWT()
+	permissionService := services.NewPermissionService(db, nil)
+	_, err := permissionService.CreatePermission(admin.ID, &models.PermissionCreateRequest{Resource: "post", Action: "create"})
+	require.NoError(t, err)
+
+	engine := newTestEngine(AuthMiddleware(jwtService, services.NewUserService(db, jwtService), permissionService), RequirePermission(models.PermPostCreate))
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, get(engine, token, nil).Code)
+
+	require.NoError(t, permissionService.GrantPermission(admin.ID, models.RoleUser, models.PermPostCreate))
+	assert.Equal(t, http.StatusOK, get(engine, token, nil).Code)
+
+	// The same token loses the permission on its next request
+	require.NoError(t, permissionService.RevokePermission(admin.ID, models.RoleUser, models.PermPostCreate))
+	assert.Equal(t, http.StatusForbidden, get(engine, token, nil).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, get(engine, "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, get(engine, "not-a-token", nil).Code)
+}
+
+func TestAuthMiddlewareRejectsInactiveAccounts(t *testing.T) {
+	db := newTestDB(t)
+	jwtService := newTestJWT()
+	userService := services.NewUserService(db, jwtService)
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		update map[string]interface{}
+		delete bool
+		status int
+	}{
+		{"active", nil, false, http.StatusOK},
+		{"deactivated", map[string]interface{}{"is_active": false}, false, http.StatusForbidden},
+		{"suspended", map[string]interface{}{"suspended_until": future}, false, http.StatusForbidden},
+		{"suspension over", map[string]interface{}{"suspended_until": past}, false, http.StatusOK},
+		{"deleted", nil, true, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, db, strings.ReplaceAll(tt.name, " ", "-"), models.RoleUser)
+			token, err := jwtService.GenerateToken(user)
+			require.NoError(t, err)
+			if tt.update != nil {
+				require.NoError(t, db.Model(user).Updates(tt.update).Error)
+			}
+			if tt.delete {
+				require.NoError(t, db.Delete(user).Error)
+			}
+
+			// Owners must not get past owner checks with a token issued
+			// before their account was disabled
+			ownUser := func(*gin.Context) uint { return user.ID }
+			engine := newTestEngine(AuthMiddleware(jwtService, userService, nil), RequireOwnerOrAdmin(ownUser))
+			assert.Equal(t, tt.status, get(engine, token, nil).Code)
+
+			engine = newTestEngine(OptionalAuthMiddleware(jwtService, userService, nil))
+			assert.Equal(t, tt.status, get(engine, token, nil).Code)
+		})
+	}
+}
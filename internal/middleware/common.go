@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"go-backend/internal/etag"
 	"go-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -79,8 +80,9 @@ func CORSMiddleware(origins []string) gin.HandlerFunc {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "86400") // 24 hours
 
@@ -143,3 +145,34 @@ func SecurityHeadersMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// IfMatchMiddleware makes PUT, PATCH and DELETE requests with an If-Match
+// header conditional: versioned resources are only changed if they are still
+// at a version the header names. Requests naming no version that could
+// match fail straight away.
+func IfMatchMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("If-Match")
+		method := c.Request.Method
+		if header == "" || (method != http.MethodPut && method != http.MethodPatch && method != http.MethodDelete) {
+			c.Next()
+			return
+		}
+
+		versions, any := etag.ParseIfMatch(header)
+		if any {
+			c.Next()
+			return
+		}
+		if len(versions) == 0 {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error": "Precondition failed",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(etag.WithExpected(c.Request.Context(), versions))
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/database"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB returns a migrated in-memory SQLite database
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	// Every connection to :memory: opens a separate database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, (&database.Database{DB: db}).Migrate())
+	return db
+}
+
+// createTestUser creates an active user with the role
+func createTestUser(t *testing.T, db *gorm.DB, username string, role models.Role) *models.User {
+	t.Helper()
+	user := &models.User{
+		Email:       username + "@example.com",
+		Username:    username,
+		Password:    "secret123",
+		FirstName:   "Test",
+		LastName:    "User",
+		PhoneNumber: "tel:" + username,
+		Role:        role,
+		IsActive:    true,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+// newTestJWT returns a JWT service signing with a test secret
+func newTestJWT() *utils.JWTService {
+	return utils.NewJWTService(&config.Config{JWT: config.JWTConfig{Secret: "test-secret", Expiry: time.Hour}})
+}
+
+// newTestEngine returns a gin engine serving every method on /test through
+// handlers, answering 200 once they all pass
+func newTestEngine(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	handlers = append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.Any("/test", handlers...)
+	return engine
+}
+
+// get sends GET /test with the bearer token, if any, and headers
+func get(engine *gin.Engine, token string, headers map[string]string) *httptest.ResponseRecorder {
+	return send(engine, http.MethodGet, token, headers)
+}
+
+// send sends a request to /test with the bearer token, if any, and headers.
+// A Host header sets the request host.
+func send(engine *gin.Engine, method, token string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/test", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range headers {
+		if key == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantMiddleware resolves the organization a request acts on and scopes the
+// request context to it. The organization is taken from the tenant header,
+// then the subdomain, then the token's tenant claim. Requests that resolve no
+// organization continue unscoped, so tenant-owned data stays unreachable.
+// Anonymous reads are scoped without a membership check. Must run after
+// AuthMiddleware or OptionalAuthMiddleware.
+func TenantMiddleware(orgService *services.OrganizationService, cfg config.TenancyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		var claimTenant *uint
+		if value, exists := c.Get("claims"); exists {
+			if claims, ok := value.(*utils.JWTClaims); ok {
+				claimTenant = claims.TenantID
+			}
+		}
+
+		ref := resolveTenantRef(c, cfg, claimTenant)
+		if ref == "" {
+			c.Next()
+			return
+		}
+
+		org, err := orgService.ResolveOrganization(ref)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Organization not found",
+			})
+			c.Abort()
+			return
+		}
+
+		// A tenant-scoped token cannot be used against another organization
+		if claimTenant != nil && *claimTenant != org.ID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Token is scoped to a different organization",
+			})
+			c.Abort()
+			return
+		}
+
+		// Anonymous visitors may read an organization's public content, which
+		// the access checks on each resource still limit
+		var member *models.OrganizationMember
+		userID := c.GetUint("user_id")
+		if userID != 0 || !isReadOnly(c.Request.Method) {
+			member, err = orgService.GetMembership(org.ID, userID)
+			if err != nil {
+				// Platform admins can act on any organization
+				role, _ := c.Get("user_role")
+				if userRole, ok := role.(models.Role); !ok || !hasRolePermission(userRole, models.RoleAdmin) {
+					c.JSON(http.StatusForbidden, gin.H{
+						"error": "Not a member of this organization",
+					})
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		c.Set("tenant_id", org.ID)
+		if member != nil {
+			c.Set("tenant_role", member.Role)
+		}
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), org.ID))
+
+		c.Next()
+	}
+}
+
+// isReadOnly checks for methods that do not change anything
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// resolveTenantRef returns the organization ID or slug for the request
+func resolveTenantRef(c *gin.Context, cfg config.TenancyConfig, claimTenant *uint) string {
+	if ref := strings.TrimSpace(c.GetHeader(cfg.Header)); ref != "" {
+		return ref
+	}
+
+	if cfg.BaseDomain != "" {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		suffix := "." + strings.ToLower(cfg.BaseDomain)
+		if sub := strings.TrimSuffix(strings.ToLower(host), suffix); sub != strings.ToLower(host) && sub != "" && !strings.Contains(sub, ".") {
+			return sub
+		}
+	}
+
+	if claimTenant != nil {
+		return strconv.FormatUint(uint64(*claimTenant), 10)
+	}
+
+	return ""
+}
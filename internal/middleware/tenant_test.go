@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolvedTenant reports the tenant the request context was scoped to in the
+// X-Resolved-Tenant header
+func resolvedTenant(c *gin.Context) {
+	if tenantID, ok := tenant.FromContext(c.Request.Context()); ok {
+		c.Header("X-Resolved-Tenant", strconv.FormatUint(uint64(tenantID), 10))
+	}
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	db := newTestDB(t)
+	jwtService := newTestJWT()
+	orgService := services.NewOrganizationService(db, nil)
+	owner := createTestUser(t, db, "owner", models.RoleUser)
+	outsider := createTestUser(t, db, "outsider", models.RoleUser)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+
+	acme, err := orgService.CreateOrganization(owner.ID, &models.OrganizationCreateRequest{Name: "Acme", Slug: "acme"})
+	require.NoError(t, err)
+	globex, err := orgService.CreateOrganization(outsider.ID, &models.OrganizationCreateRequest{Name: "Globex", Slug: "globex"})
+	require.NoError(t, err)
+	closed, err := orgService.CreateOrganization(owner.ID, &models.OrganizationCreateRequest{Name: "Closed", Slug: "closed"})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(closed).Update("is_active", false).Error)
+
+	token := func(user *models.User) string {
+		signed, err := jwtService.GenerateToken(user)
+		require.NoError(t, err)
+		return signed
+	}
+	tenantToken := func(user *models.User, orgID uint) string {
+		signed, err := jwtService.GenerateTenantToken(user, orgID)
+		require.NoError(t, err)
+		return signed
+	}
+
+	cfg := config.TenancyConfig{Enabled: true, Header: "X-Tenant-ID", BaseDomain: "example.com"}
+	engine := newTestEngine(OptionalAuthMiddleware(jwtService, nil, nil), TenantMiddleware(orgService, cfg), resolvedTenant)
+
+	tests := []struct {
+		name    string
+		method  string
+		token   string
+		headers map[string]string
+		status  int
+		tenant  uint
+	}{
+		{"no organization", http.MethodPost, token(owner), nil, http.StatusOK, 0},
+		{"header slug", http.MethodGet, "", map[string]string{"X-Tenant-ID": "acme"}, http.StatusOK, acme.ID},
+		{"header ID", http.MethodGet, "", map[string]string{"X-Tenant-ID": strconv.FormatUint(uint64(acme.ID), 10)}, http.StatusOK, acme.ID},
+		{"subdomain", http.MethodGet, "", map[string]string{"Host": "acme.example.com"}, http.StatusOK, acme.ID},
+		{"subdomain with port", http.MethodGet, "", map[string]string{"Host": "ACME.example.com:8080"}, http.StatusOK, acme.ID},
+		{"nested subdomain", http.MethodGet, "", map[string]string{"Host": "www.acme.example.com"}, http.StatusOK, 0},
+		{"other domain", http.MethodGet, "", map[string]string{"Host": "acme.example.org"}, http.StatusOK, 0},
+		{"header before subdomain", http.MethodGet, "", map[string]string{"X-Tenant-ID": "globex", "Host": "acme.example.com"}, http.StatusOK, globex.ID},
+		{"claim", http.MethodPost, tenantToken(owner, acme.ID), nil, http.StatusOK, acme.ID},
+		{"header matching claim", http.MethodPost, tenantToken(owner, acme.ID), map[string]string{"X-Tenant-ID": "acme"}, http.StatusOK, acme.ID},
+		{"header against claim", http.MethodGet, tenantToken(owner, acme.ID), map[string]string{"X-Tenant-ID": "globex"}, http.StatusForbidden, 0},
+		{"subdomain against claim", http.MethodGet, tenantToken(owner, acme.ID), map[string]string{"Host": "globex.example.com"}, http.StatusForbidden, 0},
+		{"unknown organization", http.MethodGet, "", map[string]string{"X-Tenant-ID": "initech"}, http.StatusNotFound, 0},
+		{"inactive organization", http.MethodGet, token(owner), map[string]string{"X-Tenant-ID": "closed"}, http.StatusNotFound, 0},
+		{"member writes", http.MethodPost, token(owner), map[string]string{"X-Tenant-ID": "acme"}, http.StatusOK, acme.ID},
+		{"non-member reads", http.MethodGet, token(outsider), map[string]string{"X-Tenant-ID": "acme"}, http.StatusForbidden, 0},
+		{"non-member writes", http.MethodPost, token(outsider), map[string]string{"X-Tenant-ID": "acme"}, http.StatusForbidden, 0},
+		{"anonymous writes", http.MethodPost, "", map[string]string{"X-Tenant-ID": "acme"}, http.StatusForbidden, 0},
+		{"anonymous deletes", http.MethodDelete, "", map[string]string{"Host": "acme.example.com"}, http.StatusForbidden, 0},
+		{"platform admin writes", http.MethodPost, token(admin), map[string]string{"X-Tenant-ID": "acme"}, http.StatusOK, acme.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(engine, tt.method, tt.token, tt.headers)
+			assert.Equal(t, tt.status, w.Code)
+			resolved := ""
+			if tt.tenant != 0 {
+				resolved = strconv.FormatUint(uint64(tt.tenant), 10)
+			}
+			assert.Equal(t, resolved, w.Header().Get("X-Resolved-Tenant"))
+		})
+	}
+}
+
+func TestTenantMiddlewareDisabled(t *testing.T) {
+	db := newTestDB(t)
+	owner := createTestUser(t, db, "owner", models.RoleUser)
+	orgService := services.NewOrganizationService(db, nil)
+	_, err := orgService.CreateOrganization(owner.ID, &models.OrganizationCreateRequest{Name: "Acme", Slug: "acme"})
+	require.NoError(t, err)
+
+	engine := newTestEngine(TenantMiddleware(orgService, config.TenancyConfig{Header: "X-Tenant-ID"}), resolvedTenant)
+	w := send(engine, http.MethodPost, "", map[string]string{"X-Tenant-ID": "acme"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Resolved-Tenant"))
+}
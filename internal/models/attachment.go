@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AttachmentTarget is the kind of content a file is attached to
+type AttachmentTarget string
+
+// Attachment targets
+const (
+	AttachmentTargetPost    AttachmentTarget = "post"
+	AttachmentTargetComment AttachmentTarget = "comment"
+)
+
+// AttachmentCategory is the file category of files uploaded straight onto a
+// post or comment. Such files belong to their attachments and are deleted
+// once they are no longer attached to anything.
+const AttachmentCategory = "attachment"
+
+// AttachmentURL returns the URL an attachment is downloaded from
+func AttachmentURL(id uint) string {
+	return fmt.Sprintf("/api/v1/attachments/%d/download", id)
+}
+
+// Attachment is an uploaded file attached to a post or comment. Who can see
+// an attachment follows the post it is on, not whether the file is public.
+type Attachment struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	TenantID   *uint            `json:"tenant_id,omitempty" gorm:"index"`
+	FileID     uint             `json:"file_id" gorm:"not null;uniqueIndex:idx_attachment_unique;index"`
+	TargetType AttachmentTarget `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_attachment_unique;index:idx_attachment_target"`
+	TargetID   uint             `json:"target_id" gorm:"not null;uniqueIndex:idx_attachment_unique;index:idx_attachment_target"`
+	PostID     uint             `json:"post_id" gorm:"not null;index"`
+	UserID     uint             `json:"user_id" gorm:"not null"`
+	Position   int              `json:"position" gorm:"not null;default:0"`
+	Caption    string           `json:"caption" gorm:"size:500"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+
+	// Relationships
+	File *FileUpload `json:"-" gorm:"foreignKey:FileID"`
+}
+
+// AttachmentResponse is an attachment with the details of its file
+type AttachmentResponse struct {
+	ID         uint             `json:"id"`
+	TargetType AttachmentTarget `json:"target_type"`
+	TargetID   uint             `json:"target_id"`
+	PostID     uint             `json:"post_id"`
+	Position   int              `json:"position"`
+	Caption    string           `json:"caption"`
+	FileID     uint             `json:"file_id"`
+	FileName   string           `json:"file_name"`
+	FileSize   int64            `json:"file_size"`
+	MimeType   string           `json:"mime_type"`
+	FileType   string           `json:"file_type"`
+	URL        string           `json:"url"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// ToResponse converts an attachment with its file loaded to a response,
+// downloadable from url
+func (a *Attachment) ToResponse(url string) AttachmentResponse {
+	response := AttachmentResponse{
+		ID:         a.ID,
+		TargetType: a.TargetType,
+		TargetID:   a.TargetID,
+		PostID:     a.PostID,
+		Position:   a.Position,
+		Caption:    a.Caption,
+		FileID:     a.FileID,
+		URL:        url,
+		CreatedAt:  a.CreatedAt,
+	}
+	if a.File != nil {
+		response.FileName = a.File.OriginalName
+		response.FileSize = a.File.FileSize
+		response.MimeType = a.File.MimeType
+		response.FileType = a.File.FileType
+	}
+	return response
+}
+
+// AttachmentCreateRequest attaches a file the user already uploaded.
+// Attachments are added at the end unless a position is given.
+type AttachmentCreateRequest struct {
+	FileID   uint   `json:"file_id" validate:"required"`
+	Caption  string `json:"caption" validate:"max=500"`
+	Position *int   `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// AttachmentUploadRequest carries the form fields sent along with a file
+// uploaded and attached in one call
+type AttachmentUploadRequest struct {
+	Caption  string `json:"caption" form:"caption" validate:"max=500"`
+	Position *int   `json:"position,omitempty" form:"position" validate:"omitempty,min=0"`
+}
+
+// AttachmentUpdateRequest changes an attachment's caption or moves it
+type AttachmentUpdateRequest struct {
+	Caption  *string `json:"caption,omitempty" validate:"omitempty,max=500"`
+	Position *int    `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// AttachmentOrderRequest lists all of a post's or comment's attachments in
+// their new order
+type AttachmentOrderRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1"`
+}
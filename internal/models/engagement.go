@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// ReactionTarget is the kind of content a reaction is attached to
+type ReactionTarget string
+
+// Reaction targets
+const (
+	ReactionTargetPost    ReactionTarget = "post"
+	ReactionTargetComment ReactionTarget = "comment"
+)
+
+// Reaction is a user's emoji reaction to a post or comment. A user can react
+// to the same content with several emoji, but only once with each.
+type Reaction struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	TenantID   *uint          `json:"tenant_id,omitempty" gorm:"index"`
+	UserID     uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_reaction_unique"`
+	TargetType ReactionTarget `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_reaction_unique;index:idx_reaction_target"`
+	TargetID   uint           `json:"target_id" gorm:"not null;uniqueIndex:idx_reaction_unique;index:idx_reaction_target"`
+	Emoji      string         `json:"emoji" gorm:"size:32;not null;uniqueIndex:idx_reaction_unique"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// PostView records when a viewer last counted as viewing a post, so repeat
+// views within the deduplication window are not counted again
+type PostView struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PostID       uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_post_viewer"`
+	ViewerKey    string    `json:"-" gorm:"size:64;not null;uniqueIndex:idx_post_viewer"`
+	LastViewedAt time.Time `json:"last_viewed_at" gorm:"index"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -43,6 +45,7 @@ func (ev *EmailVerification) MarkAsUsed() {
 // AuditLog represents system audit logs
 type AuditLog struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
+	TenantID   *uint     `json:"tenant_id,omitempty" gorm:"index"`
 	UserID     *uint     `json:"user_id,omitempty" gorm:"index"`
 	Action     string    `json:"action" gorm:"not null;index"`   // CREATE, UPDATE, DELETE, LOGIN, etc.
 	Resource   string    `json:"resource" gorm:"not null;index"` // user, post, comment, etc.
@@ -91,25 +94,255 @@ type UserSession struct {
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
-// Permission represents system permissions
+// RoleDefinition represents a role stored in the roles table. A role inherits
+// every permission and role check of its parent.
+type RoleDefinition struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        Role      `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	ParentID    *uint     `json:"parent_id,omitempty" gorm:"index"`
+	IsSystem    bool      `json:"is_system" gorm:"default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Parent *RoleDefinition `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
+}
+
+// TableName sets the table name for role definitions
+func (RoleDefinition) TableName() string {
+	return "roles"
+}
+
+// DefaultRoles lists the built-in roles, parents first
+var DefaultRoles = []struct {
+	Name        Role
+	Parent      Role
+	DisplayName string
+	Description string
+}{
+	{RoleUser, "", "User", "Regular authenticated user"},
+	{RoleModerator, RoleUser, "Moderator", "Moderates users and content"},
+	{RoleAdmin, RoleModerator, "Administrator", "Full access to the system"},
+}
+
+// RoleCreateRequest represents the request payload for creating a role
+type RoleCreateRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=50"`
+	DisplayName string `json:"display_name,omitempty" validate:"omitempty,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Parent      Role   `json:"parent,omitempty" validate:"omitempty,role"`
+}
+
+// RoleUpdateRequest represents the request payload for updating a role
+type RoleUpdateRequest struct {
+	DisplayName *string `json:"display_name,omitempty" validate:"omitempty,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Parent      *Role   `json:"parent,omitempty" validate:"omitempty,role"`
+}
+
+// Permission represents system permissions in resource:action form (e.g. post:delete)
 type Permission struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	Name        string         `json:"name" gorm:"uniqueIndex;not null"`
 	Description string         `json:"description"`
-	Resource    string         `json:"resource" gorm:"not null"` // user, post, comment, etc.
-	Action      string         `json:"action" gorm:"not null"`   // create, read, update, delete
+	Resource    string         `json:"resource" gorm:"not null;index"` // user, post, comment, etc.
+	Action      string         `json:"action" gorm:"not null"`         // create, read, update, delete
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
-	// Many-to-many relationships
-	Roles []*Role `json:"roles,omitempty" gorm:"many2many:role_permissions;"`
+	// Relationships
+	RolePermissions []RolePermission `json:"-" gorm:"foreignKey:PermissionID"`
 }
 
 // RolePermission represents the junction table for roles and permissions
 type RolePermission struct {
-	RoleID       uint `json:"role_id" gorm:"primaryKey"`
-	PermissionID uint `json:"permission_id" gorm:"primaryKey"`
+	Role         Role      `json:"role" gorm:"primaryKey;size:50"`
+	PermissionID uint      `json:"permission_id" gorm:"primaryKey"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relationships
+	Permission Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
+}
+
+// Built-in permission names. A grant lets the role perform the action on any
+// resource of that type; owners can always act on their own resources.
+const (
+	PermUserRead         = "user:read"
+	PermUserUpdate       = "user:update"
+	PermUserDelete       = "user:delete"
+	PermPostCreate       = "post:create"
+	PermPostUpdate       = "post:update"
+	PermPostDelete       = "post:delete"
+	PermPostBulkDelete   = "post:bulk_delete"
+	PermCommentCreate    = "comment:create"
+	PermCommentUpdate    = "comment:update"
+	PermCommentDelete    = "comment:delete"
+	PermCommentModerate  = "comment:moderate"
+	PermFileCreate       = "file:create"
+	PermFileRead         = "file:read"
+	PermFileUpdate       = "file:update"
+	PermFileDelete       = "file:delete"
+	PermPermissionManage = "permission:manage"
+	PermRoleManage       = "role:manage"
+	PermGroupManage      = "group:manage"
+	PermTaxonomyManage   = "taxonomy:manage"
+	PermReportModerate   = "report:moderate"
+)
+
+// DefaultPermissions lists the permissions seeded on a fresh database
+var DefaultPermissions = []Permission{
+	{Name: PermUserRead, Description: "View any user account"},
+	{Name: PermUserUpdate, Description: "Update any user account"},
+	{Name: PermUserDelete, Description: "Delete any user account"},
+	{Name: PermPostCreate, Description: "Create posts"},
+	{Name: PermPostUpdate, Description: "Edit posts written by other users"},
+	{Name: PermPostDelete, Description: "Delete posts written by other users"},
+	{Name: PermPostBulkDelete, Description: "Delete posts in bulk"},
+	{Name: PermCommentCreate, Description: "Create comments"},
+	{Name: PermCommentUpdate, Description: "Edit comments written by other users"},
+	{Name: PermCommentDelete, Description: "Delete comments written by other users"},
+	{Name: PermCommentModerate, Description: "Approve or reject comments"},
+	{Name: PermFileCreate, Description: "Upload files"},
+	{Name: PermFileRead, Description: "Download files uploaded by other users"},
+	{Name: PermFileUpdate, Description: "Update metadata of files uploaded by other users"},
+	{Name: PermFileDelete, Description: "Delete files uploaded by other users"},
+	{Name: PermPermissionManage, Description: "Manage permissions and role grants"},
+	{Name: PermRoleManage, Description: "Create, update and delete roles"},
+	{Name: PermGroupManage, Description: "Manage groups, their members and grants"},
+	{Name: PermTaxonomyManage, Description: "Manage, rename and merge tags and categories"},
+	{Name: PermReportModerate, Description: "Review reports and hide content, warn and suspend users"},
+}
+
+// DefaultRolePermissions maps the built-in roles to their seeded grants.
+// It mirrors the behaviour of the original hard-coded role checks.
+var DefaultRolePermissions = map[Role][]string{
+	RoleAdmin: {
+		PermUserRead, PermUserUpdate, PermUserDelete,
+		PermPostCreate, PermPostUpdate, PermPostDelete, PermPostBulkDelete,
+		PermCommentCreate, PermCommentUpdate, PermCommentDelete, PermCommentModerate,
+		PermFileCreate, PermFileRead, PermFileUpdate, PermFileDelete,
+		PermPermissionManage, PermRoleManage, PermGroupManage,
+		PermTaxonomyManage, PermReportModerate,
+	},
+	RoleModerator: {
+		PermUserRead,
+		PermPostCreate, PermPostUpdate, PermPostDelete,
+		PermCommentCreate, PermCommentUpdate, PermCommentDelete, PermCommentModerate,
+		PermFileCreate, PermFileRead,
+		PermTaxonomyManage, PermReportModerate,
+	},
+	RoleUser: {
+		PermPostCreate,
+		PermCommentCreate,
+		PermFileCreate,
+	},
+}
+
+// ParsePermission splits a resource:action permission name
+func ParsePermission(name string) (resource, action string, ok bool) {
+	parts := strings.SplitN(name, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// PermissionCreateRequest represents the request payload for creating a permission
+type PermissionCreateRequest struct {
+	Resource    string `json:"resource" validate:"required,min=1,max=50"`
+	Action      string `json:"action" validate:"required,min=1,max=50"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// RolePermissionRequest represents the request payload for granting a permission to a role
+type RolePermissionRequest struct {
+	Permission string `json:"permission" validate:"required"`
+}
+
+// Group represents a team of users. Roles and permissions granted to a group
+// apply to its members and to the members of every group nested under it.
+type Group struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description string         `json:"description"`
+	ParentID    *uint          `json:"parent_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Parent      *Group            `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
+	Roles       []GroupRole       `json:"roles,omitempty" gorm:"foreignKey:GroupID"`
+	Permissions []GroupPermission `json:"permissions,omitempty" gorm:"foreignKey:GroupID"`
+}
+
+// GroupMember represents a user's membership in a group
+type GroupMember struct {
+	GroupID   uint      `json:"group_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// GroupRole represents a role granted to a group
+type GroupRole struct {
+	GroupID   uint      `json:"group_id" gorm:"primaryKey"`
+	Role      Role      `json:"role" gorm:"primaryKey;size:50"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GroupPermission represents a permission granted directly to a group
+type GroupPermission struct {
+	GroupID      uint      `json:"group_id" gorm:"primaryKey"`
+	PermissionID uint      `json:"permission_id" gorm:"primaryKey"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relationships
+	Permission Permission `json:"permission,omitempty" gorm:"foreignKey:PermissionID"`
+}
+
+// GroupCreateRequest represents the request payload for creating a group
+type GroupCreateRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
+	ParentID    *uint  `json:"parent_id,omitempty"`
+}
+
+// GroupUpdateRequest represents the request payload for updating a group.
+// A parent ID of 0 moves the group to the top level.
+type GroupUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	ParentID    *uint   `json:"parent_id,omitempty"`
+}
+
+// GroupMemberRequest represents the request payload for adding a group member
+type GroupMemberRequest struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+// GroupRoleRequest represents the request payload for granting a role to a group
+type GroupRoleRequest struct {
+	Role Role `json:"role" validate:"required,role"`
+}
+
+// AccessCheck asks whether the current user may perform an action on a
+// resource. Without a resource ID the check applies to the resource type,
+// e.g. creating a post.
+type AccessCheck struct {
+	Action       string `json:"action" validate:"required,max=50"`
+	ResourceType string `json:"resource_type" validate:"required,max=50"`
+	ResourceID   *uint  `json:"resource_id,omitempty"`
+}
+
+// AccessCheckRequest represents the request payload for a batch access check
+type AccessCheckRequest struct {
+	Checks []AccessCheck `json:"checks" validate:"required,min=1,max=100,dive"`
 }
 
 // UserLoginAttempt tracks login attempts for security
@@ -125,6 +358,7 @@ type UserLoginAttempt struct {
 // FileUpload represents uploaded files
 type FileUpload struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
+	TenantID      *uint          `json:"tenant_id,omitempty" gorm:"index"`
 	UserID        uint           `json:"user_id" gorm:"not null;index"`
 	OriginalName  string         `json:"original_name" gorm:"not null"`
 	FileName      string         `json:"file_name" gorm:"not null;uniqueIndex"`
@@ -139,13 +373,27 @@ type FileUpload struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// DeletedBy is who moved the file to the trash
+	DeletedBy *uint `json:"-"`
+
 	// Relationships
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// MarshalJSON serializes the file with its uploader's public profile rather
+// than their account
+func (f FileUpload) MarshalJSON() ([]byte, error) {
+	type fileUpload FileUpload
+	return json.Marshal(struct {
+		fileUpload
+		User *UserProfile `json:"user,omitempty"`
+	}{fileUpload(f), authorProfile(&f.User)})
+}
+
 // Notification represents system notifications
 type Notification struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  *uint          `json:"tenant_id,omitempty" gorm:"index"`
 	UserID    uint           `json:"user_id" gorm:"not null;index"`
 	Type      string         `json:"type" gorm:"not null;index"` // email, sms, push, in_app
 	Title     string         `json:"title" gorm:"not null"`
@@ -159,7 +407,7 @@ type Notification struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
-	User User `json:"user" gorm:"foreignKey:UserID"`
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
 // MarkAsRead marks the notification as read
@@ -0,0 +1,66 @@
+package models
+
+// Import formats
+const (
+	ImportFormatBundle = "bundle"
+	ImportFormatWXR    = "wxr"
+)
+
+// ImportConflictPolicy says what happens to an imported post whose slug is
+// already taken
+type ImportConflictPolicy string
+
+// Import conflict policies
+const (
+	ImportConflictSkip   ImportConflictPolicy = "skip"
+	ImportConflictRename ImportConflictPolicy = "rename"
+)
+
+// ImportAction is what an import does, or would do, with a post
+type ImportAction string
+
+// Import actions
+const (
+	ImportActionCreate ImportAction = "create"
+	ImportActionRename ImportAction = "rename"
+	ImportActionSkip   ImportAction = "skip"
+)
+
+// ImportRequest carries the form fields sent along with a post bundle or
+// WordPress export
+type ImportRequest struct {
+	// DryRun reports what the import would do without changing anything
+	DryRun bool `json:"dry_run" form:"dry_run"`
+	// OnConflict defaults to skipping posts whose slug is taken
+	OnConflict ImportConflictPolicy `json:"on_conflict,omitempty" form:"on_conflict" validate:"omitempty,oneof=skip rename"`
+}
+
+// ImportReport reports what an import did, or would do in a dry run, with
+// each post it found. Conflicts counts the posts whose slug was taken,
+// whether they were skipped or renamed.
+type ImportReport struct {
+	Format    string         `json:"format"`
+	DryRun    bool           `json:"dry_run"`
+	Created   int            `json:"created"`
+	Skipped   int            `json:"skipped"`
+	Conflicts int            `json:"conflicts"`
+	Posts     []ImportedPost `json:"posts"`
+}
+
+// ImportedPost is a post found in an import. Slug is the slug the post got,
+// or would get, and SourceSlug the one it had when they differ. Warnings
+// note what was left out or changed on the way.
+type ImportedPost struct {
+	Title       string       `json:"title"`
+	Slug        string       `json:"slug"`
+	SourceSlug  string       `json:"source_slug,omitempty"`
+	Author      string       `json:"author"`
+	AuthorID    uint         `json:"author_id"`
+	Status      PostStatus   `json:"status"`
+	Action      ImportAction `json:"action"`
+	Conflict    string       `json:"conflict,omitempty"`
+	PostID      uint         `json:"post_id,omitempty"`
+	Comments    int          `json:"comments"`
+	Attachments int          `json:"attachments"`
+	Warnings    []string     `json:"warnings,omitempty"`
+}
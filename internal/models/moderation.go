@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+)
+
+// ReportTarget is the kind of thing a report is about
+type ReportTarget string
+
+// Report targets
+const (
+	ReportTargetPost    ReportTarget = "post"
+	ReportTargetComment ReportTarget = "comment"
+	ReportTargetUser    ReportTarget = "user"
+)
+
+// ReportReason is why something was reported
+type ReportReason string
+
+// Report reasons
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonViolence       ReportReason = "violence"
+	ReportReasonSexualContent  ReportReason = "sexual_content"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonOther          ReportReason = "other"
+)
+
+// ReportStatus is where a report is in the moderation queue
+type ReportStatus string
+
+// Report statuses. Open and in-review reports are in the queue.
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusInReview  ReportStatus = "in_review"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// IsValid checks if the report status is valid
+func (s ReportStatus) IsValid() bool {
+	switch s {
+	case ReportStatusOpen, ReportStatusInReview, ReportStatusResolved, ReportStatusDismissed:
+		return true
+	}
+	return false
+}
+
+// IsClosed checks if the report has been dealt with
+func (s ReportStatus) IsClosed() bool {
+	return s == ReportStatusResolved || s == ReportStatusDismissed
+}
+
+// Report is a user's report of a post, comment or user to the moderators
+type Report struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	TenantID   *uint        `json:"tenant_id,omitempty" gorm:"index"`
+	ReporterID uint         `json:"reporter_id" gorm:"not null;index"`
+	TargetType ReportTarget `json:"target_type" gorm:"size:20;not null;index:idx_report_target"`
+	TargetID   uint         `json:"target_id" gorm:"not null;index:idx_report_target"`
+	Reason     ReportReason `json:"reason" gorm:"size:30;not null"`
+	Details    string       `json:"details,omitempty" gorm:"type:text"`
+
+	// TargetUserID is the reported user, or the author of the reported content
+	TargetUserID uint `json:"target_user_id" gorm:"not null;index"`
+
+	// Moderation
+	Status     ReportStatus `json:"status" gorm:"size:20;not null;default:'open';index"`
+	AssigneeID *uint        `json:"assignee_id,omitempty" gorm:"index"`
+	Resolution string       `json:"resolution,omitempty" gorm:"type:text"`
+	ResolvedBy *uint        `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Reporter   *User              `json:"reporter,omitempty" gorm:"foreignKey:ReporterID"`
+	TargetUser *User              `json:"target_user,omitempty" gorm:"foreignKey:TargetUserID"`
+	Assignee   *User              `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+	Actions    []ModerationAction `json:"actions,omitempty" gorm:"foreignKey:ReportID"`
+}
+
+// ModerationActionType is what a moderator did about content or a user
+type ModerationActionType string
+
+// Moderation actions
+const (
+	// ModerationHide archives a post or rejects a comment
+	ModerationHide ModerationActionType = "hide"
+	// ModerationWarn warns the author without touching their content
+	ModerationWarn ModerationActionType = "warn"
+	// ModerationSuspend stops the author from signing in or acting for a while
+	ModerationSuspend ModerationActionType = "suspend"
+)
+
+// ModerationAction records a moderator's action against content or a user
+type ModerationAction struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	TenantID     *uint                `json:"tenant_id,omitempty" gorm:"index"`
+	ModeratorID  uint                 `json:"moderator_id" gorm:"not null;index"`
+	Action       ModerationActionType `json:"action" gorm:"size:20;not null"`
+	TargetType   ReportTarget         `json:"target_type" gorm:"size:20;not null;index:idx_moderation_target"`
+	TargetID     uint                 `json:"target_id" gorm:"not null;index:idx_moderation_target"`
+	TargetUserID uint                 `json:"target_user_id" gorm:"not null;index"`
+	ReportID     *uint                `json:"report_id,omitempty" gorm:"index"`
+	Reason       string               `json:"reason" gorm:"type:text;not null"`
+	// ExpiresAt is when a suspension ends
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	Moderator *User `json:"moderator,omitempty" gorm:"foreignKey:ModeratorID"`
+}
+
+// ReportCreateRequest represents the request payload for reporting a post,
+// comment or user
+type ReportCreateRequest struct {
+	TargetType ReportTarget `json:"target_type" validate:"required,oneof=post comment user"`
+	TargetID   uint         `json:"target_id" validate:"required"`
+	Reason     ReportReason `json:"reason" validate:"required,oneof=spam harassment hate_speech violence sexual_content misinformation other"`
+	Details    string       `json:"details,omitempty" validate:"omitempty,max=2000"`
+}
+
+// ReportUpdateRequest represents the request payload for assigning a report
+// or changing its status. An assignee ID of 0 unassigns the report.
+type ReportUpdateRequest struct {
+	Status     *ReportStatus `json:"status,omitempty" validate:"omitempty,oneof=open in_review resolved dismissed"`
+	AssigneeID *uint         `json:"assignee_id,omitempty"`
+	Resolution *string       `json:"resolution,omitempty" validate:"omitempty,max=2000"`
+}
+
+// ModerationActionRequest represents the request payload for acting against
+// content or a user. The target defaults to the report's when a report is
+// given. Suspensions last Days days.
+type ModerationActionRequest struct {
+	Action     ModerationActionType `json:"action" validate:"required,oneof=hide warn suspend"`
+	TargetType ReportTarget         `json:"target_type,omitempty" validate:"omitempty,oneof=post comment user"`
+	TargetID   uint                 `json:"target_id,omitempty"`
+	ReportID   *uint                `json:"report_id,omitempty"`
+	Reason     string               `json:"reason" validate:"required,min=1,max=2000"`
+	Days       int                  `json:"days,omitempty" validate:"omitempty,min=1,max=3650"`
+}
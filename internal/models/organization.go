@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrgRole represents a user's role within an organization
+type OrgRole string
+
+const (
+	OrgRoleOwner  OrgRole = "owner"
+	OrgRoleAdmin  OrgRole = "admin"
+	OrgRoleMember OrgRole = "member"
+)
+
+// IsValid checks if the organization role is known
+func (r OrgRole) IsValid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers checks if the role may add, remove and change members
+func (r OrgRole) CanManageMembers() bool {
+	return r == OrgRoleOwner || r == OrgRoleAdmin
+}
+
+// Organization represents a tenant. Tenant-scoped records reference it
+// through their TenantID.
+type Organization struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"not null"`
+	Slug      string         `json:"slug" gorm:"uniqueIndex;size:63;not null"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Members []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// OrganizationMember represents a user's membership in an organization
+type OrganizationMember struct {
+	OrganizationID uint      `json:"organization_id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"primaryKey;index"`
+	Role           OrgRole   `json:"role" gorm:"size:20;not null;default:'member'"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relationships
+	Organization *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	User         *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// OrganizationCreateRequest represents the request payload for creating an organization
+type OrganizationCreateRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	Slug string `json:"slug" validate:"required,min=2,max=63"`
+}
+
+// OrganizationUpdateRequest represents the request payload for updating an organization
+type OrganizationUpdateRequest struct {
+	Name     *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// MemberAddRequest represents the request payload for adding a member
+type MemberAddRequest struct {
+	UserID uint    `json:"user_id" validate:"required"`
+	Role   OrgRole `json:"role" validate:"omitempty,oneof=owner admin member"`
+}
+
+// MemberUpdateRequest represents the request payload for changing a member's role
+type MemberUpdateRequest struct {
+	Role OrgRole `json:"role" validate:"required,oneof=owner admin member"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// SharePermission is the access a share grants on a resource
+type SharePermission string
+
+const (
+	SharePermissionViewer SharePermission = "viewer"
+	SharePermissionEditor SharePermission = "editor"
+)
+
+// IsValid checks if the share permission is known
+func (p SharePermission) IsValid() bool {
+	return p == SharePermissionViewer || p == SharePermissionEditor
+}
+
+// Includes checks if the permission grants at least the other permission
+func (p SharePermission) Includes(other SharePermission) bool {
+	return p == other || p == SharePermissionEditor
+}
+
+// Share grantee types
+const (
+	ShareGranteeUser  = "user"
+	ShareGranteeGroup = "group"
+)
+
+// Shareable resource types
+const (
+	ShareResourcePost = "post"
+	ShareResourceFile = "file"
+)
+
+// ResourceShare grants a user, or every member of a group, access to a post
+// or file
+type ResourceShare struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	ResourceType string          `json:"resource_type" gorm:"size:20;not null;index:idx_share_resource"`
+	ResourceID   uint            `json:"resource_id" gorm:"not null;index:idx_share_resource"`
+	GranteeType  string          `json:"grantee_type" gorm:"size:10;not null;index:idx_share_grantee"`
+	GranteeID    uint            `json:"grantee_id" gorm:"not null;index:idx_share_grantee"`
+	Permission   SharePermission `json:"permission" gorm:"size:10;not null"`
+	GrantedBy    uint            `json:"granted_by"`
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// IsExpired checks if the share has expired
+func (s *ResourceShare) IsExpired() bool {
+	return s.ExpiresAt != nil && time.Now().After(*s.ExpiresAt)
+}
+
+// ShareLink grants read access to a post or file to anyone holding its token
+type ShareLink struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Token        string     `json:"token" gorm:"uniqueIndex;size:64;not null"`
+	ResourceType string     `json:"resource_type" gorm:"size:20;not null;index:idx_share_link_resource"`
+	ResourceID   uint       `json:"resource_id" gorm:"not null;index:idx_share_link_resource"`
+	CreatedBy    uint       `json:"created_by"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsExpired checks if the link has expired
+func (l *ShareLink) IsExpired() bool {
+	return l.ExpiresAt != nil && time.Now().After(*l.ExpiresAt)
+}
+
+// ShareCreateRequest represents the request payload for sharing a resource
+type ShareCreateRequest struct {
+	ResourceType string          `json:"resource_type" validate:"required,oneof=post file"`
+	ResourceID   uint            `json:"resource_id" validate:"required"`
+	GranteeType  string          `json:"grantee_type" validate:"required,oneof=user group"`
+	GranteeID    uint            `json:"grantee_id" validate:"required"`
+	Permission   SharePermission `json:"permission" validate:"required,oneof=viewer editor"`
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`
+}
+
+// ShareLinkCreateRequest represents the request payload for creating a share link
+type ShareLinkCreateRequest struct {
+	ResourceType string     `json:"resource_type" validate:"required,oneof=post file"`
+	ResourceID   uint       `json:"resource_id" validate:"required"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
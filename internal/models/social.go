@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+)
+
+// Follow is a user following another user's posts. Followers who asked to be
+// notified get an inbox notification when the followee publishes a post.
+type Follow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TenantID   *uint     `json:"tenant_id,omitempty" gorm:"index"`
+	FollowerID uint      `json:"follower_id" gorm:"not null;uniqueIndex:idx_follow_unique"`
+	FolloweeID uint      `json:"followee_id" gorm:"not null;uniqueIndex:idx_follow_unique;index"`
+	Notify     bool      `json:"notify" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relationships
+	Follower *User `json:"follower,omitempty" gorm:"foreignKey:FollowerID"`
+	Followee *User `json:"followee,omitempty" gorm:"foreignKey:FolloweeID"`
+}
+
+// TimelineEntry is a post in a user's materialised home timeline. Entries are
+// written when followed authors publish, so the home feed of users following
+// many authors does not have to be assembled on every read.
+type TimelineEntry struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  *uint     `json:"tenant_id,omitempty" gorm:"index"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_timeline_post"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_timeline_post"`
+	AuthorID  uint      `json:"author_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Block is a user blocking another user. Blocked users cannot follow the
+// blocker, and their mentions of the blocker are ignored.
+type Block struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  *uint     `json:"tenant_id,omitempty" gorm:"index"`
+	BlockerID uint      `json:"blocker_id" gorm:"not null;uniqueIndex:idx_block_unique"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_block_unique;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Blocked *User `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+}
+
+// BlockedUser is a user in a block list with when they were blocked
+type BlockedUser struct {
+	UserProfile
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// MentionTarget is the kind of content a mention is made in
+type MentionTarget string
+
+// Mention targets
+const (
+	MentionTargetPost    MentionTarget = "post"
+	MentionTargetComment MentionTarget = "comment"
+)
+
+// MentionSetting is how a user wants to be notified of mentions
+type MentionSetting string
+
+// Mention settings
+const (
+	MentionsOff   MentionSetting = "off"
+	MentionsInApp MentionSetting = "in_app"
+	MentionsEmail MentionSetting = "email"
+	MentionsAll   MentionSetting = "all"
+)
+
+// InApp reports whether mentions are notified in the app
+func (s MentionSetting) InApp() bool {
+	return s == MentionsInApp || s == MentionsAll
+}
+
+// Email reports whether mentions are notified by email
+func (s MentionSetting) Email() bool {
+	return s == MentionsEmail || s == MentionsAll
+}
+
+// Mention is a user @mentioned in a post or comment. Mentions are delivered
+// once the content is visible to everyone: when the post is published or the
+// comment approved.
+type Mention struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	TenantID    *uint         `json:"tenant_id,omitempty" gorm:"index"`
+	UserID      uint          `json:"user_id" gorm:"not null;uniqueIndex:idx_mention_unique;index:idx_mention_user"`
+	AuthorID    uint          `json:"author_id" gorm:"not null"`
+	TargetType  MentionTarget `json:"target_type" gorm:"size:20;not null;uniqueIndex:idx_mention_unique;index:idx_mention_target"`
+	TargetID    uint          `json:"target_id" gorm:"not null;uniqueIndex:idx_mention_unique;index:idx_mention_target"`
+	PostID      uint          `json:"post_id" gorm:"not null;index"`
+	DeliveredAt *time.Time    `json:"delivered_at,omitempty" gorm:"index:idx_mention_user"`
+	CreatedAt   time.Time     `json:"created_at"`
+
+	// Relationships
+	Author *User `json:"-" gorm:"foreignKey:AuthorID"`
+	Post   *Post `json:"-" gorm:"foreignKey:PostID"`
+}
+
+// MentionResponse is a mention of the requesting user with where it was made
+type MentionResponse struct {
+	ID          uint          `json:"id"`
+	TargetType  MentionTarget `json:"target_type"`
+	TargetID    uint          `json:"target_id"`
+	PostID      uint          `json:"post_id"`
+	PostTitle   string        `json:"post_title"`
+	PostSlug    string        `json:"post_slug"`
+	Author      UserProfile   `json:"author"`
+	MentionedAt time.Time     `json:"mentioned_at"`
+}
+
+// UserProfile is the public part of a user's account
+type UserProfile struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Avatar    string `json:"avatar,omitempty"`
+}
+
+// FollowUser is a user in a follower or following list
+type FollowUser struct {
+	UserProfile
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowStats counts a user's followers and followed users. Followed tells
+// whether the requesting user follows them.
+type FollowStats struct {
+	Followers int64 `json:"followers"`
+	Following int64 `json:"following"`
+	Followed  bool  `json:"followed"`
+}
+
+// FollowRequest represents the request payload for following a user
+type FollowRequest struct {
+	Notify bool `json:"notify"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a free-form label attached to posts
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  *uint     `json:"tenant_id,omitempty" gorm:"uniqueIndex:idx_tag_tenant_slug"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	Slug      string    `json:"slug" gorm:"size:100;not null;uniqueIndex:idx_tag_tenant_slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// PostCount is the number of published posts with the tag, when counted
+	PostCount *int64 `json:"post_count,omitempty" gorm:"-"`
+}
+
+// Category is a node in the hierarchical category tree posts are filed under
+type Category struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	TenantID    *uint     `json:"tenant_id,omitempty" gorm:"uniqueIndex:idx_category_tenant_slug"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Slug        string    `json:"slug" gorm:"size:100;not null;uniqueIndex:idx_category_tenant_slug"`
+	Description string    `json:"description,omitempty"`
+	ParentID    *uint     `json:"parent_id,omitempty" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Children are the categories directly below this one, when loaded as a tree
+	Children []Category `json:"children,omitempty" gorm:"-"`
+}
+
+// TagCreateRequest represents the request payload for creating a tag
+type TagCreateRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+// TagUpdateRequest represents the request payload for renaming a tag
+type TagUpdateRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+// TagMergeRequest represents the request payload for merging tags into
+// another tag
+type TagMergeRequest struct {
+	SourceIDs []uint `json:"source_ids" validate:"required,min=1,max=100"`
+	TargetID  uint   `json:"target_id" validate:"required"`
+}
+
+// PostTagsRequest represents the request payload for setting a post's tags.
+// Tags that do not exist yet are created.
+type PostTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,min=1,max=50"`
+}
+
+// CategoryCreateRequest represents the request payload for creating a category
+type CategoryCreateRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
+	ParentID    *uint  `json:"parent_id,omitempty"`
+}
+
+// CategoryUpdateRequest represents the request payload for updating a
+// category. A parent ID of 0 moves the category to the top level.
+type CategoryUpdateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	ParentID    *uint   `json:"parent_id,omitempty"`
+}
+
+// PostCategoriesRequest represents the request payload for setting a post's
+// categories
+type PostCategoriesRequest struct {
+	CategoryIDs []uint `json:"category_ids" validate:"max=10"`
+}
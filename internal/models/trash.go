@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// TrashedItem is a deleted post, comment, file or user waiting in the trash
+// to be restored or purged
+type TrashedItem[T any] struct {
+	Item      T         `json:"item"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the item will be purged for good, unless the trash
+	// keeps items until they are purged by hand
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
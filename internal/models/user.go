@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -16,6 +18,65 @@ const (
 	RoleUser      Role = "user"
 )
 
+// roleParents maps every known role to the role it inherits from. It starts
+// with the built-in hierarchy and is replaced from the roles table at startup.
+var (
+	roleParentsMu sync.RWMutex
+	roleParents   = map[Role]Role{
+		RoleAdmin:     RoleModerator,
+		RoleModerator: RoleUser,
+		RoleUser:      "",
+	}
+)
+
+// SetRoleHierarchy replaces the known roles and their parent roles
+func SetRoleHierarchy(parents map[Role]Role) {
+	hierarchy := make(map[Role]Role, len(parents))
+	for role, parent := range parents {
+		hierarchy[role] = parent
+	}
+
+	roleParentsMu.Lock()
+	roleParents = hierarchy
+	roleParentsMu.Unlock()
+}
+
+// IsValid checks if the role is one of the known roles
+func (r Role) IsValid() bool {
+	roleParentsMu.RLock()
+	defer roleParentsMu.RUnlock()
+
+	_, exists := roleParents[r]
+	return exists
+}
+
+// Ancestors returns the role followed by every role it inherits from
+func (r Role) Ancestors() []Role {
+	roleParentsMu.RLock()
+	defer roleParentsMu.RUnlock()
+
+	var roles []Role
+	seen := make(map[Role]bool)
+	for role := r; role != "" && !seen[role]; role = roleParents[role] {
+		if _, exists := roleParents[role]; !exists {
+			break
+		}
+		seen[role] = true
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// Includes checks if the role is the other role or inherits from it
+func (r Role) Includes(other Role) bool {
+	for _, role := range r.Ancestors() {
+		if role == other {
+			return true
+		}
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
 	ID        uint   `json:"id" gorm:"primaryKey"`
@@ -24,7 +85,7 @@ type User struct {
 	Password  string `json:"-" gorm:"not null" validate:"required,min=6"`
 	FirstName string `json:"first_name" gorm:"not null" validate:"required,min=1,max=50"`
 	LastName  string `json:"last_name" gorm:"not null" validate:"required,min=1,max=50"`
-	Role      Role   `json:"role" gorm:"not null;default:'user'" validate:"required,oneof=admin moderator user"`
+	Role      Role   `json:"role" gorm:"not null;default:'user'" validate:"required,role"`
 	IsActive  bool   `json:"is_active" gorm:"default:true"`
 
 	// Enhanced security fields
@@ -44,12 +105,20 @@ type User struct {
 	PasswordChangedAt   *time.Time `json:"-"`
 	MustChangePassword  bool       `json:"-" gorm:"default:false"`
 	TwoFactorEnabled    bool       `json:"two_factor_enabled" gorm:"default:false"`
+	SuspendedUntil      *time.Time `json:"suspended_until,omitempty"`
+
+	// Notification preferences
+	MentionNotifications MentionSetting `json:"mention_notifications" gorm:"size:20;default:'in_app'"`
 
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Version is bumped on every change and served as the ETag, so
+	// concurrent edits do not overwrite each other
+	Version uint `json:"version" gorm:"not null;default:1"`
+
 	// Relationships
 	Posts              []Post              `json:"posts,omitempty" gorm:"foreignKey:UserID"`
 	Comments           []Comment           `json:"comments,omitempty" gorm:"foreignKey:UserID"`
@@ -69,7 +138,7 @@ type UserCreateRequest struct {
 	Password  string `json:"password" validate:"required,min=6"`
 	FirstName string `json:"first_name" validate:"required,min=1,max=50"`
 	LastName  string `json:"last_name" validate:"required,min=1,max=50"`
-	Role      Role   `json:"role,omitempty" validate:"omitempty,oneof=admin moderator user"`
+	Role      Role   `json:"role,omitempty" validate:"omitempty,role"`
 }
 
 // UserUpdateRequest represents the request payload for updating a user
@@ -78,30 +147,35 @@ type UserUpdateRequest struct {
 	Username  *string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=1,max=50"`
 	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=1,max=50"`
-	Role      *Role   `json:"role,omitempty" validate:"omitempty,oneof=admin moderator user"`
+	Role      *Role   `json:"role,omitempty" validate:"omitempty,role"`
 	IsActive  *bool   `json:"is_active,omitempty"`
+
+	MentionNotifications *MentionSetting `json:"mention_notifications,omitempty" validate:"omitempty,oneof=off in_app email all"`
 }
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
-	ID               uint       `json:"id"`
-	Email            string     `json:"email"`
-	Username         string     `json:"username"`
-	FirstName        string     `json:"first_name"`
-	LastName         string     `json:"last_name"`
-	Role             Role       `json:"role"`
-	IsActive         bool       `json:"is_active"`
-	EmailVerified    bool       `json:"email_verified"`
-	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
-	PhoneNumber      string     `json:"phone_number,omitempty"`
-	PhoneVerified    bool       `json:"phone_verified"`
-	Avatar           string     `json:"avatar,omitempty"`
-	Timezone         string     `json:"timezone"`
-	Language         string     `json:"language"`
-	TwoFactorEnabled bool       `json:"two_factor_enabled"`
-	LastLoginAt      *time.Time `json:"last_login_at,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	ID                   uint           `json:"id"`
+	Email                string         `json:"email"`
+	Username             string         `json:"username"`
+	FirstName            string         `json:"first_name"`
+	LastName             string         `json:"last_name"`
+	Role                 Role           `json:"role"`
+	IsActive             bool           `json:"is_active"`
+	EmailVerified        bool           `json:"email_verified"`
+	EmailVerifiedAt      *time.Time     `json:"email_verified_at,omitempty"`
+	PhoneNumber          string         `json:"phone_number,omitempty"`
+	PhoneVerified        bool           `json:"phone_verified"`
+	Avatar               string         `json:"avatar,omitempty"`
+	Timezone             string         `json:"timezone"`
+	Language             string         `json:"language"`
+	TwoFactorEnabled     bool           `json:"two_factor_enabled"`
+	LastLoginAt          *time.Time     `json:"last_login_at,omitempty"`
+	SuspendedUntil       *time.Time     `json:"suspended_until,omitempty"`
+	MentionNotifications MentionSetting `json:"mention_notifications"`
+	Version              uint           `json:"version"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
 }
 
 // LoginRequest represents the request payload for user login
@@ -116,9 +190,46 @@ type LoginResponse struct {
 	User  UserResponse `json:"user"`
 }
 
+// PostStatus is the editorial state of a post
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusInReview  PostStatus = "in_review"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
+
+// PostStatusTransitions lists the statuses a post can move to from each status
+var PostStatusTransitions = map[PostStatus][]PostStatus{
+	PostStatusDraft:     {PostStatusInReview, PostStatusScheduled, PostStatusPublished},
+	PostStatusInReview:  {PostStatusDraft, PostStatusScheduled, PostStatusPublished},
+	PostStatusScheduled: {PostStatusDraft, PostStatusPublished},
+	PostStatusPublished: {PostStatusDraft, PostStatusArchived},
+	PostStatusArchived:  {PostStatusDraft, PostStatusPublished},
+}
+
+// IsValid checks if the post status is valid
+func (s PostStatus) IsValid() bool {
+	_, ok := PostStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo checks if a post can move from this status to another
+func (s PostStatus) CanTransitionTo(to PostStatus) bool {
+	for _, allowed := range PostStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 // Post represents a blog post or article
 type Post struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  *uint          `json:"tenant_id,omitempty" gorm:"index"`
 	Title     string         `json:"title" gorm:"not null" validate:"required,min=1,max=200"`
 	Content   string         `json:"content" gorm:"type:text" validate:"required,min=1"`
 	Slug      string         `json:"slug" gorm:"uniqueIndex;not null"`
@@ -128,28 +239,156 @@ type Post struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// DeletedBy is who moved the post to the trash. Authors can only restore
+	// what they deleted themselves, not what a moderator removed.
+	DeletedBy *uint `json:"-"`
+
+	// Version is bumped on every change and served as the ETag, so
+	// concurrent edits do not overwrite each other
+	Version uint `json:"version" gorm:"not null;default:1"`
+
+	// Content is Markdown. ContentHTML caches it rendered and sanitized by
+	// the rendering rules of RenderVersion.
+	ContentHTML   string `json:"content_html" gorm:"type:text"`
+	RenderVersion int    `json:"-" gorm:"default:0"`
+
+	// Editorial workflow. Published mirrors Status == published.
+	Status      PostStatus `json:"status" gorm:"size:20;not null;default:'draft';index"`
+	PublishAt   *time.Time `json:"publish_at,omitempty" gorm:"index"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+
+	// CommentsRequireApproval holds new comments by other users for moderation
+	CommentsRequireApproval bool `json:"comments_require_approval" gorm:"default:false"`
+
+	// Engagement. Reactions holds the count of each emoji, when loaded.
+	ViewCount int64            `json:"view_count" gorm:"default:0;index"`
+	Reactions map[string]int64 `json:"reactions,omitempty" gorm:"-"`
+
 	// Relationships
-	User     User      `json:"user" gorm:"foreignKey:UserID"`
-	Comments []Comment `json:"comments,omitempty" gorm:"foreignKey:PostID"`
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+	Comments   []Comment  `json:"comments,omitempty" gorm:"foreignKey:PostID"`
+	Tags       []Tag      `json:"tags,omitempty" gorm:"many2many:post_tags"`
+	Categories []Category `json:"categories,omitempty" gorm:"many2many:post_categories"`
+}
+
+// MarshalJSON serializes the post with its author's public profile rather
+// than their account
+func (p Post) MarshalJSON() ([]byte, error) {
+	type post Post
+	return json.Marshal(struct {
+		post
+		User *UserProfile `json:"user,omitempty"`
+	}{post(p), authorProfile(&p.User)})
 }
 
 // PostCreateRequest represents the request payload for creating a post
 type PostCreateRequest struct {
-	Title     string `json:"title" validate:"required,min=1,max=200"`
-	Content   string `json:"content" validate:"required,min=1"`
-	Published bool   `json:"published,omitempty"`
+	Title   string `json:"title" validate:"required,min=1,max=200"`
+	Content string `json:"content" validate:"required,min=1"`
+	Slug    string `json:"slug,omitempty" validate:"omitempty,max=100"`
+
+	// Published publishes the post right away.
+	//
+	// Deprecated: set Status instead. Only users who may publish posts, such
+	// as moderators, can use it; authors submit posts for review.
+	Published bool `json:"published,omitempty"`
+
+	// Status defaults to published if Published is set and draft otherwise
+	Status    PostStatus `json:"status,omitempty" validate:"omitempty,oneof=draft in_review scheduled published"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	CommentsRequireApproval bool `json:"comments_require_approval,omitempty"`
 }
 
 // PostUpdateRequest represents the request payload for updating a post
 type PostUpdateRequest struct {
-	Title     *string `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
-	Content   *string `json:"content,omitempty" validate:"omitempty,min=1"`
-	Published *bool   `json:"published,omitempty"`
+	Title   *string `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Content *string `json:"content,omitempty" validate:"omitempty,min=1"`
+	Slug    *string `json:"slug,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// Published publishes the post or moves it back to draft.
+	//
+	// Deprecated: use the status endpoint instead. Only users who may
+	// publish posts, such as moderators, can set it to true.
+	Published *bool `json:"published,omitempty"`
+
+	CommentsRequireApproval *bool `json:"comments_require_approval,omitempty"`
+}
+
+// RenderPreviewRequest represents the request payload for previewing
+// rendered Markdown
+type RenderPreviewRequest struct {
+	Content string `json:"content" validate:"required,max=100000"`
+}
+
+// PostStatusChangeRequest represents the request payload for moving a post
+// through the editorial workflow
+type PostStatusChangeRequest struct {
+	Status    PostStatus `json:"status" validate:"required,oneof=draft in_review scheduled published archived"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+// PostSlugRedirect keeps a post's previous slug so links to it keep working
+type PostSlugRedirect struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null"`
+	PostID    uint      `json:"post_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostRevision is a snapshot of a post's title and content after a change
+type PostRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PostID    uint      `json:"post_id" gorm:"not null;uniqueIndex:idx_post_revision_number"`
+	Number    int       `json:"number" gorm:"not null;uniqueIndex:idx_post_revision_number"`
+	Title     string    `json:"title" gorm:"not null"`
+	Content   string    `json:"content,omitempty" gorm:"type:text"`
+	EditorID  uint      `json:"editor_id" gorm:"not null"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Editor *User `json:"editor,omitempty" gorm:"foreignKey:EditorID"`
+}
+
+// MarshalJSON serializes the revision with its editor's public profile
+// rather than their account
+func (r PostRevision) MarshalJSON() ([]byte, error) {
+	type postRevision PostRevision
+	return json.Marshal(struct {
+		postRevision
+		Editor *UserProfile `json:"editor,omitempty"`
+	}{postRevision(r), authorProfile(r.Editor)})
+}
+
+// PostBulkDeleteRequest represents the request payload for deleting posts in bulk
+type PostBulkDeleteRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1,max=100"`
+}
+
+// CommentStatus is the moderation state of a comment
+type CommentStatus string
+
+const (
+	CommentStatusPending  CommentStatus = "pending"
+	CommentStatusApproved CommentStatus = "approved"
+	CommentStatusSpam     CommentStatus = "spam"
+	CommentStatusRejected CommentStatus = "rejected"
+)
+
+// IsValid checks if the comment status is valid
+func (s CommentStatus) IsValid() bool {
+	switch s {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusSpam, CommentStatusRejected:
+		return true
+	}
+	return false
 }
 
 // Comment represents a comment on a post
 type Comment struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	TenantID  *uint          `json:"tenant_id,omitempty" gorm:"index"`
 	Content   string         `json:"content" gorm:"type:text;not null" validate:"required,min=1"`
 	UserID    uint           `json:"user_id" gorm:"not null"`
 	PostID    uint           `json:"post_id" gorm:"not null"`
@@ -157,15 +396,63 @@ type Comment struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// DeletedBy is who moved the comment to the trash. Authors can only restore
+	// what they deleted themselves, not what a moderator removed.
+	DeletedBy *uint `json:"-"`
+
+	// Version is bumped on every change and served as the ETag, so
+	// concurrent edits do not overwrite each other
+	Version uint `json:"version" gorm:"not null;default:1"`
+
+	// Content is Markdown. ContentHTML caches it rendered and sanitized by
+	// the rendering rules of RenderVersion.
+	ContentHTML   string `json:"content_html" gorm:"type:text"`
+	RenderVersion int    `json:"-" gorm:"default:0"`
+
+	// Threading
+	ParentID *uint `json:"parent_id,omitempty" gorm:"index"`
+	Depth    int   `json:"depth" gorm:"default:0"`
+
+	// Moderation
+	Status      CommentStatus `json:"status" gorm:"size:20;not null;default:'approved';index"`
+	ModeratedBy *uint         `json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time    `json:"moderated_at,omitempty"`
+	EditedAt    *time.Time    `json:"edited_at,omitempty"`
+
+	// Reactions holds the count of each emoji, when loaded
+	Reactions map[string]int64 `json:"reactions,omitempty" gorm:"-"`
+
 	// Relationships
-	User User `json:"user" gorm:"foreignKey:UserID"`
-	Post Post `json:"post" gorm:"foreignKey:PostID"`
+	User    User      `json:"user" gorm:"foreignKey:UserID"`
+	Post    *Post     `json:"post,omitempty" gorm:"foreignKey:PostID"`
+	Replies []Comment `json:"replies,omitempty" gorm:"-"`
+}
+
+// MarshalJSON serializes the comment with its author's public profile
+// rather than their account
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type comment Comment
+	return json.Marshal(struct {
+		comment
+		User *UserProfile `json:"user,omitempty"`
+	}{comment(c), authorProfile(&c.User)})
+}
+
+// authorProfile returns the public profile of an author, or nil if the
+// author was not loaded
+func authorProfile(user *User) *UserProfile {
+	if user == nil || user.ID == 0 {
+		return nil
+	}
+	profile := user.ToProfile()
+	return &profile
 }
 
 // CommentCreateRequest represents the request payload for creating a comment
 type CommentCreateRequest struct {
-	Content string `json:"content" validate:"required,min=1"`
-	PostID  uint   `json:"post_id" validate:"required"`
+	Content  string `json:"content" validate:"required,min=1"`
+	PostID   uint   `json:"post_id" validate:"required"`
+	ParentID *uint  `json:"parent_id,omitempty"`
 }
 
 // CommentUpdateRequest represents the request payload for updating a comment
@@ -173,6 +460,12 @@ type CommentUpdateRequest struct {
 	Content *string `json:"content,omitempty" validate:"omitempty,min=1"`
 }
 
+// CommentBulkModerateRequest represents the request payload for moderating comments in bulk
+type CommentBulkModerateRequest struct {
+	IDs    []uint        `json:"ids" validate:"required,min=1,max=100"`
+	Status CommentStatus `json:"status" validate:"required,oneof=approved spam rejected"`
+}
+
 // BeforeCreate hook for User model
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	// Hash password before saving
@@ -201,54 +494,59 @@ func (u *User) CheckPassword(password string) bool {
 // ToResponse converts User model to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:               u.ID,
-		Email:            u.Email,
-		Username:         u.Username,
-		FirstName:        u.FirstName,
-		LastName:         u.LastName,
-		Role:             u.Role,
-		IsActive:         u.IsActive,
-		EmailVerified:    u.EmailVerified,
-		EmailVerifiedAt:  u.EmailVerifiedAt,
-		PhoneNumber:      u.PhoneNumber,
-		PhoneVerified:    u.PhoneVerified,
-		Avatar:           u.Avatar,
-		Timezone:         u.Timezone,
-		Language:         u.Language,
-		TwoFactorEnabled: u.TwoFactorEnabled,
-		LastLoginAt:      u.LastLoginAt,
-		CreatedAt:        u.CreatedAt,
-		UpdatedAt:        u.UpdatedAt,
+		ID:                   u.ID,
+		Email:                u.Email,
+		Username:             u.Username,
+		FirstName:            u.FirstName,
+		LastName:             u.LastName,
+		Role:                 u.Role,
+		IsActive:             u.IsActive,
+		EmailVerified:        u.EmailVerified,
+		EmailVerifiedAt:      u.EmailVerifiedAt,
+		PhoneNumber:          u.PhoneNumber,
+		PhoneVerified:        u.PhoneVerified,
+		Avatar:               u.Avatar,
+		Timezone:             u.Timezone,
+		Language:             u.Language,
+		TwoFactorEnabled:     u.TwoFactorEnabled,
+		LastLoginAt:          u.LastLoginAt,
+		SuspendedUntil:       u.SuspendedUntil,
+		MentionNotifications: u.MentionNotifications,
+		Version:              u.Version,
+		CreatedAt:            u.CreatedAt,
+		UpdatedAt:            u.UpdatedAt,
+	}
+}
+
+// ToProfile converts a user to their public profile
+func (u *User) ToProfile() UserProfile {
+	return UserProfile{
+		ID:        u.ID,
+		Username:  u.Username,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Avatar:    u.Avatar,
 	}
 }
 
-// IsAdmin checks if the user has admin role
+// IsAdmin checks if the user has admin role (directly or through inheritance)
 func (u *User) IsAdmin() bool {
-	return u.Role == RoleAdmin
+	return u.Role.Includes(RoleAdmin)
 }
 
-// IsModerator checks if the user has moderator role
+// IsModerator checks if the user has moderator role but is not an admin
 func (u *User) IsModerator() bool {
-	return u.Role == RoleModerator
+	return u.Role.Includes(RoleModerator) && !u.IsAdmin()
 }
 
-// CanModerate checks if the user can moderate (admin or moderator)
+// CanModerate checks if the user can moderate (any role inheriting moderator)
 func (u *User) CanModerate() bool {
-	return u.Role == RoleAdmin || u.Role == RoleModerator
+	return u.Role.Includes(RoleModerator)
 }
 
-// HasPermission checks if the user has specific permissions
+// HasPermission checks if the user's role is or inherits from the required role
 func (u *User) HasPermission(requiredRole Role) bool {
-	switch requiredRole {
-	case RoleAdmin:
-		return u.Role == RoleAdmin
-	case RoleModerator:
-		return u.Role == RoleAdmin || u.Role == RoleModerator
-	case RoleUser:
-		return true // All authenticated users have user permission
-	default:
-		return false
-	}
+	return u.Role.Includes(requiredRole)
 }
 
 // Security-related methods
@@ -261,6 +559,14 @@ func (u *User) IsAccountLocked() bool {
 	return time.Now().Before(*u.AccountLockedUntil)
 }
 
+// IsSuspended checks if a moderator has suspended the account
+func (u *User) IsSuspended() bool {
+	if u.SuspendedUntil == nil {
+		return false
+	}
+	return time.Now().Before(*u.SuspendedUntil)
+}
+
 // LockAccount locks the user account for the specified duration
 func (u *User) LockAccount(duration time.Duration) {
 	lockUntil := time.Now().Add(duration)
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Count returns how many words of text match a word of the query
+func (q *Query) Count(text string) int {
+	return len(q.spans([]rune(text)))
+}
+
+// Mark returns text as HTML with the words matching the query wrapped in
+// <mark> tags. Text longer than width runes is cut to a window around the
+// first match; a width of 0 keeps all of it.
+func (q *Query) Mark(text string, width int) string {
+	runes := []rune(text)
+	spans := q.spans(runes)
+
+	start, end := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		if len(spans) > 0 {
+			start = max(spans[0][0]-width/4, 0)
+		}
+		end = min(start+width, len(runes))
+		start = max(end-width, 0)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, span := range spans {
+		if span[0] < start || span[1] > end {
+			continue
+		}
+		b.WriteString(string(runes[pos:span[0]]))
+		b.WriteString(markStart)
+		b.WriteString(string(runes[span[0]:span[1]]))
+		b.WriteString(markEnd)
+		pos = span[1]
+	}
+	b.WriteString(string(runes[pos:end]))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return markHTML(b.String())
+}
+
+// spans returns the start and end of each word in runes matching the query
+func (q *Query) spans(runes []rune) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		if q.matchesWord(strings.ToLower(string(runes[i:j]))) {
+			spans = append(spans, [2]int{i, j})
+		}
+		i = j
+	}
+	return spans
+}
+
+// matchesWord checks if a lowercase word matches any word of the query. The
+// last word of a prefix term matches words starting with it.
+func (q *Query) matchesWord(word string) bool {
+	for _, term := range q.Terms {
+		for i, w := range term.Words {
+			if word == w || (term.Prefix && i == len(term.Words)-1 && strings.HasPrefix(word, w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWordRune checks if r can be part of a searchable word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// postgresIndex indexes posts in a generated tsvector column with a GIN
+// index. Postgres keeps the column in sync on every write.
+type postgresIndex struct{}
+
+// postgresConfig is the text search configuration used for stemming
+const postgresConfig = "english"
+
+// postgresSchema adds the tsvector column and its index. Title words weigh
+// more than content words when ranking.
+var postgresSchema = []string{
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('` + postgresConfig + `', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('` + postgresConfig + `', coalesce(content, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector)`,
+}
+
+// Highlight options for ts_headline
+const (
+	postgresTitleOptions   = "StartSel=" + markStart + ", StopSel=" + markEnd + ", HighlightAll=true"
+	postgresSnippetOptions = "StartSel=" + markStart + ", StopSel=" + markEnd +
+		`, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+func (postgresIndex) Migrate(db *gorm.DB) error {
+	for _, statement := range postgresSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("search: failed to create index: %w", err)
+		}
+	}
+	return nil
+}
+
+func (postgresIndex) Match(db *gorm.DB, q *Query) *gorm.DB {
+	return db.Where("posts.search_vector @@ to_tsquery('"+postgresConfig+"', ?)", q.tsquery())
+}
+
+func (postgresIndex) Rank(db *gorm.DB, q *Query) *gorm.DB {
+	tsquery := q.tsquery()
+	return db.Select(`posts.id AS id,
+		ts_rank_cd(posts.search_vector, to_tsquery('`+postgresConfig+`', ?)) AS search_rank,
+		ts_headline('`+postgresConfig+`', posts.title, to_tsquery('`+postgresConfig+`', ?), ?) AS search_title,
+		ts_headline('`+postgresConfig+`', posts.content, to_tsquery('`+postgresConfig+`', ?), ?) AS search_snippet`,
+		tsquery, tsquery, postgresTitleOptions, tsquery, postgresSnippetOptions).
+		Order("search_rank DESC, posts.id DESC")
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// MaxTerms is the largest number of terms and phrases a query may have
+const MaxTerms = 16
+
+var (
+	// ErrEmptyQuery is returned for queries without any searchable words
+	ErrEmptyQuery = errors.New("search query must contain letters or digits")
+
+	// ErrTooManyTerms is returned for queries with more than MaxTerms terms
+	ErrTooManyTerms = errors.New("search query has too many terms")
+)
+
+// Term is a word or, when it has several words, a phrase whose words must
+// appear next to each other. A prefix term also matches words starting with
+// its last word.
+type Term struct {
+	Words  []string
+	Prefix bool
+}
+
+// Query is a parsed search query. A post matches when it matches every term.
+type Query struct {
+	Terms []Term
+}
+
+// Parse parses a search query. Words match anywhere in the title or content,
+// "quoted words" match as a phrase and a trailing * matches by prefix, as in
+// go* or "web frame*". Punctuation separates words and is otherwise ignored.
+func Parse(input string) (*Query, error) {
+	q := &Query{}
+	rest := strings.TrimSpace(input)
+
+	for rest != "" {
+		var raw string
+		if rest[0] == '"' {
+			// A phrase runs to the closing quote, or to the end when unclosed
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				raw, rest = rest[1:], ""
+			} else {
+				raw, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			raw, rest = rest[:end], rest[end:]
+		}
+
+		prefix := false
+		if strings.HasPrefix(rest, "*") {
+			prefix, rest = true, rest[1:]
+		}
+		if strings.HasSuffix(raw, "*") {
+			prefix = true
+		}
+		rest = strings.TrimSpace(rest)
+
+		words := strings.FieldsFunc(raw, func(r rune) bool {
+			return !isWordRune(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+		q.Terms = append(q.Terms, Term{Words: words, Prefix: prefix})
+	}
+
+	if len(q.Terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if len(q.Terms) > MaxTerms {
+		return nil, ErrTooManyTerms
+	}
+	return q, nil
+}
+
+// Like restricts db to rows where, for every term, one of the columns
+// contains the term's words. It serves data without a full-text index.
+func (q *Query) Like(db *gorm.DB, columns ...string) *gorm.DB {
+	for _, term := range q.Terms {
+		// Words hold only letters and digits, so the pattern needs no escaping
+		pattern := "%" + strings.Join(term.Words, " ") + "%"
+		conditions := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			conditions[i] = "LOWER(" + column + ") LIKE ?"
+			args[i] = pattern
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	return db
+}
+
+// fts5 renders the query in SQLite FTS5 syntax
+func (q *Query) fts5() string {
+	parts := make([]string, len(q.Terms))
+	for i, term := range q.Terms {
+		// Words hold only letters and digits, so quoting needs no escaping
+		parts[i] = `"` + strings.Join(term.Words, " ") + `"`
+		if term.Prefix {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// tsquery renders the query in Postgres tsquery syntax
+func (q *Query) tsquery() string {
+	parts := make([]string, len(q.Terms))
+	for i, term := range q.Terms {
+		words := make([]string, len(term.Words))
+		copy(words, term.Words)
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		parts[i] = "(" + strings.Join(words, " <-> ") + ")"
+	}
+	return strings.Join(parts, " & ")
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Term
+	}{
+		{"word", "go", []Term{{Words: []string{"go"}}}},
+		{"words are lowercased", "  Go   Web ", []Term{{Words: []string{"go"}}, {Words: []string{"web"}}}},
+		{"phrase", `"web framework"`, []Term{{Words: []string{"web", "framework"}}}},
+		{"unclosed phrase", `"web framework`, []Term{{Words: []string{"web", "framework"}}}},
+		{"prefix", "frame*", []Term{{Words: []string{"frame"}, Prefix: true}}},
+		{"prefix phrase", `"web frame*"`, []Term{{Words: []string{"web", "frame"}, Prefix: true}}},
+		{"prefix after phrase", `"web frame"* go`, []Term{{Words: []string{"web", "frame"}, Prefix: true}, {Words: []string{"go"}}}},
+		{"punctuation splits words", "don't", []Term{{Words: []string{"don", "t"}}}},
+		{"punctuation is dropped", "c++ & rust!", []Term{{Words: []string{"c"}}, {Words: []string{"rust"}}}},
+		{"operators are words", "go OR NOT rust", []Term{{Words: []string{"go"}}, {Words: []string{"or"}}, {Words: []string{"not"}}, {Words: []string{"rust"}}}},
+		{"syntax is stripped", `title:go -rust NEAR(a b) "x" | y`, []Term{
+			{Words: []string{"title", "go"}},
+			{Words: []string{"rust"}},
+			{Words: []string{"near", "a"}},
+			{Words: []string{"b"}},
+			{Words: []string{"x"}},
+			{Words: []string{"y"}},
+		}},
+		{"unicode", "Crème brûlée", []Term{{Words: []string{"crème"}}, {Words: []string{"brûlée"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, q.Terms)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"", "   ", `""`, "*", "!?& -- :"} {
+		_, err := Parse(input)
+		assert.ErrorIs(t, err, ErrEmptyQuery, "input %q", input)
+	}
+
+	words := strings.Repeat("go ", MaxTerms)
+	_, err := Parse(words)
+	assert.NoError(t, err)
+	_, err = Parse(words + "go")
+	assert.ErrorIs(t, err, ErrTooManyTerms)
+}
+
+func TestQuerySyntax(t *testing.T) {
+	tests := []struct {
+		input   string
+		fts5    string
+		tsquery string
+	}{
+		{"go", `"go"`, "(go)"},
+		{"go web", `"go" AND "web"`, "(go) & (web)"},
+		{`"web framework"`, `"web framework"`, "(web <-> framework)"},
+		{"frame*", `"frame"*`, "(frame:*)"},
+		{`"web frame*" go`, `"web frame"* AND "go"`, "(web <-> frame:*) & (go)"},
+		{`say "hi" OR x:y`, `"say" AND "hi" AND "or" AND "x y"`, "(say) & (hi) & (or) & (x <-> y)"},
+		{`a' OR '1'='1`, `"a" AND "or" AND "1 1"`, "(a) & (or) & (1 <-> 1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.fts5, q.fts5())
+			assert.Equal(t, tt.tsquery, q.tsquery())
+		})
+	}
+}
+
+func TestQueryTsqueryKeepsTerms(t *testing.T) {
+	q, err := Parse(`"web frame*"`)
+	require.NoError(t, err)
+	q.tsquery()
+	assert.Equal(t, []string{"web", "frame"}, q.Terms[0].Words)
+}
@@ -0,0 +1,74 @@
+// Package search provides full-text search over post titles and content,
+// backed by FTS5 on SQLite and tsvector with a GIN index on Postgres.
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Markers delimit matched text in raw highlights. They are private use
+// characters, which post content has no reason to contain, and are turned
+// into <mark> tags once the surrounding text is escaped.
+const (
+	markStart = "\uE000"
+	markEnd   = "\uE001"
+)
+
+// Index is a full-text index over posts
+type Index interface {
+	// Migrate creates the index and the machinery that keeps it in sync as
+	// posts are created, updated and deleted
+	Migrate(db *gorm.DB) error
+
+	// Match restricts a query on the posts table to posts matching q
+	Match(db *gorm.DB, q *Query) *gorm.DB
+
+	// Rank selects the ID, rank and raw highlights of the posts matched by
+	// Match, best match first
+	Rank(db *gorm.DB, q *Query) *gorm.DB
+}
+
+// NewIndex returns the index for the database dialect
+func NewIndex(dialect string) (Index, error) {
+	switch dialect {
+	case "sqlite":
+		return sqliteIndex{}, nil
+	case "postgres":
+		return postgresIndex{}, nil
+	default:
+		return nil, fmt.Errorf("search: unsupported database dialect: %s", dialect)
+	}
+}
+
+// Hit is a post matched by a search, as scanned from a Rank query
+type Hit struct {
+	ID      uint    `gorm:"column:id"`
+	Rank    float64 `gorm:"column:search_rank"`
+	Title   string  `gorm:"column:search_title"`
+	Snippet string  `gorm:"column:search_snippet"`
+}
+
+// Highlight holds HTML excerpts of a matched post with the matching terms
+// wrapped in <mark> tags. Everything else is escaped.
+type Highlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// Highlight renders the hit's raw highlights as HTML
+func (h Hit) Highlight() Highlight {
+	return Highlight{
+		Title:   markHTML(h.Title),
+		Snippet: markHTML(h.Snippet),
+	}
+}
+
+// markHTML escapes text and turns highlight markers into <mark> tags
+func markHTML(text string) string {
+	escaped := html.EscapeString(text)
+	return strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>").Replace(escaped)
+}
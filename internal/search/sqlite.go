@@ -0,0 +1,74 @@
+package search
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// sqliteIndex indexes posts in an external content FTS5 table, kept in sync
+// with the posts table by triggers
+type sqliteIndex struct{}
+
+// sqliteSchema creates the FTS5 table and its sync triggers, then indexes
+// any posts written while they were missing. GORM's SQLite migrator may
+// recreate the posts table, which drops the triggers, so every statement is
+// safe to run again.
+var sqliteSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+		title, content,
+		content = 'posts', content_rowid = 'id',
+		tokenize = 'porter unicode61 remove_diacritics 2'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+		INSERT INTO posts_fts (rowid, title, content) VALUES (new.id, new.title, new.content);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+		INSERT INTO posts_fts (posts_fts, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF title, content ON posts BEGIN
+		INSERT INTO posts_fts (posts_fts, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+		INSERT INTO posts_fts (rowid, title, content) VALUES (new.id, new.title, new.content);
+	END`,
+	`INSERT INTO posts_fts (posts_fts) VALUES ('rebuild')`,
+}
+
+// sqliteObjects are the schema objects of the index
+var sqliteObjects = []string{"posts_fts", "posts_fts_insert", "posts_fts_delete", "posts_fts_update"}
+
+func (sqliteIndex) Migrate(db *gorm.DB) error {
+	var count int64
+	err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name IN ?", sqliteObjects).
+		Scan(&count).Error
+	if err != nil {
+		return fmt.Errorf("search: failed to inspect schema: %w", err)
+	}
+	if count == int64(len(sqliteObjects)) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range sqliteSchema {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("search: failed to create index: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (sqliteIndex) Match(db *gorm.DB, q *Query) *gorm.DB {
+	return db.Joins("JOIN posts_fts ON posts_fts.rowid = posts.id").
+		Where("posts_fts MATCH ?", q.fts5())
+}
+
+func (sqliteIndex) Rank(db *gorm.DB, q *Query) *gorm.DB {
+	// bm25 scores better matches lower, so negate it for a higher-is-better
+	// rank. Weights are per column: title, then content.
+	return db.Select(`posts.id AS id,
+		-bm25(posts_fts, 5.0, 1.0) AS search_rank,
+		highlight(posts_fts, 0, ?, ?) AS search_title,
+		snippet(posts_fts, 1, ?, ?, '…', 24) AS search_snippet`,
+		markStart, markEnd, markStart, markEnd).
+		Order("search_rank DESC, posts.id DESC")
+}
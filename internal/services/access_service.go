@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AccessService answers access checks for the current user against the
+// authorization policy, using the same checks as the other services
+type AccessService struct {
+	db *gorm.DB
+}
+
+// AccessResult is the outcome of a single access check
+type AccessResult struct {
+	models.AccessCheck
+	Allowed bool   `json:"allowed"`
+	Error   string `json:"error,omitempty"`
+	// Decision is only included when the policy engine runs in debug mode
+	Decision *authz.Decision `json:"decision,omitempty"`
+}
+
+// NewAccessService creates a new access service
+func NewAccessService(db *gorm.DB) *AccessService {
+	return &AccessService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *AccessService) WithContext(ctx context.Context) *AccessService {
+	return &AccessService{db: s.db.WithContext(ctx)}
+}
+
+// CheckAll evaluates each check for the user. Checks on resources that do not
+// exist are denied.
+func (s *AccessService) CheckAll(userID uint, checks []models.AccessCheck) ([]AccessResult, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	ctx := s.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	results := make([]AccessResult, len(checks))
+	for i, check := range checks {
+		results[i].AccessCheck = check
+
+		resource, err := s.loadResource(check)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		decision := authz.Explain(ctx, &user, check.Action, resource)
+		results[i].Allowed = decision.Allowed
+		if authz.Default().Debug() {
+			results[i].Decision = &decision
+		}
+	}
+
+	return results, nil
+}
+
+// loadResource loads the resource a check refers to, or the resource type
+// when no ID is given
+func (s *AccessService) loadResource(check models.AccessCheck) (interface{}, error) {
+	if check.ResourceID == nil {
+		return authz.Kind(check.ResourceType), nil
+	}
+
+	var resource interface{}
+	switch check.ResourceType {
+	case "post":
+		resource = &models.Post{}
+	case "comment":
+		resource = &models.Comment{}
+	case "file":
+		resource = &models.FileUpload{}
+	case "user":
+		resource = &models.User{}
+	default:
+		return nil, fmt.Errorf("unsupported resource type %q", check.ResourceType)
+	}
+
+	if err := s.db.First(resource, *check.ResourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s not found", check.ResourceType)
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return resource, nil
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"slices"
+	"strconv"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTooManyAttachments is returned when a post or comment already has
+	// as many attachments as allowed
+	ErrTooManyAttachments = errors.New("too many attachments")
+	// ErrAlreadyAttached is returned when attaching a file twice to the same
+	// post or comment
+	ErrAlreadyAttached = errors.New("file is already attached")
+	// ErrInvalidAttachmentOrder is returned when a new order does not list
+	// each attachment exactly once
+	ErrInvalidAttachmentOrder = errors.New("order must list each attachment exactly once")
+)
+
+// AttachmentService attaches uploaded files to posts and comments. Anyone who
+// can see a post can see its attachments and those of its visible comments;
+// anyone who can edit a post or comment can change its attachments.
+type AttachmentService struct {
+	db           *gorm.DB
+	fileService  *FileService
+	auditService *AuditService
+	config       config.AttachmentsConfig
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(db *gorm.DB, fileService *FileService, auditService *AuditService, cfg config.AttachmentsConfig) *AttachmentService {
+	return &AttachmentService{
+		db:           db,
+		fileService:  fileService,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *AttachmentService) WithContext(ctx context.Context) *AttachmentService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.fileService != nil {
+		clone.fileService = s.fileService.WithContext(ctx)
+	}
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// List returns the attachments of a post or comment the user can see, in
+// order
+func (s *AttachmentService) List(userID uint, target models.AttachmentTarget, targetID uint) ([]models.Attachment, error) {
+	if _, err := s.authorizeRead(userID, target, targetID); err != nil {
+		return nil, err
+	}
+	return s.attachments(s.db, target, targetID)
+}
+
+// Attach attaches a file the user may edit to a post or comment they may edit
+func (s *AttachmentService) Attach(userID uint, target models.AttachmentTarget, targetID uint, req *models.AttachmentCreateRequest) (*models.Attachment, error) {
+	postID, err := s.authorizeEdit(userID, target, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var file models.FileUpload
+	if err := s.db.First(&file, req.FileID).Error; err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "update", &file); err != nil {
+		return nil, fmt.Errorf("unauthorized to attach this file: %w", err)
+	}
+
+	return s.attach(userID, target, targetID, postID, &file, req.Caption, req.Position)
+}
+
+// Upload stores an uploaded file and attaches it to a post or comment the
+// user may edit. The file is deleted again if it cannot be attached.
+func (s *AttachmentService) Upload(userID uint, target models.AttachmentTarget, targetID uint, fileHeader *multipart.FileHeader, req *models.AttachmentUploadRequest) (*models.Attachment, error) {
+	postID, err := s.authorizeEdit(userID, target, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "create", authz.Kind("file")); err != nil {
+		return nil, fmt.Errorf("unauthorized to upload files: %w", err)
+	}
+	if err := s.checkLimit(target, targetID); err != nil {
+		return nil, err
+	}
+
+	upload, err := s.fileService.UploadFile(fileHeader, userID, models.AttachmentCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attach(userID, target, targetID, postID, upload.FileUpload, req.Caption, req.Position)
+	if err != nil {
+		cleanupErr := s.db.Unscoped().Delete(upload.FileUpload).Error
+		if cleanupErr == nil {
+			cleanupErr = removeFileBytes([]models.FileUpload{*upload.FileUpload})
+		}
+		if cleanupErr != nil {
+			return nil, fmt.Errorf("%w (and failed to delete upload: %v)", err, cleanupErr)
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Update changes an attachment's caption or moves it to another position
+func (s *AttachmentService) Update(userID, attachmentID uint, req *models.AttachmentUpdateRequest) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := s.db.First(&attachment, attachmentID).Error; err != nil {
+		return nil, err
+	}
+	if _, err := s.authorizeEdit(userID, attachment.TargetType, attachment.TargetID); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if req.Caption != nil {
+			if err := tx.Model(&attachment).Update("caption", *req.Caption).Error; err != nil {
+				return err
+			}
+		}
+		if req.Position == nil {
+			return nil
+		}
+
+		siblings, err := s.attachments(tx, attachment.TargetType, attachment.TargetID)
+		if err != nil {
+			return err
+		}
+		index := slices.IndexFunc(siblings, func(a models.Attachment) bool { return a.ID == attachment.ID })
+		if index < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		moved := siblings[index]
+		siblings = slices.Delete(siblings, index, index+1)
+		siblings = slices.Insert(siblings, min(*req.Position, len(siblings)), moved)
+		return arrangeAttachments(tx, siblings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		newValues := map[string]interface{}{}
+		if req.Caption != nil {
+			newValues["caption"] = *req.Caption
+		}
+		if req.Position != nil {
+			newValues["position"] = *req.Position
+		}
+		auditData := AuditEventData{
+			EntityType: "attachment",
+			EntityID:   strconv.FormatUint(uint64(attachmentID), 10),
+			OldValues: map[string]interface{}{
+				"caption":  attachment.Caption,
+				"position": attachment.Position,
+			},
+			NewValues: newValues,
+		}
+		s.auditService.LogEvent(userID, ActionUpdate, auditData)
+	}
+
+	return s.get(attachmentID)
+}
+
+// Reorder puts all of a post's or comment's attachments in the given order
+func (s *AttachmentService) Reorder(userID uint, target models.AttachmentTarget, targetID uint, ids []uint) ([]models.Attachment, error) {
+	if _, err := s.authorizeEdit(userID, target, targetID); err != nil {
+		return nil, err
+	}
+
+	var ordered []models.Attachment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		current, err := s.attachments(tx, target, targetID)
+		if err != nil {
+			return err
+		}
+		if len(ids) != len(current) {
+			return ErrInvalidAttachmentOrder
+		}
+
+		byID := make(map[uint]models.Attachment, len(current))
+		for _, attachment := range current {
+			byID[attachment.ID] = attachment
+		}
+		ordered = make([]models.Attachment, 0, len(ids))
+		for _, id := range ids {
+			attachment, ok := byID[id]
+			if !ok {
+				return ErrInvalidAttachmentOrder
+			}
+			delete(byID, id)
+			ordered = append(ordered, attachment)
+		}
+		return arrangeAttachments(tx, ordered)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "attachment",
+			NewValues: map[string]interface{}{
+				"target_type": target,
+				"target_id":   targetID,
+				"order":       ids,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionUpdate, auditData)
+	}
+
+	return ordered, nil
+}
+
+// Detach removes an attachment. A file uploaded as an attachment is moved to
+// the trash once it is not attached anywhere else.
+func (s *AttachmentService) Detach(userID, attachmentID uint) error {
+	var attachment models.Attachment
+	if err := s.db.First(&attachment, attachmentID).Error; err != nil {
+		return err
+	}
+	if _, err := s.authorizeEdit(userID, attachment.TargetType, attachment.TargetID); err != nil {
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return err
+		}
+		siblings, err := s.attachments(tx, attachment.TargetType, attachment.TargetID)
+		if err != nil {
+			return err
+		}
+		if err := arrangeAttachments(tx, siblings); err != nil {
+			return err
+		}
+
+		files, err := unattachedUploads(tx, []uint{attachment.FileID})
+		if err != nil || len(files) == 0 {
+			return err
+		}
+		return tx.Delete(&files).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "attachment",
+			EntityID:   strconv.FormatUint(uint64(attachmentID), 10),
+			OldValues: map[string]interface{}{
+				"file_id":     attachment.FileID,
+				"target_type": attachment.TargetType,
+				"target_id":   attachment.TargetID,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionDelete, auditData)
+	}
+
+	return nil
+}
+
+// Download returns an attachment the user can see with its file, and counts
+// the download
+func (s *AttachmentService) Download(userID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.get(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorizeRead(userID, attachment.TargetType, attachment.TargetID); err != nil {
+		return nil, err
+	}
+	if attachment.File == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	err = s.db.Model(attachment.File).UpdateColumn("download_count", gorm.Expr("download_count + 1")).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// attach adds the file to the post or comment at the position, or at the end
+func (s *AttachmentService) attach(userID uint, target models.AttachmentTarget, targetID, postID uint, file *models.FileUpload, caption string, position *int) (*models.Attachment, error) {
+	if err := s.checkLimit(target, targetID); err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		FileID:     file.ID,
+		TargetType: target,
+		TargetID:   targetID,
+		PostID:     postID,
+		UserID:     userID,
+		Caption:    caption,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		siblings, err := s.attachments(tx, target, targetID)
+		if err != nil {
+			return err
+		}
+		for _, sibling := range siblings {
+			if sibling.FileID == file.ID {
+				return ErrAlreadyAttached
+			}
+		}
+
+		attachment.Position = len(siblings)
+		if err := tx.Create(attachment).Error; err != nil {
+			return err
+		}
+		if position == nil || *position >= len(siblings) {
+			return nil
+		}
+		siblings = slices.Insert(siblings, *position, *attachment)
+		return arrangeAttachments(tx, siblings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "attachment",
+			EntityID:   strconv.FormatUint(uint64(attachment.ID), 10),
+			NewValues: map[string]interface{}{
+				"file_id":     file.ID,
+				"target_type": target,
+				"target_id":   targetID,
+				"caption":     caption,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionCreate, auditData)
+	}
+
+	return s.get(attachment.ID)
+}
+
+// checkLimit checks the post or comment can take another attachment
+func (s *AttachmentService) checkLimit(target models.AttachmentTarget, targetID uint) error {
+	if s.config.MaxPerTarget <= 0 {
+		return nil
+	}
+
+	var count int64
+	err := s.db.Model(&models.Attachment{}).
+		Where("target_type = ? AND target_id = ?", target, targetID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.config.MaxPerTarget) {
+		return fmt.Errorf("%w: at most %d per %s", ErrTooManyAttachments, s.config.MaxPerTarget, target)
+	}
+	return nil
+}
+
+// get loads an attachment with its file
+func (s *AttachmentService) get(attachmentID uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := s.db.Preload("File").First(&attachment, attachmentID).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// attachments loads the attachments of a post or comment with their files, in
+// order. Attachments whose file is in the trash are left out.
+func (s *AttachmentService) attachments(db *gorm.DB, target models.AttachmentTarget, targetID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := db.Preload("File").
+		Where("target_type = ? AND target_id = ?", target, targetID).
+		Where("file_id IN (?)", db.Model(&models.FileUpload{}).Select("id")).
+		Order("position ASC").
+		Order("id ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// authorizeRead checks the user can see the post or comment and returns its
+// post's ID. Comments are visible like in their post's thread: approved
+// ones, and the user's own.
+func (s *AttachmentService) authorizeRead(userID uint, target models.AttachmentTarget, targetID uint) (uint, error) {
+	post, comment, err := s.parent(target, targetID)
+	if err != nil {
+		return 0, err
+	}
+	if comment != nil && comment.Status != models.CommentStatusApproved && comment.UserID != userID &&
+		authorize(s.db, userID, "moderate", authz.Kind("comment")) != nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if err := authorize(s.db, userID, "read", post); err != nil {
+		return 0, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+	return post.ID, nil
+}
+
+// authorizeEdit checks the user may edit the post or comment and returns its
+// post's ID
+func (s *AttachmentService) authorizeEdit(userID uint, target models.AttachmentTarget, targetID uint) (uint, error) {
+	post, comment, err := s.parent(target, targetID)
+	if err != nil {
+		return 0, err
+	}
+	if comment != nil {
+		if err := authorize(s.db, userID, "update", comment); err != nil {
+			return 0, fmt.Errorf("unauthorized to edit this comment: %w", err)
+		}
+		return post.ID, nil
+	}
+	if err := authorize(s.db, userID, "update", post); err != nil {
+		return 0, fmt.Errorf("unauthorized to edit this post: %w", err)
+	}
+	return post.ID, nil
+}
+
+// parent loads the post an attachment target is on, and the comment when the
+// target is one
+func (s *AttachmentService) parent(target models.AttachmentTarget, targetID uint) (*models.Post, *models.Comment, error) {
+	var post models.Post
+	switch target {
+	case models.AttachmentTargetPost:
+		if err := s.db.First(&post, targetID).Error; err != nil {
+			return nil, nil, err
+		}
+		return &post, nil, nil
+	case models.AttachmentTargetComment:
+		var comment models.Comment
+		if err := s.db.First(&comment, targetID).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := s.db.First(&post, comment.PostID).Error; err != nil {
+			return nil, nil, err
+		}
+		return &post, &comment, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown attachment target: %s", target)
+	}
+}
+
+// arrangeAttachments numbers the attachments' positions in slice order
+func arrangeAttachments(db *gorm.DB, attachments []models.Attachment) error {
+	for i := range attachments {
+		if attachments[i].Position == i {
+			continue
+		}
+		attachments[i].Position = i
+		err := db.Model(&models.Attachment{}).Where("id = ?", attachments[i].ID).Update("position", i).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeAttachments permanently deletes the attachments matching the query,
+// and the files uploaded for them that are no longer attached anywhere. db
+// must ignore the trash so files already in it are purged too. The purged
+// files are returned so their bytes can be removed once the transaction
+// commits.
+func purgeAttachments(db *gorm.DB, query string, args ...interface{}) ([]models.FileUpload, error) {
+	var fileIDs []uint
+	if err := db.Model(&models.Attachment{}).Where(query, args...).Pluck("file_id", &fileIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(fileIDs) == 0 {
+		return nil, nil
+	}
+	if err := db.Where(query, args...).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+
+	files, err := unattachedUploads(db, fileIDs)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	if err := db.Delete(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// unattachedUploads loads the files among fileIDs that were uploaded as
+// attachments and are no longer attached to anything
+func unattachedUploads(db *gorm.DB, fileIDs []uint) ([]models.FileUpload, error) {
+	var files []models.FileUpload
+	err := db.Where("id IN ? AND category = ?", fileIDs, models.AttachmentCategory).
+		Where("id NOT IN (?)", db.Model(&models.Attachment{}).Select("file_id")).
+		Find(&files).Error
+	return files, err
+}
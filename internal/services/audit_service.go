@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"go-backend/internal/models"
+	"go-backend/internal/tenant"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,6 +20,20 @@ func NewAuditService(db *gorm.DB) *AuditService {
 	return &AuditService{db: db}
 }
 
+// WithContext returns a copy of the service whose queries use ctx, which
+// carries the tenant audit logs are scoped to
+func (s *AuditService) WithContext(ctx context.Context) *AuditService {
+	return &AuditService{db: s.db.WithContext(ctx)}
+}
+
+// tenantID returns the tenant the service's context is scoped to, if any
+func (s *AuditService) tenantID() *uint {
+	if tenantID, ok := tenant.FromContext(s.db.Statement.Context); ok {
+		return &tenantID
+	}
+	return nil
+}
+
 // AuditAction defines types of auditable actions
 type AuditAction string
 
@@ -32,8 +48,13 @@ const (
 	ActionEmailVerify  AuditAction = "email_verify"
 	ActionRoleChange   AuditAction = "role_change"
 	ActionPermissionChange AuditAction = "permission_change"
+	ActionMembershipChange AuditAction = "membership_change"
+	ActionShareChange  AuditAction = "share_change"
+	ActionModerate     AuditAction = "moderate"
 	ActionFileUpload   AuditAction = "file_upload"
 	ActionFileDownload AuditAction = "file_download"
+	ActionExport       AuditAction = "export"
+	ActionImport       AuditAction = "import"
 	ActionSecurityEvent AuditAction = "security_event"
 )
 
@@ -80,6 +101,7 @@ func (s *AuditService) LogEvent(userID uint, action AuditAction, data AuditEvent
 		Metadata:   string(metadataJSON),
 		CreatedAt:  time.Now(),
 	}
+	auditLog.TenantID = s.tenantID()
 
 	return s.db.Create(auditLog).Error
 }
@@ -104,6 +126,7 @@ func (s *AuditService) LogSystemEvent(action AuditAction, data AuditEventData) e
 		Metadata:  string(metadataJSON),
 		CreatedAt: time.Now(),
 	}
+	auditLog.TenantID = s.tenantID()
 
 	return s.db.Create(auditLog).Error
 }
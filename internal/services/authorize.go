@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// authorize loads the acting user and checks the action against the
+// authorization policy. A zero user ID checks anonymous access. Denials are
+// returned as *authz.DeniedError.
+func authorize(db *gorm.DB, userID uint, action string, resource interface{}) error {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if userID == 0 {
+		return authz.Check(ctx, nil, action, resource)
+	}
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return errors.New("unauthorized")
+	}
+
+	return authz.Check(ctx, &user, action, resource)
+}
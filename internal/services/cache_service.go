@@ -356,6 +356,12 @@ func (s *CacheService) HashGetAll(ctx context.Context, key string) (map[string]s
 	return s.client.HGetAll(ctx, cacheKey).Result()
 }
 
+// HashIncrement atomically increments a counter field in a hash
+func (s *CacheService) HashIncrement(ctx context.Context, key, field string, delta int64) (int64, error) {
+	cacheKey := s.buildKey(key)
+	return s.client.HIncrBy(ctx, cacheKey, field, delta).Result()
+}
+
 // HashDelete deletes fields from a hash
 func (s *CacheService) HashDelete(ctx context.Context, key string, fields ...string) error {
 	cacheKey := s.buildKey(key)
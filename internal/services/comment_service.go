@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/markdown"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrParentNotFound is returned when replying to a comment that is not visible on the post
+	ErrParentNotFound = errors.New("parent comment not found")
+	// ErrCommentTooDeep is returned when a reply would exceed the maximum thread depth
+	ErrCommentTooDeep = errors.New("reply exceeds the maximum thread depth")
+	// ErrEditWindowClosed is returned when an author edits a comment after the edit window
+	ErrEditWindowClosed = errors.New("comment can no longer be edited")
+)
+
+// CommentService provides threaded comments on posts and their moderation
+type CommentService struct {
+	*CRUDService[models.Comment]
+	db             *gorm.DB
+	auditService   *AuditService
+	mentionService *MentionService
+	config         config.CommentsConfig
+}
+
+// NewCommentService creates a new comment service instance. Users mentioned
+// in comments are notified through mentionService when it is given.
+func NewCommentService(db *gorm.DB, auditService *AuditService, mentionService *MentionService, cfg config.CommentsConfig) *CommentService {
+	return &CommentService{
+		CRUDService:    NewCRUDService[models.Comment](db),
+		db:             db,
+		auditService:   auditService,
+		mentionService: mentionService,
+		config:         cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *CommentService) WithContext(ctx context.Context) *CommentService {
+	var auditService *AuditService
+	if s.auditService != nil {
+		auditService = s.auditService.WithContext(ctx)
+	}
+	var mentionService *MentionService
+	if s.mentionService != nil {
+		mentionService = s.mentionService.WithContext(ctx)
+	}
+	return &CommentService{
+		CRUDService:    s.CRUDService.WithContext(ctx),
+		db:             s.db.WithContext(ctx),
+		auditService:   auditService,
+		mentionService: mentionService,
+		config:         s.config,
+	}
+}
+
+// CreateComment adds a comment or reply to a post the user may read. Comments
+// by other users on posts that require approval wait in the moderation queue.
+func (s *CommentService) CreateComment(userID uint, req *models.CommentCreateRequest) (*models.Comment, error) {
+	var post models.Post
+	if err := s.db.First(&post, req.PostID).Error; err != nil {
+		return nil, err
+	}
+
+	// Check the user may read the post and write comments
+	if err := authorize(s.db, userID, "read", &post); err != nil {
+		return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+	if err := authorize(s.db, userID, "create", authz.Kind("comment")); err != nil {
+		return nil, fmt.Errorf("unauthorized to comment: %w", err)
+	}
+
+	contentHTML, err := markdown.Render(req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
+	comment := &models.Comment{
+		Content:       req.Content,
+		ContentHTML:   contentHTML,
+		RenderVersion: markdown.Version,
+		UserID:        userID,
+		PostID:        post.ID,
+		ParentID:      req.ParentID,
+		Status:        models.CommentStatusApproved,
+	}
+
+	if req.ParentID != nil {
+		var parent models.Comment
+		err := s.db.Where("id = ? AND post_id = ? AND status = ?", *req.ParentID, post.ID, models.CommentStatusApproved).
+			First(&parent).Error
+		if err != nil {
+			return nil, ErrParentNotFound
+		}
+		if parent.Depth+1 > s.config.MaxDepth {
+			return nil, ErrCommentTooDeep
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	if post.CommentsRequireApproval && post.UserID != userID {
+		comment.Status = models.CommentStatusPending
+	}
+
+	if err := s.Create(comment); err != nil {
+		return nil, err
+	}
+
+	// Log the creation in audit trail
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "comment",
+			EntityID:   strconv.FormatUint(uint64(comment.ID), 10),
+			NewValues: map[string]interface{}{
+				"post_id":   comment.PostID,
+				"parent_id": comment.ParentID,
+				"content":   comment.Content,
+				"status":    comment.Status,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionCreate, auditData)
+	}
+
+	if s.mentionService != nil {
+		s.mentionService.Record(userID, models.MentionTargetComment, comment.ID, comment.PostID, comment.Content)
+	}
+
+	return s.GetByID(comment.ID, "User")
+}
+
+// GetPostComments returns the comment threads of a post the user may read.
+// Approved comments are visible to everyone; users also see their own
+// comments awaiting moderation. A zero user ID reads as an anonymous visitor.
+func (s *CommentService) GetPostComments(postID, userID uint) ([]models.Comment, error) {
+	var post models.Post
+	if err := s.db.First(&post, postID).Error; err != nil {
+		return nil, err
+	}
+
+	// Check the user may read the post
+	if err := authorize(s.db, userID, "read", &post); err != nil {
+		return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+
+	query := s.db.Preload("User").Where("post_id = ?", postID)
+	if userID == 0 {
+		query = query.Where("status = ?", models.CommentStatusApproved)
+	} else {
+		query = query.Where("(status = ? OR (status = ? AND user_id = ?))",
+			models.CommentStatusApproved, models.CommentStatusPending, userID)
+	}
+
+	var comments []models.Comment
+	if err := query.Order("created_at ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	// Load reaction counts for the whole thread at once
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	counts, err := reactionCounts(s.db, models.ReactionTargetComment, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+	}
+
+	return buildThreads(comments), nil
+}
+
+// UpdateComment edits a comment. Authors can only edit within the edit
+// window; moderators can edit any time.
+func (s *CommentService) UpdateComment(commentID, userID uint, req *models.CommentUpdateRequest) (*models.Comment, error) {
+	existingComment, err := s.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user may edit the comment
+	if err := authorize(s.db, userID, "update", existingComment); err != nil {
+		return nil, fmt.Errorf("unauthorized to edit this comment: %w", err)
+	}
+	if existingComment.UserID == userID && time.Since(existingComment.CreatedAt) > s.config.EditWindow {
+		if authorize(s.db, userID, "moderate", authz.Kind("comment")) != nil {
+			return nil, ErrEditWindowClosed
+		}
+	}
+	if err := checkVersion(s.db, existingComment.Version); err != nil {
+		return nil, err
+	}
+
+	if req.Content != nil && *req.Content != existingComment.Content {
+		contentHTML, err := markdown.Render(*req.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render content: %w", err)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"content":        *req.Content,
+			"content_html":   contentHTML,
+			"render_version": markdown.Version,
+			"edited_at":      now,
+			"updated_at":     now,
+		}
+		if err := s.Update(commentID, updates); err != nil {
+			return nil, err
+		}
+
+		// Log the update in audit trail
+		if s.auditService != nil {
+			auditData := AuditEventData{
+				EntityType: "comment",
+				EntityID:   strconv.FormatUint(uint64(commentID), 10),
+				OldValues:  map[string]interface{}{"content": existingComment.Content},
+				NewValues:  map[string]interface{}{"content": *req.Content},
+			}
+			s.auditService.LogEvent(userID, ActionUpdate, auditData)
+		}
+
+		if s.mentionService != nil {
+			s.mentionService.Record(existingComment.UserID, models.MentionTargetComment, commentID, existingComment.PostID, *req.Content)
+		}
+	}
+
+	return s.GetByID(commentID, "User")
+}
+
+// DeleteComment deletes a comment and its replies
+func (s *CommentService) DeleteComment(commentID, userID uint) error {
+	existingComment, err := s.GetByID(commentID)
+	if err != nil {
+		return err
+	}
+
+	// Check the user may delete the comment
+	if err := authorize(s.db, userID, "delete", existingComment); err != nil {
+		return fmt.Errorf("unauthorized to delete this comment: %w", err)
+	}
+	if err := checkVersion(s.db, existingComment.Version); err != nil {
+		return err
+	}
+
+	ids, err := s.threadIDs(commentID)
+	if err != nil {
+		return err
+	}
+	// The comment may have changed since it was loaded, so the version is
+	// checked again as it is deleted. Its replies are deleted at the same
+	// time, which is how restoring the comment finds them.
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteVersioned(tx, &models.Comment{}, commentID); err != nil {
+			return err
+		}
+		var deleted models.Comment
+		if err := tx.Unscoped().Select("deleted_at").First(&deleted, commentID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.Comment{}).Where("id IN ?", ids).UpdateColumn("deleted_at", deleted.DeletedAt).Error
+		if err != nil {
+			return err
+		}
+		return recordDeletion(tx, &models.Comment{}, ids, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log the deletion in audit trail
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "comment",
+			EntityID:   strconv.FormatUint(uint64(commentID), 10),
+			OldValues: map[string]interface{}{
+				"post_id":     existingComment.PostID,
+				"content":     existingComment.Content,
+				"deleted_ids": ids,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionDelete, auditData)
+	}
+
+	return nil
+}
+
+// GetModerationQueue lists comments with the given status for moderators,
+// oldest first
+func (s *CommentService) GetModerationQueue(userID uint, status models.CommentStatus, options QueryOptions) (*PaginatedResult[models.Comment], error) {
+	if err := authorize(s.db, userID, "moderate", authz.Kind("comment")); err != nil {
+		return nil, fmt.Errorf("unauthorized to moderate comments: %w", err)
+	}
+
+	options.Filter.Filters = map[string]interface{}{"status": string(status)}
+	options.Preload = append(options.Preload, "User", "Post")
+	if len(options.Sort) == 0 {
+		options.Sort = []SortOptions{{Field: "created_at", Direction: "asc"}}
+	}
+
+	return s.GetAll(options)
+}
+
+// ModerateComments sets the moderation status of comments and returns how
+// many were changed
+func (s *CommentService) ModerateComments(commentIDs []uint, status models.CommentStatus, userID uint) (int64, error) {
+	if !status.IsValid() || status == models.CommentStatusPending {
+		return 0, fmt.Errorf("invalid moderation status: %s", status)
+	}
+	if err := authorize(s.db, userID, "moderate", authz.Kind("comment")); err != nil {
+		return 0, fmt.Errorf("unauthorized to moderate comments: %w", err)
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.Comment{}).
+		Where("id IN ? AND status <> ?", commentIDs, status).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"moderated_by": userID,
+			"moderated_at": now,
+			"updated_at":   now,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// Log the moderation in audit trail
+	if s.auditService != nil && result.RowsAffected > 0 {
+		auditData := AuditEventData{
+			EntityType: "comment",
+			NewValues: map[string]interface{}{
+				"ids":    commentIDs,
+				"status": status,
+				"count":  result.RowsAffected,
+			},
+		}
+		s.auditService.LogEvent(userID, ActionModerate, auditData)
+	}
+
+	if s.mentionService != nil && status == models.CommentStatusApproved && result.RowsAffected > 0 {
+		s.mentionService.Deliver(models.MentionTargetComment, commentIDs...)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// threadIDs returns the ID of a comment and all of its replies
+func (s *CommentService) threadIDs(commentID uint) ([]uint, error) {
+	ids := []uint{commentID}
+	parents := ids
+	for len(parents) > 0 {
+		var children []uint
+		if err := s.db.Model(&models.Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+	return ids, nil
+}
+
+// buildThreads nests comments under their parents. Replies to comments that
+// are not in the list are left out.
+func buildThreads(comments []models.Comment) []models.Comment {
+	children := make(map[uint][]models.Comment)
+	for _, comment := range comments {
+		var parentID uint
+		if comment.ParentID != nil {
+			parentID = *comment.ParentID
+		}
+		children[parentID] = append(children[parentID], comment)
+	}
+
+	var attach func(parentID uint) []models.Comment
+	attach = func(parentID uint) []models.Comment {
+		replies := children[parentID]
+		for i := range replies {
+			replies[i].Replies = attach(replies[i].ID)
+		}
+		return replies
+	}
+
+	threads := attach(0)
+	if threads == nil {
+		threads = []models.Comment{}
+	}
+	return threads
+}
@@ -1,10 +1,13 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 
+	"go-backend/internal/etag"
+
 	"gorm.io/gorm"
 )
 
@@ -12,14 +15,30 @@ import (
 type CRUDService[T any] struct {
 	db        *gorm.DB
 	modelType reflect.Type
+	// versioned is set for models with a Version field, whose updates and
+	// deletes honour the request's If-Match versions
+	versioned bool
 }
 
 // NewCRUDService creates a new generic CRUD service for the specified model type
 func NewCRUDService[T any](db *gorm.DB) *CRUDService[T] {
 	var model T
+	modelType := reflect.TypeOf(model)
+	_, versioned := modelType.FieldByName("Version")
 	return &CRUDService[T]{
 		db:        db,
-		modelType: reflect.TypeOf(model),
+		modelType: modelType,
+		versioned: versioned,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx. Tenant
+// scoping reads the current tenant from ctx.
+func (s *CRUDService[T]) WithContext(ctx context.Context) *CRUDService[T] {
+	return &CRUDService[T]{
+		db:        s.db.WithContext(ctx),
+		modelType: s.modelType,
+		versioned: s.versioned,
 	}
 }
 
@@ -140,19 +159,60 @@ func (s *CRUDService[T]) GetAll(options QueryOptions) (*PaginatedResult[T], erro
 	}, nil
 }
 
-// Update updates a record by ID
+// Update updates a record by ID. Versioned records get a new version, and
+// conditional requests fail with ErrPreconditionFailed if the record has
+// changed since.
 func (s *CRUDService[T]) Update(id interface{}, updates map[string]interface{}) error {
+	if s.versioned {
+		return updateVersioned(s.db, new(T), id, updates)
+	}
 	return s.db.Model(new(T)).Where("id = ?", id).Updates(updates).Error
 }
 
-// UpdateStruct updates a record using a struct
+// UpdateStruct updates a record using a struct. Versioned records are handled
+// as in Update.
 func (s *CRUDService[T]) UpdateStruct(id interface{}, model *T) error {
-	return s.db.Model(model).Where("id = ?", id).Updates(model).Error
+	if !s.versioned {
+		return s.db.Model(model).Where("id = ?", id).Updates(model).Error
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var current struct{ Version uint }
+		if err := tx.Model(new(T)).Select("version").Where("id = ?", id).Take(&current).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(tx, current.Version); err != nil {
+			return err
+		}
+
+		reflect.ValueOf(model).Elem().FieldByName("Version").SetUint(uint64(current.Version + 1))
+		result := tx.Model(model).Where("id = ? AND version = ?", id, current.Version).Updates(model)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPreconditionFailed
+		}
+		return nil
+	})
 }
 
-// Delete soft deletes a record by ID
+// Delete soft deletes a record by ID. Conditional requests fail with
+// ErrPreconditionFailed if a versioned record has changed since.
 func (s *CRUDService[T]) Delete(id interface{}) error {
-	return s.db.Delete(new(T), id).Error
+	expected, conditional := etag.Expected(s.db.Statement.Context)
+	if !s.versioned || !conditional {
+		return s.db.Delete(new(T), id).Error
+	}
+
+	result := s.db.Where("version IN ?", expected).Delete(new(T), id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	}
+	return nil
 }
 
 // HardDelete permanently deletes a record by ID
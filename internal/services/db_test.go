@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/database"
+	"go-backend/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB returns a migrated in-memory SQLite database
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	// Every connection to :memory: opens a separate database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, (&database.Database{DB: db}).Migrate())
+	return db
+}
+
+// createTestUser creates an active user with the role
+func createTestUser(t *testing.T, db *gorm.DB, username string, role models.Role) *models.User {
+	t.Helper()
+	user := &models.User{
+		Email:       username + "@example.com",
+		Username:    username,
+		Password:    "secret123",
+		FirstName:   "Test",
+		LastName:    "User",
+		PhoneNumber: "tel:" + username,
+		Role:        role,
+		IsActive:    true,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
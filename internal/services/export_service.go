@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/bundle"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrExportUserNotFound is returned when exporting the posts of a user who
+// does not exist
+var ErrExportUserNotFound = errors.New("user not found")
+
+// ExportService exports posts as bundles of Markdown files with YAML front
+// matter, along with their approved comments and the files attached to
+// both. Users export their own posts; admins can export another user's or
+// everyone's, and their exports carry authors' emails so that imports map
+// them back to users.
+type ExportService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewExportService creates a new export service
+func NewExportService(db *gorm.DB, auditService *AuditService) *ExportService {
+	return &ExportService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ExportService) WithContext(ctx context.Context) *ExportService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// PostExport is a set of posts ready to be written as a bundle
+type PostExport struct {
+	Posts []bundle.Post
+
+	// files maps the path of each attached file in the bundle to the file
+	// on disk
+	files map[string]string
+}
+
+// Write writes the export to w as a zip archive
+func (e *PostExport) Write(w io.Writer) error {
+	return bundle.Write(w, e.Posts, func(filePath string) (io.ReadCloser, error) {
+		return os.Open(e.files[filePath])
+	})
+}
+
+// Export collects the posts of the target user, the user's own for a zero
+// target, or everyone's if all is set, with their approved comments and
+// attachments. Links to attachments in posts and comments are rewritten to
+// the attached files in the bundle. Attachments whose file is missing from
+// disk are left out.
+func (s *ExportService) Export(userID, targetID uint, all bool) (*PostExport, error) {
+	if targetID == 0 {
+		targetID = userID
+	}
+
+	err := authorize(s.db, userID, "export", authz.Kind("post"))
+	if all && err != nil {
+		return nil, fmt.Errorf("unauthorized to export all posts: %w", err)
+	}
+	if !all && targetID != userID && err != nil {
+		return nil, fmt.Errorf("unauthorized to export another user's posts: %w", err)
+	}
+	withEmails := err == nil
+
+	query := s.db.Preload("User").Preload("Tags").Preload("Categories").Order("id ASC")
+	if !all {
+		if err := s.db.Select("id").First(&models.User{}, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrExportUserNotFound
+			}
+			return nil, fmt.Errorf("failed to fetch user: %w", err)
+		}
+		query = query.Where("user_id = ?", targetID)
+	}
+	var posts []models.Post
+	if err := query.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch posts: %w", err)
+	}
+
+	export := &PostExport{Posts: []bundle.Post{}, files: make(map[string]string)}
+	if len(posts) == 0 {
+		return export, nil
+	}
+
+	postIDs := make([]uint, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	// Comments of every status are fetched so that replies to comments
+	// left out can hang off their nearest approved ancestor
+	var comments []models.Comment
+	err = s.db.Preload("User").Where("post_id IN ?", postIDs).Order("id ASC").Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	commentsByPost := make(map[uint][]models.Comment)
+	for _, comment := range comments {
+		commentsByPost[comment.PostID] = append(commentsByPost[comment.PostID], comment)
+	}
+
+	var attachments []models.Attachment
+	err = s.db.Preload("File").Where("post_id IN ?", postIDs).
+		Order("target_type ASC, target_id ASC, position ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	attachmentsByPost := make(map[uint][]models.Attachment)
+	for _, attachment := range attachments {
+		attachmentsByPost[attachment.PostID] = append(attachmentsByPost[attachment.PostID], attachment)
+	}
+
+	for _, post := range posts {
+		export.Posts = append(export.Posts, export.add(&post, commentsByPost[post.ID], attachmentsByPost[post.ID], withEmails))
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogEvent(userID, ActionExport, AuditEventData{
+			EntityType: "post",
+			NewValues: map[string]interface{}{
+				"all":     all,
+				"user_id": targetID,
+				"count":   len(export.Posts),
+			},
+		})
+	}
+
+	return export, nil
+}
+
+// add converts a post with its comments and attachments for the bundle,
+// recording where the attached files go
+func (e *PostExport) add(post *models.Post, comments []models.Comment, attachments []models.Attachment, withEmails bool) bundle.Post {
+	exported := bundle.Post{
+		Title:       post.Title,
+		Slug:        post.Slug,
+		Author:      exportAuthor(&post.User, withEmails),
+		Status:      string(post.Status),
+		CreatedAt:   post.CreatedAt,
+		PublishAt:   post.PublishAt,
+		PublishedAt: post.PublishedAt,
+
+		CommentsRequireApproval: post.CommentsRequireApproval,
+	}
+	for _, tag := range post.Tags {
+		exported.Tags = append(exported.Tags, tag.Name)
+	}
+	for _, category := range post.Categories {
+		exported.Categories = append(exported.Categories, category.Slug)
+	}
+
+	// Only approved comments are exported
+	parents := make(map[uint]*uint, len(comments))
+	commentIDs := make(map[uint]bool, len(comments))
+	var approved []models.Comment
+	for _, comment := range comments {
+		parents[comment.ID] = comment.ParentID
+		if comment.Status == models.CommentStatusApproved {
+			commentIDs[comment.ID] = true
+			approved = append(approved, comment)
+		}
+	}
+
+	// Attachments are numbered across the post and its comments, as they
+	// share a directory. Those of comments left out are left out too.
+	var links []attachmentLink
+	byComment := make(map[uint][]bundle.Attachment)
+	index := 0
+	for _, attachment := range attachments {
+		file := attachment.File
+		if file == nil {
+			continue
+		}
+		if attachment.TargetType == models.AttachmentTargetComment && !commentIDs[attachment.TargetID] {
+			continue
+		}
+		if _, err := os.Stat(file.FilePath); err != nil {
+			continue
+		}
+
+		index++
+		filePath := bundle.AttachmentPath(post.Slug, index, file.OriginalName)
+		e.files[filePath] = file.FilePath
+		links = append(links, newAttachmentLink(attachment.ID, bundle.Link(filePath)))
+
+		exportedAttachment := bundle.Attachment{
+			Path:     filePath,
+			Name:     file.OriginalName,
+			MimeType: file.MimeType,
+			Caption:  attachment.Caption,
+		}
+		if attachment.TargetType == models.AttachmentTargetComment {
+			byComment[attachment.TargetID] = append(byComment[attachment.TargetID], exportedAttachment)
+		} else {
+			exported.Attachments = append(exported.Attachments, exportedAttachment)
+		}
+	}
+
+	exported.Content = rewriteLinks(post.Content, links)
+	for _, comment := range approved {
+		exportedComment := bundle.Comment{
+			ID:          comment.ID,
+			Author:      exportAuthor(&comment.User, withEmails),
+			Status:      string(comment.Status),
+			CreatedAt:   comment.CreatedAt,
+			Content:     rewriteLinks(comment.Content, links),
+			Attachments: byComment[comment.ID],
+		}
+		for parent := comment.ParentID; parent != nil; parent = parents[*parent] {
+			if commentIDs[*parent] {
+				exportedComment.Parent = *parent
+				break
+			}
+		}
+		exported.Comments = append(exported.Comments, exportedComment)
+	}
+
+	return exported
+}
+
+// attachmentLink rewrites the links matching pattern to url
+type attachmentLink struct {
+	pattern *regexp.Regexp
+	url     string
+}
+
+// newAttachmentLink returns a link rewriting an attachment's download URL,
+// relative or on any host, to url
+func newAttachmentLink(attachmentID uint, url string) attachmentLink {
+	pattern := regexp.MustCompile(`(?:https?://[^/\s"'()<>]+)?` + regexp.QuoteMeta(models.AttachmentURL(attachmentID)))
+	return attachmentLink{pattern: pattern, url: url}
+}
+
+// rewriteLinks rewrites the attachment links in Markdown
+func rewriteLinks(content string, links []attachmentLink) string {
+	for _, link := range links {
+		content = link.pattern.ReplaceAllLiteralString(content, link.url)
+	}
+	return content
+}
+
+// exportAuthor identifies a user in a bundle
+func exportAuthor(user *models.User, withEmail bool) bundle.Author {
+	author := bundle.Author{Username: user.Username}
+	if withEmail {
+		author.Email = user.Email
+	}
+	return author
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTargetUser(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	other := createTestUser(t, db, "other", models.RoleUser)
+	require.NoError(t, db.Create(&models.Post{Title: "Mine", Content: "content", Slug: "mine", UserID: author.ID}).Error)
+	require.NoError(t, db.Create(&models.Post{Title: "Theirs", Content: "content", Slug: "theirs", UserID: other.ID}).Error)
+	service := NewExportService(db, nil)
+
+	export, err := service.Export(author.ID, 0, false)
+	require.NoError(t, err)
+	require.Len(t, export.Posts, 1)
+	assert.Equal(t, "mine", export.Posts[0].Slug)
+	assert.Empty(t, export.Posts[0].Author.Email)
+
+	export, err = service.Export(author.ID, author.ID, false)
+	require.NoError(t, err)
+	assert.Len(t, export.Posts, 1)
+
+	var denied *authz.DeniedError
+	_, err = service.Export(author.ID, other.ID, false)
+	assert.ErrorAs(t, err, &denied)
+	_, err = service.Export(author.ID, 0, true)
+	assert.ErrorAs(t, err, &denied)
+
+	export, err = service.Export(admin.ID, other.ID, false)
+	require.NoError(t, err)
+	require.Len(t, export.Posts, 1)
+	assert.Equal(t, "theirs", export.Posts[0].Slug)
+	assert.Equal(t, other.Email, export.Posts[0].Author.Email)
+
+	export, err = service.Export(admin.ID, 0, true)
+	require.NoError(t, err)
+	assert.Len(t, export.Posts, 2)
+
+	_, err = service.Export(admin.ID, 9999, false)
+	assert.ErrorIs(t, err, ErrExportUserNotFound)
+}
+
+func TestExportApprovedComments(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+
+	comment := func(content string, status models.CommentStatus, parent *models.Comment) *models.Comment {
+		c := &models.Comment{Content: content, UserID: author.ID, PostID: post.ID, Status: status}
+		if parent != nil {
+			c.ParentID = &parent.ID
+		}
+		require.NoError(t, db.Create(c).Error)
+		return c
+	}
+	root := comment("root", models.CommentStatusApproved, nil)
+	pending := comment("pending", models.CommentStatusPending, root)
+	reply := comment("reply to pending", models.CommentStatusApproved, pending)
+	spam := comment("spam", models.CommentStatusSpam, nil)
+	orphan := comment("reply to spam", models.CommentStatusApproved, spam)
+	comment("rejected", models.CommentStatusRejected, nil)
+
+	export, err := NewExportService(db, nil).Export(author.ID, 0, false)
+	require.NoError(t, err)
+	require.Len(t, export.Posts, 1)
+
+	comments := export.Posts[0].Comments
+	require.Len(t, comments, 3)
+	assert.Equal(t, root.ID, comments[0].ID)
+	assert.Zero(t, comments[0].Parent)
+	assert.Equal(t, reply.ID, comments[1].ID)
+	assert.Equal(t, root.ID, comments[1].Parent, "replies hang off their nearest approved ancestor")
+	assert.Equal(t, orphan.ID, comments[2].ID)
+	assert.Zero(t, comments[2].Parent)
+	for _, c := range comments {
+		assert.Equal(t, string(models.CommentStatusApproved), c.Status)
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"go-backend/internal/config"
+	"go-backend/internal/feed"
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// FeedService builds syndication feeds and sitemaps of published posts.
+// Links point at the frontend's pages.
+type FeedService struct {
+	db      *gorm.DB
+	app     config.AppConfig
+	config  config.FeedsConfig
+	tenancy config.TenancyConfig
+}
+
+// NewFeedService creates a new feed service
+func NewFeedService(db *gorm.DB, app config.AppConfig, cfg config.FeedsConfig, tenancy config.TenancyConfig) *FeedService {
+	app.FrontendURL = strings.TrimSuffix(app.FrontendURL, "/")
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = app.FrontendURL
+	}
+	return &FeedService{
+		db:      db,
+		app:     app,
+		config:  cfg,
+		tenancy: tenancy,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *FeedService) WithContext(ctx context.Context) *FeedService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// ForTenant returns a copy of the service whose links point at the current
+// organization's subdomain when organizations are resolved from subdomains.
+// The scheme, port and path of the configured URLs are kept. Without a
+// tenant in the context, or with header-only tenancy, links point at the
+// configured URLs.
+func (s *FeedService) ForTenant() (*FeedService, error) {
+	tenantID, ok := tenant.FromContext(s.db.Statement.Context)
+	if !s.tenancy.Enabled || s.tenancy.BaseDomain == "" || !ok {
+		return s, nil
+	}
+
+	var org models.Organization
+	if err := s.db.Select("slug").First(&org, tenantID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	host := org.Slug + "." + strings.ToLower(s.tenancy.BaseDomain)
+	clone := *s
+	clone.app.FrontendURL = withHost(s.app.FrontendURL, host)
+	clone.config.BaseURL = withHost(s.config.BaseURL, host)
+	return &clone, nil
+}
+
+// URL returns the public URL of a feed or sitemap path. It is built from
+// configuration rather than the request, whose Host header clients control.
+func (s *FeedService) URL(path string) string {
+	return s.config.BaseURL + path
+}
+
+// PostsFeed returns a feed of the latest published posts. feedURL is where
+// the feed is served.
+func (s *FeedService) PostsFeed(feedURL string) (*feed.Feed, error) {
+	f := &feed.Feed{
+		Title:       s.app.Name,
+		Description: "Latest posts on " + s.app.Name,
+		Link:        s.app.FrontendURL,
+		FeedURL:     feedURL,
+	}
+	return s.build(f, s.published())
+}
+
+// AuthorFeed returns a feed of the latest posts published by a user
+func (s *FeedService) AuthorFeed(username, feedURL string) (*feed.Feed, error) {
+	var user models.User
+	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	f := &feed.Feed{
+		Title:       fmt.Sprintf("Posts by %s - %s", authorName(&user), s.app.Name),
+		Description: fmt.Sprintf("Latest posts by %s on %s", authorName(&user), s.app.Name),
+		Link:        s.app.FrontendURL + "/authors/" + user.Username,
+		FeedURL:     feedURL,
+	}
+	return s.build(f, s.published().Where("posts.user_id = ?", user.ID))
+}
+
+// TagFeed returns a feed of the latest published posts with a tag
+func (s *FeedService) TagFeed(slug, feedURL string) (*feed.Feed, error) {
+	var tag models.Tag
+	if err := s.db.Where("slug = ?", slug).First(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	f := &feed.Feed{
+		Title:       fmt.Sprintf("Posts tagged %s - %s", tag.Name, s.app.Name),
+		Description: fmt.Sprintf("Latest posts tagged %s on %s", tag.Name, s.app.Name),
+		Link:        s.app.FrontendURL + "/tags/" + tag.Slug,
+		FeedURL:     feedURL,
+	}
+	tagged := s.db.Table("post_tags").Select("post_id").Where("tag_id = ?", tag.ID)
+	return s.build(f, s.published().Where("posts.id IN (?)", tagged))
+}
+
+// SitemapIndex lists the post sitemaps. sitemapURL returns where the
+// sitemap with the given page number is served.
+func (s *FeedService) SitemapIndex(sitemapURL func(page int) string) ([]feed.SitemapURL, error) {
+	var total int64
+	if err := s.published().Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count published posts: %w", err)
+	}
+
+	// Sitemaps are only as fresh as the latest change to any post
+	var latest models.Post
+	err := s.published().Select("updated_at").Order("updated_at DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest post: %w", err)
+	}
+
+	pages := int((total + feed.SitemapMaxURLs - 1) / feed.SitemapMaxURLs)
+	if pages == 0 {
+		pages = 1
+	}
+
+	sitemaps := make([]feed.SitemapURL, pages)
+	for i := range sitemaps {
+		sitemaps[i] = feed.SitemapURL{Loc: sitemapURL(i + 1), LastMod: latest.UpdatedAt}
+	}
+	return sitemaps, nil
+}
+
+// Sitemap lists the pages of the published posts on a sitemap page. Pages
+// are numbered from 1; pages past the last one are not found.
+func (s *FeedService) Sitemap(page int) ([]feed.SitemapURL, error) {
+	var posts []models.Post
+	err := s.published().
+		Select("id", "slug", "updated_at").
+		Order("posts.id").
+		Offset((page - 1) * feed.SitemapMaxURLs).
+		Limit(feed.SitemapMaxURLs).
+		Find(&posts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch posts: %w", err)
+	}
+	if len(posts) == 0 && page > 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	urls := make([]feed.SitemapURL, len(posts))
+	for i := range posts {
+		urls[i] = feed.SitemapURL{Loc: s.postURL(&posts[i]), LastMod: posts[i].UpdatedAt}
+	}
+	return urls, nil
+}
+
+// published returns a query on published posts
+func (s *FeedService) published() *gorm.DB {
+	return s.db.Model(&models.Post{}).Where("posts.status = ?", models.PostStatusPublished)
+}
+
+// build fills the feed with the latest posts matched by query
+func (s *FeedService) build(f *feed.Feed, query *gorm.DB) (*feed.Feed, error) {
+	var posts []models.Post
+	err := query.Preload("User").Preload("Tags").
+		Order("posts.published_at DESC").
+		Order("posts.id DESC").
+		Limit(s.config.Size).
+		Find(&posts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch posts: %w", err)
+	}
+
+	for i := range posts {
+		post := &posts[i]
+
+		published := post.CreatedAt
+		if post.PublishedAt != nil {
+			published = *post.PublishedAt
+		}
+
+		item := feed.Item{
+			ID:          feed.TagURI(s.app.FrontendURL, post.CreatedAt, fmt.Sprintf("posts/%d", post.ID)),
+			Title:       post.Title,
+			Link:        s.postURL(post),
+			Author:      authorName(&post.User),
+			ContentHTML: post.ContentHTML,
+			Published:   published,
+			Updated:     post.UpdatedAt,
+		}
+		for _, tag := range post.Tags {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+		f.Items = append(f.Items, item)
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+	}
+	return f, nil
+}
+
+// postURL returns the link to a post's page
+func (s *FeedService) postURL(post *models.Post) string {
+	return s.app.FrontendURL + "/posts/" + post.Slug
+}
+
+// withHost replaces the host of a URL, keeping its port
+func withHost(rawURL, host string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	u.Host = host
+	return u.String()
+}
+
+// authorName returns the name a user is credited by
+func authorName(user *models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Username
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"go-backend/internal/models"
 	"io"
@@ -55,6 +56,16 @@ func NewFileService(db *gorm.DB, config FileUploadConfig, auditService *AuditSer
 	}
 }
 
+// WithContext returns a copy of the service whose queries use ctx
+func (s *FileService) WithContext(ctx context.Context) *FileService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
 // FileValidationError represents file validation errors
 type FileValidationError struct {
 	Field   string `json:"field"`
@@ -73,8 +84,13 @@ type UploadResult struct {
 
 // ValidateFile validates a file before upload
 func (s *FileService) ValidateFile(fileHeader *multipart.FileHeader) error {
+	return s.CheckFile(fileHeader.Filename, fileHeader.Size)
+}
+
+// CheckFile validates the name and size of a file before it is stored
+func (s *FileService) CheckFile(name string, size int64) error {
 	// Check file size
-	if fileHeader.Size > s.maxFileSize {
+	if size > s.maxFileSize {
 		return FileValidationError{
 			Field:   "file_size",
 			Message: fmt.Sprintf("File size exceeds maximum allowed size of %d bytes", s.maxFileSize),
@@ -82,7 +98,7 @@ func (s *FileService) ValidateFile(fileHeader *multipart.FileHeader) error {
 	}
 
 	// Check file extension
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	ext := strings.ToLower(filepath.Ext(name))
 	if ext == "" {
 		return FileValidationError{
 			Field:   "file_extension",
@@ -120,8 +136,19 @@ func (s *FileService) UploadFile(fileHeader *multipart.FileHeader, userID uint,
 	}
 	defer file.Close()
 
+	return s.StoreFile(file, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), userID, category)
+}
+
+// StoreFile stores a file read from r under the original name and stores its
+// metadata. The file is checked with CheckFile first, and rejected if more
+// than the maximum size is read, as the size of r is only known once read.
+func (s *FileService) StoreFile(r io.Reader, name, mimeType string, userID uint, category string) (*UploadResult, error) {
+	if err := s.CheckFile(name, 0); err != nil {
+		return nil, err
+	}
+
 	// Generate unique filename
-	ext := filepath.Ext(fileHeader.Filename)
+	ext := filepath.Ext(name)
 	fileName := fmt.Sprintf("%s%s", uuid.New().String(), ext)
 
 	// Create full file path
@@ -135,12 +162,16 @@ func (s *FileService) UploadFile(fileHeader *multipart.FileHeader, userID uint,
 	defer dst.Close()
 
 	// Copy the uploaded file to destination
-	_, err = io.Copy(dst, file)
+	written, err := io.Copy(dst, io.LimitReader(r, s.maxFileSize+1))
 	if err != nil {
 		// Clean up the created file if copy fails
 		os.Remove(filePath)
 		return nil, fmt.Errorf("failed to save file: %w", err)
 	}
+	if written > s.maxFileSize {
+		os.Remove(filePath)
+		return nil, s.CheckFile(name, written)
+	}
 
 	// Get file info
 	fileInfo, err := dst.Stat()
@@ -151,11 +182,12 @@ func (s *FileService) UploadFile(fileHeader *multipart.FileHeader, userID uint,
 	// Create file upload record
 	fileUpload := &models.FileUpload{
 		UserID:       userID,
-		OriginalName: fileHeader.Filename,
+		OriginalName: name,
 		FileName:     fileName,
 		FilePath:     filePath,
 		FileSize:     fileInfo.Size(),
-		MimeType:     fileHeader.Header.Get("Content-Type"),
+		MimeType:     mimeType,
+		FileType:     fileTypeOf(mimeType),
 		Category:     category,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -200,6 +232,21 @@ func (s *FileService) GetFile(fileID uint) (*models.FileUpload, error) {
 	return &fileUpload, err
 }
 
+// GetFileForUser retrieves file metadata the user may read
+func (s *FileService) GetFileForUser(fileID, userID uint) (*models.FileUpload, error) {
+	fileUpload, err := s.GetFile(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %w", err)
+	}
+
+	// Check the user may access the file
+	if err := authorize(s.db, userID, "read", fileUpload); err != nil {
+		return nil, fmt.Errorf("unauthorized to access this file: %w", err)
+	}
+
+	return fileUpload, nil
+}
+
 // GetUserFiles retrieves all files uploaded by a specific user
 func (s *FileService) GetUserFiles(userID uint, category string, limit, offset int) ([]models.FileUpload, error) {
 	var files []models.FileUpload
@@ -230,7 +277,7 @@ func (s *FileService) GetFilesByCategory(category string, limit, offset int) ([]
 	return files, err
 }
 
-// DeleteFile deletes a file and its metadata
+// DeleteFile moves a file to the trash
 func (s *FileService) DeleteFile(fileID, userID uint) error {
 	// Get the file record
 	var fileUpload models.FileUpload
@@ -238,24 +285,20 @@ func (s *FileService) DeleteFile(fileID, userID uint) error {
 		return fmt.Errorf("file not found: %w", err)
 	}
 
-	// Check if user owns the file or is admin
-	if fileUpload.UserID != userID {
-		var user models.User
-		if err := s.db.First(&user, userID).Error; err != nil {
-			return fmt.Errorf("unauthorized")
-		}
-		if user.Role != models.RoleAdmin {
-			return fmt.Errorf("unauthorized to delete this file")
-		}
-	}
-
-	// Delete the physical file
-	if err := os.Remove(fileUpload.FilePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete physical file: %w", err)
+	// Check the user may delete the file
+	if err := authorize(s.db, userID, "delete", &fileUpload); err != nil {
+		return fmt.Errorf("unauthorized to delete this file: %w", err)
 	}
 
-	// Delete the database record
-	if err := s.db.Delete(&fileUpload).Error; err != nil {
+	// Move the file to the trash. Its bytes and attachments are kept until
+	// it is purged.
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&fileUpload).Error; err != nil {
+			return err
+		}
+		return recordDeletion(tx, &models.FileUpload{}, []uint{fileID}, userID)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete file record: %w", err)
 	}
 
@@ -285,15 +328,9 @@ func (s *FileService) GetFileContent(fileID, userID uint) (*models.FileUpload, *
 		return nil, nil, fmt.Errorf("file not found: %w", err)
 	}
 
-	// Check if user owns the file or is admin (for private files)
-	if fileUpload.UserID != userID {
-		var user models.User
-		if err := s.db.First(&user, userID).Error; err != nil {
-			return nil, nil, fmt.Errorf("unauthorized")
-		}
-		if user.Role != models.RoleAdmin && user.Role != models.RoleModerator {
-			return nil, nil, fmt.Errorf("unauthorized to access this file")
-		}
+	// Check the user may access the file
+	if err := authorize(s.db, userID, "read", &fileUpload); err != nil {
+		return nil, nil, fmt.Errorf("unauthorized to access this file: %w", err)
 	}
 
 	// Open the file
@@ -326,15 +363,9 @@ func (s *FileService) UpdateFileMetadata(fileID, userID uint, updates map[string
 		return fmt.Errorf("file not found: %w", err)
 	}
 
-	// Check if user owns the file or is admin
-	if fileUpload.UserID != userID {
-		var user models.User
-		if err := s.db.First(&user, userID).Error; err != nil {
-			return fmt.Errorf("unauthorized")
-		}
-		if user.Role != models.RoleAdmin {
-			return fmt.Errorf("unauthorized to update this file")
-		}
+	// Check the user may update the file
+	if err := authorize(s.db, userID, "update", &fileUpload); err != nil {
+		return fmt.Errorf("unauthorized to update this file: %w", err)
 	}
 
 	// Add update timestamp
@@ -431,3 +462,17 @@ func (s *FileService) GetFileStats() (map[string]interface{}, error) {
 func (s *FileService) GetUploadedFileURL(fileName string) string {
 	return fmt.Sprintf("%s/%s", strings.TrimRight(s.staticURL, "/"), fileName)
 }
+
+// fileTypeOf groups a MIME type into the broad file type stored with uploads
+func fileTypeOf(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "audio"
+	default:
+		return "document"
+	}
+}
@@ -0,0 +1,555 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCannotFollowSelf is returned when a user tries to follow themselves
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+	// ErrInvalidCursor is returned when a feed cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCannotBlockSelf is returned when a user tries to block themselves
+	ErrCannotBlockSelf = errors.New("you cannot block yourself")
+	// ErrBlocked is returned when a user tries to follow a user they blocked
+	// or who blocked them
+	ErrBlocked = errors.New("you cannot follow this user")
+)
+
+// HomeFeed is a page of a user's home feed. NextCursor fetches the next page
+// and is empty on the last one.
+type HomeFeed struct {
+	Posts      []models.Post `json:"posts"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// FollowService manages who follows whom and builds users' home feeds from
+// the posts of the authors they follow. Home feeds are assembled on every
+// read, except for users following at least the configured number of
+// authors, whose posts are copied into a materialised timeline as they are
+// published.
+type FollowService struct {
+	*CRUDService[models.Follow]
+	db                  *gorm.DB
+	notificationService *NotificationService
+	config              config.FollowsConfig
+	logger              *logger.Logger
+}
+
+// NewFollowService creates a new follow service
+func NewFollowService(db *gorm.DB, notificationService *NotificationService, cfg config.FollowsConfig, logger *logger.Logger) *FollowService {
+	return &FollowService{
+		CRUDService:         NewCRUDService[models.Follow](db),
+		db:                  db,
+		notificationService: notificationService,
+		config:              cfg,
+		logger:              logger,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *FollowService) WithContext(ctx context.Context) *FollowService {
+	var notificationService *NotificationService
+	if s.notificationService != nil {
+		notificationService = s.notificationService.WithContext(ctx)
+	}
+	return &FollowService{
+		CRUDService:         s.CRUDService.WithContext(ctx),
+		db:                  s.db.WithContext(ctx),
+		notificationService: notificationService,
+		config:              s.config,
+		logger:              s.logger,
+	}
+}
+
+// Follow makes the follower follow the followee, or changes whether they are
+// notified of new posts if they already do
+func (s *FollowService) Follow(followerID, followeeID uint, notify bool) (*models.Follow, error) {
+	if followerID == followeeID {
+		return nil, ErrCannotFollowSelf
+	}
+	if err := authorize(s.db, followerID, "create", authz.Kind("follow")); err != nil {
+		return nil, fmt.Errorf("unauthorized to follow users: %w", err)
+	}
+
+	var followee models.User
+	if err := s.db.Where("is_active = ?", true).First(&followee, followeeID).Error; err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedEitherWay(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
+	var follow models.Follow
+	err = s.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).First(&follow).Error
+	if err == nil {
+		if follow.Notify != notify {
+			follow.Notify = notify
+			if err := s.db.Model(&follow).Update("notify", notify).Error; err != nil {
+				return nil, err
+			}
+		}
+		return &follow, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	follow = models.Follow{FollowerID: followerID, FolloweeID: followeeID, Notify: notify}
+	if err := s.db.Create(&follow).Error; err != nil {
+		return nil, err
+	}
+
+	// Copy the new author's posts into the follower's timeline, or build the
+	// whole timeline if this follow makes them a heavy follower
+	following, err := s.followingCount(followerID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !s.materialised(following):
+	case following == int64(s.config.TimelineThreshold):
+		err = s.rebuildTimeline(followerID)
+	default:
+		err = s.copyToTimeline(followerID, followeeID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update timeline: %w", err)
+	}
+
+	return &follow, nil
+}
+
+// Unfollow stops the follower following the followee. Unfollowing a user who
+// is not followed does nothing.
+func (s *FollowService) Unfollow(followerID, followeeID uint) error {
+	result := s.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.Follow{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	// Timelines of users who no longer follow enough authors are not read,
+	// so they are dropped rather than kept up to date
+	following, err := s.followingCount(followerID)
+	if err != nil {
+		return err
+	}
+	query := s.db.Where("user_id = ?", followerID)
+	if s.materialised(following) {
+		query = query.Where("author_id = ?", followeeID)
+	}
+	if err := query.Delete(&models.TimelineEntry{}).Error; err != nil {
+		return fmt.Errorf("failed to update timeline: %w", err)
+	}
+	return nil
+}
+
+// Block makes the blocker block a user, and removes any follows between them
+func (s *FollowService) Block(blockerID, blockedID uint) (*models.Block, error) {
+	if blockerID == blockedID {
+		return nil, ErrCannotBlockSelf
+	}
+	if err := s.db.Select("id").First(&models.User{}, blockedID).Error; err != nil {
+		return nil, err
+	}
+
+	var block models.Block
+	err := s.db.Where(models.Block{BlockerID: blockerID, BlockedID: blockedID}).FirstOrCreate(&block).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Unfollow(blockerID, blockedID); err != nil {
+		return nil, err
+	}
+	if err := s.Unfollow(blockedID, blockerID); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// Unblock removes the blocker's block of a user. Unblocking a user who is
+// not blocked does nothing.
+func (s *FollowService) Unblock(blockerID, blockedID uint) error {
+	return s.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.Block{}).Error
+}
+
+// Blocked pages through the users a user has blocked, latest first
+func (s *FollowService) Blocked(userID uint, options QueryOptions) (*PaginatedResult[models.BlockedUser], error) {
+	blocks, err := NewCRUDService[models.Block](s.db).GetAll(QueryOptions{
+		Pagination: options.Pagination,
+		Filter:     FilterOptions{Filters: map[string]interface{}{"blocker_id": userID}},
+		Sort:       []SortOptions{{Field: "created_at", Direction: "desc"}, {Field: "id", Direction: "desc"}},
+		Preload:    []string{"Blocked"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.BlockedUser, 0, len(blocks.Data))
+	for _, block := range blocks.Data {
+		if block.Blocked == nil {
+			continue
+		}
+		users = append(users, models.BlockedUser{
+			UserProfile: block.Blocked.ToProfile(),
+			BlockedAt:   block.CreatedAt,
+		})
+	}
+
+	return &PaginatedResult[models.BlockedUser]{
+		Data:       users,
+		Total:      blocks.Total,
+		Page:       blocks.Page,
+		PageSize:   blocks.PageSize,
+		TotalPages: blocks.TotalPages,
+		HasNext:    blocks.HasNext,
+		HasPrev:    blocks.HasPrev,
+	}, nil
+}
+
+// Followers pages through the users following a user, latest first
+func (s *FollowService) Followers(userID uint, options QueryOptions) (*PaginatedResult[models.FollowUser], error) {
+	options.Filter.Filters = map[string]interface{}{"followee_id": userID}
+	options.Preload = []string{"Follower"}
+	return s.list(options, func(follow *models.Follow) *models.User { return follow.Follower })
+}
+
+// Following pages through the users a user follows, latest first
+func (s *FollowService) Following(userID uint, options QueryOptions) (*PaginatedResult[models.FollowUser], error) {
+	options.Filter.Filters = map[string]interface{}{"follower_id": userID}
+	options.Preload = []string{"Followee"}
+	return s.list(options, func(follow *models.Follow) *models.User { return follow.Followee })
+}
+
+// Stats counts a user's followers and followed users, and whether the viewer
+// follows them
+func (s *FollowService) Stats(userID, viewerID uint) (*models.FollowStats, error) {
+	if err := s.db.Select("id").First(&models.User{}, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var stats models.FollowStats
+	var err error
+	if stats.Followers, err = s.Count(map[string]interface{}{"followee_id": userID}); err != nil {
+		return nil, err
+	}
+	if stats.Following, err = s.followingCount(userID); err != nil {
+		return nil, err
+	}
+	if viewerID != 0 && viewerID != userID {
+		followed, err := s.Count(map[string]interface{}{"follower_id": viewerID, "followee_id": userID})
+		if err != nil {
+			return nil, err
+		}
+		stats.Followed = followed > 0
+	}
+	return &stats, nil
+}
+
+// Feed returns a page of the user's home feed: the published posts of the
+// authors they follow, latest first. cursor is the NextCursor of the
+// previous page, or empty for the first.
+func (s *FollowService) Feed(userID uint, cursor string, limit int) (*HomeFeed, error) {
+	query := s.db.Model(&models.Post{}).Where("posts.status = ?", models.PostStatusPublished)
+	if cursor != "" {
+		publishedAt, postID, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("posts.published_at < ? OR (posts.published_at = ? AND posts.id < ?)",
+			publishedAt, publishedAt, postID)
+	}
+
+	following, err := s.followingCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.materialised(following) {
+		// Timelines are built when a user starts following enough authors,
+		// or here for users who did before materialising was enabled
+		built, err := s.timelineBuilt(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !built {
+			if err := s.rebuildTimeline(userID); err != nil {
+				return nil, fmt.Errorf("failed to build timeline: %w", err)
+			}
+		}
+		query = query.Joins("JOIN timeline_entries ON timeline_entries.post_id = posts.id").
+			Where("timeline_entries.user_id = ?", userID)
+	} else {
+		followees, err := s.followeeIDs(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(followees) == 0 {
+			return &HomeFeed{Posts: []models.Post{}}, nil
+		}
+		query = query.Where("posts.user_id IN ?", followees)
+	}
+
+	var posts []models.Post
+	err = query.Preload("User").
+		Order("posts.published_at DESC").
+		Order("posts.id DESC").
+		Limit(limit + 1).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HomeFeed{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		last := page.Posts[limit-1]
+		publishedAt := last.CreatedAt
+		if last.PublishedAt != nil {
+			publishedAt = *last.PublishedAt
+		}
+		page.NextCursor = encodeFeedCursor(publishedAt, last.ID)
+	}
+
+	ids := make([]uint, len(page.Posts))
+	for i, post := range page.Posts {
+		ids[i] = post.ID
+	}
+	counts, err := reactionCounts(s.db, models.ReactionTargetPost, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Posts {
+		page.Posts[i].Reactions = counts[page.Posts[i].ID]
+	}
+
+	return page, nil
+}
+
+// PostPublished delivers a post published for the first time to the
+// author's followers: it is added to materialised timelines, and followers
+// who asked for it are notified. Failures are logged, as the post is
+// published either way.
+func (s *FollowService) PostPublished(post *models.Post) {
+	if err := s.fanOut(post); err != nil {
+		s.logger.WithError(err).WithField("post_id", post.ID).Error("Failed to add post to timelines")
+	}
+	if err := s.notifyFollowers(post); err != nil {
+		s.logger.WithError(err).WithField("post_id", post.ID).Error("Failed to notify followers of post")
+	}
+}
+
+// fanOut adds the post to the timelines of the author's heavy followers
+func (s *FollowService) fanOut(post *models.Post) error {
+	if s.config.TimelineThreshold <= 0 {
+		return nil
+	}
+
+	var followerIDs []uint
+	err := s.db.Model(&models.Follow{}).
+		Where("follower_id IN (?)", s.db.Model(&models.Follow{}).Select("follower_id").Where("followee_id = ?", post.UserID)).
+		Group("follower_id").
+		Having("COUNT(*) >= ?", s.config.TimelineThreshold).
+		Pluck("follower_id", &followerIDs).Error
+	if err != nil || len(followerIDs) == 0 {
+		return err
+	}
+
+	entries := make([]models.TimelineEntry, len(followerIDs))
+	for i, followerID := range followerIDs {
+		entries[i] = models.TimelineEntry{TenantID: post.TenantID, UserID: followerID, PostID: post.ID, AuthorID: post.UserID}
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 500).Error
+}
+
+// notifyFollowers notifies the author's followers who asked for it of the post
+func (s *FollowService) notifyFollowers(post *models.Post) error {
+	if !s.config.NotifyPosts || s.notificationService == nil {
+		return nil
+	}
+
+	var followerIDs []uint
+	err := s.db.Model(&models.Follow{}).
+		Where("followee_id = ? AND notify = ?", post.UserID, true).
+		Pluck("follower_id", &followerIDs).Error
+	if err != nil || len(followerIDs) == 0 {
+		return err
+	}
+
+	var author models.User
+	if err := s.db.First(&author, post.UserID).Error; err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"kind":      "post",
+		"post_id":   post.ID,
+		"slug":      post.Slug,
+		"author_id": post.UserID,
+	}
+	for _, followerID := range followerIDs {
+		err := s.notificationService.NotifyInApp(followerID,
+			fmt.Sprintf("New post by %s", author.Username), post.Title, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list pages through follows and returns the users picked from them
+func (s *FollowService) list(options QueryOptions, user func(*models.Follow) *models.User) (*PaginatedResult[models.FollowUser], error) {
+	options.Sort = []SortOptions{{Field: "created_at", Direction: "desc"}, {Field: "id", Direction: "desc"}}
+	follows, err := s.GetAll(options)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.FollowUser, 0, len(follows.Data))
+	for i := range follows.Data {
+		followed := user(&follows.Data[i])
+		if followed == nil {
+			continue
+		}
+		users = append(users, models.FollowUser{
+			UserProfile: followed.ToProfile(),
+			FollowedAt:  follows.Data[i].CreatedAt,
+		})
+	}
+
+	return &PaginatedResult[models.FollowUser]{
+		Data:       users,
+		Total:      follows.Total,
+		Page:       follows.Page,
+		PageSize:   follows.PageSize,
+		TotalPages: follows.TotalPages,
+		HasNext:    follows.HasNext,
+		HasPrev:    follows.HasPrev,
+	}, nil
+}
+
+// blockedEitherWay reports whether either user has blocked the other
+func (s *FollowService) blockedEitherWay(userID, otherID uint) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// materialised reports whether a user following that many authors has a
+// materialised timeline
+func (s *FollowService) materialised(following int64) bool {
+	return s.config.TimelineThreshold > 0 && following >= int64(s.config.TimelineThreshold)
+}
+
+// followingCount counts the authors a user follows
+func (s *FollowService) followingCount(userID uint) (int64, error) {
+	return s.Count(map[string]interface{}{"follower_id": userID})
+}
+
+// followeeIDs returns the IDs of the authors a user follows
+func (s *FollowService) followeeIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := s.db.Model(&models.Follow{}).Where("follower_id = ?", userID).Pluck("followee_id", &ids).Error
+	return ids, err
+}
+
+// timelineBuilt reports whether the user's timeline has any entries
+func (s *FollowService) timelineBuilt(userID uint) (bool, error) {
+	var ids []uint
+	err := s.db.Model(&models.TimelineEntry{}).Where("user_id = ?", userID).Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// rebuildTimeline replaces the user's timeline with the latest posts of the
+// authors they follow
+func (s *FollowService) rebuildTimeline(userID uint) error {
+	followees, err := s.followeeIDs(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TimelineEntry{}).Error; err != nil {
+			return err
+		}
+		return s.copyPosts(tx, userID, followees)
+	})
+}
+
+// copyToTimeline copies an author's latest posts into the user's timeline
+func (s *FollowService) copyToTimeline(userID, authorID uint) error {
+	return s.copyPosts(s.db, userID, []uint{authorID})
+}
+
+// copyPosts copies the latest published posts of the authors into the user's
+// timeline
+func (s *FollowService) copyPosts(db *gorm.DB, userID uint, authorIDs []uint) error {
+	if len(authorIDs) == 0 {
+		return nil
+	}
+
+	var posts []models.Post
+	err := db.Select("id, tenant_id, user_id").
+		Where("status = ? AND user_id IN ?", models.PostStatusPublished, authorIDs).
+		Order("published_at DESC").
+		Order("id DESC").
+		Limit(s.config.TimelineSize).
+		Find(&posts).Error
+	if err != nil || len(posts) == 0 {
+		return err
+	}
+
+	entries := make([]models.TimelineEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = models.TimelineEntry{TenantID: post.TenantID, UserID: userID, PostID: post.ID, AuthorID: post.UserID}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 500).Error
+}
+
+// encodeFeedCursor encodes the position of the last post on a feed page
+func encodeFeedCursor(publishedAt time.Time, postID uint) string {
+	raw := strconv.FormatInt(publishedAt.UnixNano(), 10) + ":" + strconv.FormatUint(uint64(postID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor decodes a feed cursor into the publication time and ID of
+// the post the next page starts after
+func decodeFeedCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	postID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.Unix(0, unixNano).UTC(), uint(postID), nil
+}
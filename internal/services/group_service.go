@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GroupService manages groups, their members and the roles and permissions
+// granted to them
+type GroupService struct {
+	db                *gorm.DB
+	auditService      *AuditService
+	permissionService *PermissionService
+}
+
+// NewGroupService creates a new group service
+func NewGroupService(db *gorm.DB, auditService *AuditService, permissionService *PermissionService) *GroupService {
+	return &GroupService{
+		db:                db,
+		auditService:      auditService,
+		permissionService: permissionService,
+	}
+}
+
+// ListGroups retrieves all groups with their grants
+func (s *GroupService) ListGroups() ([]models.Group, error) {
+	var groups []models.Group
+	err := s.db.Preload("Roles").Preload("Permissions.Permission").
+		Order("name ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	return groups, nil
+}
+
+// GetGroup retrieves a group by ID with its parent and grants
+func (s *GroupService) GetGroup(id uint) (*models.Group, error) {
+	var group models.Group
+	err := s.db.Preload("Parent").Preload("Roles").Preload("Permissions.Permission").
+		First(&group, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("group not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &group, nil
+}
+
+// CreateGroup creates a new group, optionally nested under a parent group
+func (s *GroupService) CreateGroup(actorID uint, req *models.GroupCreateRequest) (*models.Group, error) {
+	name := strings.TrimSpace(req.Name)
+
+	var count int64
+	s.db.Model(&models.Group{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("group already exists")
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.GetGroup(*req.ParentID); err != nil {
+			return nil, fmt.Errorf("parent group: %w", err)
+		}
+	}
+
+	group := &models.Group{
+		Name:        name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := s.db.Create(group).Error; err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionCreate, "group", group.ID, nil, map[string]interface{}{
+		"name":      group.Name,
+		"parent_id": group.ParentID,
+	})
+
+	return group, nil
+}
+
+// UpdateGroup updates a group's details or moves it under another parent
+func (s *GroupService) UpdateGroup(actorID, id uint, req *models.GroupUpdateRequest) (*models.Group, error) {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		var count int64
+		s.db.Model(&models.Group{}).Where("name = ? AND id <> ?", name, id).Count(&count)
+		if count > 0 {
+			return nil, errors.New("group already exists")
+		}
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if _, err := s.GetGroup(*req.ParentID); err != nil {
+				return nil, fmt.Errorf("parent group: %w", err)
+			}
+			isDescendant, err := s.isSelfOrDescendant(*req.ParentID, id)
+			if err != nil {
+				return nil, err
+			}
+			if isDescendant {
+				return nil, errors.New("group hierarchy cannot contain cycles")
+			}
+			updates["parent_id"] = *req.ParentID
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.Group{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update group: %w", err)
+		}
+
+		s.permissionService.InvalidateCache()
+		s.logChange(actorID, ActionUpdate, "group", id, map[string]interface{}{
+			"name":      group.Name,
+			"parent_id": group.ParentID,
+		}, updates)
+	}
+
+	return s.GetGroup(id)
+}
+
+// DeleteGroup deletes a group, its memberships and grants. Child groups are
+// moved up to the deleted group's parent.
+func (s *GroupService) DeleteGroup(actorID, id uint) error {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Group{}).Where("parent_id = ?", id).Update("parent_id", group.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupPermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionDelete, "group", id, map[string]interface{}{
+		"name": group.Name,
+	}, nil)
+
+	return nil
+}
+
+// ListMembers lists the direct members of a group
+func (s *GroupService) ListMembers(groupID uint) ([]models.GroupMember, error) {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	var members []models.GroupMember
+	err := s.db.Preload("User").
+		Where("group_id = ?", groupID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch group members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds a user to a group
+func (s *GroupService) AddMember(actorID, groupID, userID uint) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	var count int64
+	s.db.Model(&models.GroupMember{}).Where("group_id = ? AND user_id = ?", groupID, userID).Count(&count)
+	if count > 0 {
+		return errors.New("user is already a member")
+	}
+
+	if err := s.db.Create(&models.GroupMember{GroupID: groupID, UserID: userID}).Error; err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	s.permissionService.InvalidateUser(userID)
+	s.logChange(actorID, ActionMembershipChange, "group_member", groupID, nil, map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+	})
+
+	return nil
+}
+
+// RemoveMember removes a user from a group
+func (s *GroupService) RemoveMember(actorID, groupID, userID uint) error {
+	result := s.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.GroupMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove group member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user is not a member of this group")
+	}
+
+	s.permissionService.InvalidateUser(userID)
+	s.logChange(actorID, ActionMembershipChange, "group_member", groupID, map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+	}, nil)
+
+	return nil
+}
+
+// ListUserGroups lists the groups a user belongs to directly
+func (s *GroupService) ListUserGroups(userID uint) ([]models.Group, error) {
+	var groups []models.Group
+	err := s.db.Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("group_members.user_id = ?", userID).
+		Preload("Roles").
+		Order("groups.name ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user groups: %w", err)
+	}
+	return groups, nil
+}
+
+// GrantRole grants a role to a group
+func (s *GroupService) GrantRole(actorID, groupID uint, role models.Role) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+
+	grant := &models.GroupRole{GroupID: groupID, Role: role}
+	if err := s.db.Where(grant).FirstOrCreate(grant).Error; err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionRoleChange, "group_grant", groupID, nil, map[string]interface{}{
+		"group_id": groupID,
+		"role":     role,
+	})
+
+	return nil
+}
+
+// RevokeRole revokes a role from a group
+func (s *GroupService) RevokeRole(actorID, groupID uint, role models.Role) error {
+	result := s.db.Where("group_id = ? AND role = ?", groupID, role).Delete(&models.GroupRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("group does not have this role")
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionRoleChange, "group_grant", groupID, map[string]interface{}{
+		"group_id": groupID,
+		"role":     role,
+	}, nil)
+
+	return nil
+}
+
+// GrantPermission grants a permission directly to a group
+func (s *GroupService) GrantPermission(actorID, groupID uint, name string) error {
+	if _, err := s.GetGroup(groupID); err != nil {
+		return err
+	}
+
+	permission, err := s.permissionService.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	grant := &models.GroupPermission{GroupID: groupID, PermissionID: permission.ID}
+	if err := s.db.Where(grant).FirstOrCreate(grant).Error; err != nil {
+		return fmt.Errorf("failed to grant permission: %w", err)
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionPermissionChange, "group_grant", groupID, nil, map[string]interface{}{
+		"group_id":   groupID,
+		"permission": permission.Name,
+	})
+
+	return nil
+}
+
+// RevokePermission revokes a permission granted directly to a group
+func (s *GroupService) RevokePermission(actorID, groupID uint, name string) error {
+	permission, err := s.permissionService.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Where("group_id = ? AND permission_id = ?", groupID, permission.ID).Delete(&models.GroupPermission{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke permission: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("group does not have this permission")
+	}
+
+	s.permissionService.InvalidateCache()
+	s.logChange(actorID, ActionPermissionChange, "group_grant", groupID, map[string]interface{}{
+		"group_id":   groupID,
+		"permission": permission.Name,
+	}, nil)
+
+	return nil
+}
+
+// isSelfOrDescendant checks if candidate is the group itself or nested
+// anywhere below it
+func (s *GroupService) isSelfOrDescendant(candidate, groupID uint) (bool, error) {
+	var groups []models.Group
+	if err := s.db.Select("id", "parent_id").Find(&groups).Error; err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	parents := make(map[uint]*uint, len(groups))
+	for _, group := range groups {
+		parents[group.ID] = group.ParentID
+	}
+
+	seen := make(map[uint]bool)
+	for id := &candidate; id != nil && !seen[*id]; id = parents[*id] {
+		if *id == groupID {
+			return true, nil
+		}
+		seen[*id] = true
+	}
+	return false, nil
+}
+
+// logChange records a group change in the audit trail
+func (s *GroupService) logChange(actorID uint, action AuditAction, entityType string, groupID uint, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, action, AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(groupID), 10),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-backend/internal/bundle"
+	"go-backend/internal/models"
+	"go-backend/internal/wxr"
+)
+
+// zipMagic starts every zip archive
+var zipMagic = []byte("PK\x03\x04")
+
+// importPost is a post read from a bundle or WordPress export, as it was
+// found. Posts that cannot be imported at all say why in skip; warnings note
+// what was lost reading the others.
+type importPost struct {
+	title       string
+	slug        string
+	author      importAuthor
+	status      models.PostStatus
+	createdAt   time.Time
+	publishAt   *time.Time
+	publishedAt *time.Time
+	tags        []string
+	categories  []string
+	content     string
+	files       []importFile
+	comments    []importComment
+
+	commentsRequireApproval bool
+
+	skip     string
+	warnings []string
+}
+
+// importAuthor identifies the author of an imported post or comment by
+// username or email. name is how the author is reported.
+type importAuthor struct {
+	username string
+	email    string
+	name     string
+}
+
+// importComment is a comment on an imported post. IDs link replies to their
+// parent within the post.
+type importComment struct {
+	id        uint
+	parent    uint
+	author    importAuthor
+	status    models.CommentStatus
+	createdAt time.Time
+	content   string
+	files     []importFile
+}
+
+// importFile is a file attached to an imported post or comment. Links to it
+// in the post and its comments match links.
+type importFile struct {
+	name     string
+	mimeType string
+	caption  string
+	file     *zip.File
+	limit    *bundle.Limit
+	links    *regexp.Regexp
+}
+
+// open opens the file within the limit of its archive
+func (f *importFile) open() (io.ReadCloser, error) {
+	return f.limit.Open(f.file)
+}
+
+// readImport reads the posts of a bundle or of a WordPress export, which may
+// come alone or zipped with its uploads. The files of an archive read no
+// more than MaxUnpackedSize bytes between them, including the attached files
+// stored later.
+func (s *ImportService) readImport(r io.ReaderAt, size int64) (string, []importPost, error) {
+	magic := make([]byte, len(zipMagic))
+	if _, err := r.ReadAt(magic, 0); err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	if !bytes.Equal(magic, zipMagic) {
+		export, err := wxr.Parse(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		return models.ImportFormatWXR, wxrPosts(export, nil, nil), nil
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	limit := bundle.NewLimit(s.config.MaxUnpackedSize)
+	posts, err := bundle.Read(archive, s.config.MaxSize, limit)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if len(posts) > 0 {
+		return models.ImportFormatBundle, bundlePosts(archive, limit, posts), nil
+	}
+
+	// An archive without posts may hold a WordPress export and its uploads
+	for _, file := range archive.File {
+		if path.Ext(file.Name) != ".xml" || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		data, err := limit.ReadFile(file, s.config.MaxSize)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, file.Name, err)
+		}
+		export, err := wxr.Parse(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, file.Name, err)
+		}
+		return models.ImportFormatWXR, wxrPosts(export, archive, limit), nil
+	}
+	return "", nil, ErrInvalidImport
+}
+
+// bundlePosts converts the posts of a bundle, finding their attached files
+// in the archive
+func bundlePosts(archive *zip.Reader, limit *bundle.Limit, posts []bundle.Post) []importPost {
+	imported := make([]importPost, 0, len(posts))
+	for _, post := range posts {
+		p := importPost{
+			title:       post.Title,
+			slug:        post.Slug,
+			author:      bundleAuthor(post.Author),
+			status:      models.PostStatus(post.Status),
+			createdAt:   post.CreatedAt,
+			publishAt:   post.PublishAt,
+			publishedAt: post.PublishedAt,
+			tags:        post.Tags,
+			categories:  post.Categories,
+			content:     post.Content,
+
+			commentsRequireApproval: post.CommentsRequireApproval,
+		}
+		p.files = bundleFiles(archive, limit, post.Attachments, &p.warnings)
+		for _, comment := range post.Comments {
+			p.comments = append(p.comments, importComment{
+				id:        comment.ID,
+				parent:    comment.Parent,
+				author:    bundleAuthor(comment.Author),
+				status:    models.CommentStatus(comment.Status),
+				createdAt: comment.CreatedAt,
+				content:   comment.Content,
+				files:     bundleFiles(archive, limit, comment.Attachments, &p.warnings),
+			})
+		}
+		imported = append(imported, p)
+	}
+	return imported
+}
+
+// bundleFiles finds attached files in a bundle. Links to a file are relative
+// to the post's Markdown file, or to the root of the bundle.
+func bundleFiles(archive *zip.Reader, limit *bundle.Limit, attachments []bundle.Attachment, warnings *[]string) []importFile {
+	var files []importFile
+	for _, attachment := range attachments {
+		file := zipFile(archive, attachment.Path)
+		if file == nil {
+			*warnings = append(*warnings, fmt.Sprintf("attachment %s is missing from the bundle", attachment.Path))
+			continue
+		}
+
+		name := attachment.Name
+		if name == "" {
+			name = path.Base(attachment.Path)
+		}
+		mimeType := attachment.MimeType
+		if mimeType == "" {
+			mimeType = mime.TypeByExtension(path.Ext(name))
+		}
+		files = append(files, importFile{
+			name:     name,
+			mimeType: mimeType,
+			caption:  attachment.Caption,
+			file:     file,
+			limit:    limit,
+			links:    regexp.MustCompile(`(?:\.\./)?` + regexp.QuoteMeta(attachment.Path)),
+		})
+	}
+	return files
+}
+
+// bundleAuthor identifies the author of a bundled post or comment
+func bundleAuthor(author bundle.Author) importAuthor {
+	name := author.Username
+	if name == "" {
+		name = author.Email
+	}
+	return importAuthor{username: author.Username, email: author.Email, name: name}
+}
+
+// wxrPosts converts the posts of a WordPress export. Pages are reported but
+// not imported, and trashed posts and revisions are left out. The files of
+// attachments on a post or linked from it are looked up in archive, which is
+// nil for an export that came alone, and read within limit.
+func wxrPosts(export *wxr.Export, archive *zip.Reader, limit *bundle.Limit) []importPost {
+	authors := make(map[string]wxr.Author, len(export.Authors))
+	authorsByID := make(map[uint]wxr.Author, len(export.Authors))
+	for _, author := range export.Authors {
+		authors[author.Login] = author
+		authorsByID[author.ID] = author
+	}
+
+	var media []wxr.Item
+	for _, item := range export.Items {
+		if item.Type == wxr.TypeAttachment && item.AttachmentURL != "" {
+			media = append(media, item)
+		}
+	}
+
+	var posts []importPost
+	for _, item := range export.Items {
+		if item.Type != wxr.TypePost && item.Type != wxr.TypePage {
+			continue
+		}
+		if item.Status == "trash" || item.Status == "auto-draft" || item.Status == "inherit" {
+			continue
+		}
+
+		p := importPost{
+			title: html.UnescapeString(item.Title),
+			slug:  item.Name,
+			author: importAuthor{
+				username: item.Creator,
+				email:    authors[item.Creator].Email,
+				name:     item.Creator,
+			},
+			status:  models.PostStatusDraft,
+			content: item.Content,
+		}
+		if item.Type == wxr.TypePage {
+			p.skip = "WordPress pages are not imported"
+			posts = append(posts, p)
+			continue
+		}
+
+		date, dated := item.Time()
+		if dated {
+			p.createdAt = date
+		}
+		switch item.Status {
+		case "publish":
+			p.status = models.PostStatusPublished
+			if dated {
+				p.publishedAt = &date
+			}
+		case "future":
+			p.status = models.PostStatusScheduled
+			if dated {
+				p.publishAt = &date
+			}
+		case "pending":
+			p.status = models.PostStatusInReview
+		case "private":
+			p.warnings = append(p.warnings, "private post imported as a draft")
+		}
+
+		for _, tag := range item.Terms(wxr.DomainTag) {
+			p.tags = append(p.tags, html.UnescapeString(tag.Name))
+		}
+		for _, category := range item.Terms(wxr.DomainCategory) {
+			// Every post WordPress has not filed elsewhere is uncategorized
+			if category.Nicename != "uncategorized" {
+				p.categories = append(p.categories, category.Nicename)
+			}
+		}
+
+		for _, attachment := range media {
+			links := wxrLinks(attachment.AttachmentURL)
+			if attachment.Parent != item.ID && !links.MatchString(item.Content) {
+				continue
+			}
+			file := wxrUpload(archive, attachment.AttachmentURL)
+			if file == nil {
+				p.warnings = append(p.warnings, fmt.Sprintf("attachment %s is not included in the import; links to it are left as they are", attachment.AttachmentURL))
+				continue
+			}
+			name := path.Base(file.Name)
+			p.files = append(p.files, importFile{
+				name:     name,
+				mimeType: mime.TypeByExtension(path.Ext(name)),
+				file:     file,
+				limit:    limit,
+				links:    links,
+			})
+		}
+
+		for _, comment := range item.Comments {
+			if comment.Type == "pingback" || comment.Type == "trackback" {
+				continue
+			}
+			var status models.CommentStatus
+			switch comment.Approved {
+			case "1":
+				status = models.CommentStatusApproved
+			case "0":
+				status = models.CommentStatusPending
+			case "spam":
+				status = models.CommentStatusSpam
+			default:
+				continue
+			}
+
+			// Comments by WordPress users are mapped like posts, and those
+			// by visitors by their email
+			author := importAuthor{email: comment.AuthorEmail, name: comment.Author}
+			if user, ok := authorsByID[comment.UserID]; ok && comment.UserID != 0 {
+				author = importAuthor{username: user.Login, email: user.Email, name: user.Login}
+			}
+			createdAt, _ := comment.Time()
+			p.comments = append(p.comments, importComment{
+				id:        comment.ID,
+				parent:    comment.Parent,
+				author:    author,
+				status:    status,
+				createdAt: createdAt,
+				content:   comment.Content,
+			})
+		}
+
+		posts = append(posts, p)
+	}
+	return posts
+}
+
+// wxrLinks matches links to a WordPress upload over HTTP or HTTPS, including
+// links to the resized copies WordPress names after their size, such as
+// photo-300x200.jpg
+func wxrLinks(rawURL string) *regexp.Regexp {
+	link := rawURL
+	if i := strings.Index(link, "://"); i >= 0 {
+		link = link[i+1:]
+	}
+	ext := path.Ext(link)
+	return regexp.MustCompile(`(?:https?:)?` + regexp.QuoteMeta(strings.TrimSuffix(link, ext)) +
+		`(?:-\d+x\d+)?` + regexp.QuoteMeta(ext))
+}
+
+// wxrUpload finds the file of a WordPress upload in an archive by its path
+// below wp-content/uploads, or its whole path for uploads stored elsewhere
+func wxrUpload(archive *zip.Reader, rawURL string) *zip.File {
+	if archive == nil {
+		return nil
+	}
+	uploadPath := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		uploadPath = u.Path
+	}
+	uploadPath = strings.TrimPrefix(uploadPath, "/")
+	if i := strings.Index(uploadPath, "wp-content/uploads/"); i >= 0 {
+		uploadPath = uploadPath[i+len("wp-content/uploads/"):]
+	}
+	if uploadPath == "" {
+		return nil
+	}
+
+	for _, file := range archive.File {
+		if file.Name == uploadPath || strings.HasSuffix(file.Name, "/"+uploadPath) {
+			return file
+		}
+	}
+	return nil
+}
+
+// zipFile returns the file at a path in an archive, or nil
+func zipFile(archive *zip.Reader, name string) *zip.File {
+	for _, file := range archive.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
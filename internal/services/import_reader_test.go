@@ -0,0 +1,40 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadImportLimitsUnpackedSize(t *testing.T) {
+	// Many small entries that expand well past the archive's own size
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i < 20; i++ {
+		f, err := w.Create(fmt.Sprintf("posts/post-%d.md", i))
+		require.NoError(t, err)
+		_, err = f.Write([]byte("---\ntitle: Post\n---\n" + strings.Repeat("a", 10000)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	s := NewImportService(nil, nil, nil, nil, config.ImportsConfig{MaxSize: 1 << 20, MaxUnpackedSize: 100000}, config.CommentsConfig{}, config.AttachmentsConfig{})
+	_, _, err := s.readImport(bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, ErrInvalidImport)
+	assert.ErrorContains(t, err, "file too large")
+
+	s.config.MaxUnpackedSize = 1 << 20
+	format, posts, err := s.readImport(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportFormatBundle, format)
+	assert.Len(t, posts, 20)
+}
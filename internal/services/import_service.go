@@ -0,0 +1,760 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/markdown"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidImport is returned for imports that are neither a post bundle nor
+// a WordPress export
+var ErrInvalidImport = errors.New("not a post bundle or WordPress export")
+
+// ImportService imports posts from bundles made by ExportService and from
+// WordPress exports (WXR), with their comments and attached files.
+//
+// Authors are mapped to users by email, then username. Admins import posts
+// and comments for any user; everyone else imports posts as their own and
+// only their own comments. Posts whose author is not found are imported as
+// the importing user's, while comments by authors not found are left out.
+// Posts take their slug with them; a taken slug is a conflict, and the post
+// is skipped or given a free slug. Attached files are stored as uploads and
+// links to them rewritten to their attachments.
+//
+// Imported posts keep their dates and are not announced to followers or
+// mentioned users. A dry run reports what an import would do without
+// changing anything.
+type ImportService struct {
+	db           *gorm.DB
+	postService  *PostService
+	fileService  *FileService
+	auditService *AuditService
+	config       config.ImportsConfig
+	comments     config.CommentsConfig
+	attachments  config.AttachmentsConfig
+}
+
+// NewImportService creates a new import service
+func NewImportService(db *gorm.DB, postService *PostService, fileService *FileService, auditService *AuditService, cfg config.ImportsConfig, comments config.CommentsConfig, attachments config.AttachmentsConfig) *ImportService {
+	return &ImportService{
+		db:           db,
+		postService:  postService,
+		fileService:  fileService,
+		auditService: auditService,
+		config:       cfg,
+		comments:     comments,
+		attachments:  attachments,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ImportService) WithContext(ctx context.Context) *ImportService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.postService != nil {
+		clone.postService = s.postService.WithContext(ctx)
+	}
+	if s.fileService != nil {
+		clone.fileService = s.fileService.WithContext(ctx)
+	}
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// importPlan is an imported post checked and ready to be created, with its
+// report entry
+type importPlan struct {
+	entry    models.ImportedPost
+	post     *importPost
+	authorID uint
+	files    []plannedFile
+	comments []*plannedComment
+
+	tags       []string
+	categories []models.Category
+}
+
+// plannedComment is an imported comment ready to be created under parent.
+// id is set once it is created.
+type plannedComment struct {
+	comment *importComment
+	userID  uint
+	parent  *plannedComment
+	depth   int
+	files   []plannedFile
+	id      uint
+}
+
+// plannedFile is an imported file ready to be stored. upload is set once it
+// is stored.
+type plannedFile struct {
+	importFile
+	upload *models.FileUpload
+}
+
+// importer plans the posts of one import
+type importer struct {
+	s          *ImportService
+	user       *models.User
+	forOthers  bool
+	onConflict models.ImportConflictPolicy
+	slugs      map[string]bool
+	users      map[importAuthor]*models.User
+}
+
+// Import imports the posts of a post bundle or WordPress export, or only
+// reports what it would do in a dry run. A post that fails to import is
+// skipped and reported, leaving the posts imported before it.
+func (s *ImportService) Import(userID uint, fileHeader *multipart.FileHeader, req *models.ImportRequest) (*models.ImportReport, error) {
+	if err := authorize(s.db, userID, "create", authz.Kind("post")); err != nil {
+		return nil, fmt.Errorf("unauthorized to create posts: %w", err)
+	}
+	if fileHeader.Size > s.config.MaxSize {
+		return nil, FileValidationError{
+			Field:   "file_size",
+			Message: fmt.Sprintf("Import exceeds maximum allowed size of %d bytes", s.config.MaxSize),
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	format, posts, err := s.readImport(file, fileHeader.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	im, err := s.newImporter(userID, req.OnConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{
+		Format: format,
+		DryRun: req.DryRun,
+		Posts:  []models.ImportedPost{},
+	}
+	for i := range posts {
+		plan, err := im.plan(&posts[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if plan.entry.Action != models.ImportActionSkip && !req.DryRun {
+			postID, err := s.create(plan)
+			if err != nil {
+				plan.entry.Action = models.ImportActionSkip
+				plan.entry.Warnings = append(plan.entry.Warnings, fmt.Sprintf("failed to import: %v", err))
+			}
+			plan.entry.PostID = postID
+		}
+
+		if plan.entry.Action == models.ImportActionSkip {
+			report.Skipped++
+		} else {
+			report.Created++
+		}
+		if plan.entry.Conflict != "" {
+			report.Conflicts++
+		}
+		report.Posts = append(report.Posts, plan.entry)
+	}
+
+	if s.auditService != nil && !req.DryRun {
+		s.auditService.LogEvent(userID, ActionImport, AuditEventData{
+			EntityType: "post",
+			NewValues: map[string]interface{}{
+				"format":    format,
+				"created":   report.Created,
+				"skipped":   report.Skipped,
+				"conflicts": report.Conflicts,
+			},
+		})
+	}
+
+	return report, nil
+}
+
+// newImporter starts planning an import by the user
+func (s *ImportService) newImporter(userID uint, onConflict models.ImportConflictPolicy) (*importer, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if onConflict == "" {
+		onConflict = models.ImportConflictSkip
+	}
+	return &importer{
+		s:          s,
+		user:       &user,
+		forOthers:  authorize(s.db, userID, "import", authz.Kind("post")) == nil,
+		onConflict: onConflict,
+		slugs:      make(map[string]bool),
+		users:      make(map[importAuthor]*models.User),
+	}, nil
+}
+
+// plan checks an imported post and decides what to do with it: the author,
+// status and slug it gets, and which of its comments and files are kept
+func (im *importer) plan(post *importPost) (*importPlan, error) {
+	plan := &importPlan{
+		post: post,
+		entry: models.ImportedPost{
+			Title:    post.title,
+			Slug:     post.slug,
+			Status:   post.status,
+			Warnings: post.warnings,
+		},
+	}
+	if post.skip != "" {
+		plan.entry.Action = models.ImportActionSkip
+		plan.entry.Author = post.author.name
+		plan.entry.Warnings = append(plan.entry.Warnings, post.skip)
+		return plan, nil
+	}
+
+	if strings.TrimSpace(post.title) == "" {
+		post.title = "Untitled"
+		plan.entry.Title = post.title
+		plan.warn("post has no title")
+	}
+	if post.createdAt.IsZero() {
+		post.createdAt = time.Now()
+	}
+
+	if err := im.planAuthor(plan); err != nil {
+		return nil, err
+	}
+	if err := im.planStatus(plan); err != nil {
+		return nil, err
+	}
+	if err := im.planSlug(plan); err != nil {
+		return nil, err
+	}
+	if plan.entry.Action == models.ImportActionSkip {
+		return plan, nil
+	}
+	if err := im.planTaxonomy(plan); err != nil {
+		return nil, err
+	}
+	if err := im.planComments(plan); err != nil {
+		return nil, err
+	}
+
+	plan.files = im.planFiles(plan, post.files)
+	plan.entry.Attachments = len(plan.files)
+	for _, comment := range plan.comments {
+		plan.entry.Attachments += len(comment.files)
+	}
+	return plan, nil
+}
+
+// planAuthor maps the post's author to a user, falling back on the importing
+// user
+func (im *importer) planAuthor(plan *importPlan) error {
+	author := plan.post.author
+	user, err := im.lookup(author)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case user == nil:
+		if author.name != "" {
+			plan.warn(fmt.Sprintf("author %s not found; imported as yours", author.name))
+		}
+		user = im.user
+	case user.ID != im.user.ID && !im.forOthers:
+		plan.warn(fmt.Sprintf("only admins can import posts by other users; post by %s imported as yours", user.Username))
+		user = im.user
+	}
+
+	plan.authorID = user.ID
+	plan.entry.Author = user.Username
+	plan.entry.AuthorID = user.ID
+	return nil
+}
+
+// planStatus keeps the post's status if the importing user may move a post
+// there, and otherwise imports the post as a draft
+func (im *importer) planStatus(plan *importPlan) error {
+	post := plan.post
+	draft := func(warning string) {
+		post.status = models.PostStatusDraft
+		post.publishAt = nil
+		post.publishedAt = nil
+		plan.warn(warning)
+	}
+
+	switch post.status {
+	case "":
+		post.status = models.PostStatusDraft
+	case models.PostStatusDraft, models.PostStatusInReview, models.PostStatusPublished, models.PostStatusArchived:
+	case models.PostStatusScheduled:
+		switch {
+		case post.publishAt == nil:
+			draft("scheduled post has no publish time; imported as a draft")
+		case !post.publishAt.After(time.Now()):
+			// The post would have been published by now
+			post.status = models.PostStatusPublished
+			post.publishedAt = post.publishAt
+			post.publishAt = nil
+		}
+	default:
+		draft(fmt.Sprintf("unknown status %q; imported as a draft", post.status))
+	}
+
+	if post.status == models.PostStatusDraft {
+		post.publishedAt = nil
+	} else {
+		action := transitionAction(models.PostStatusDraft, post.status)
+		err := authorize(im.s.db, im.user.ID, action, &models.Post{UserID: plan.authorID})
+		var denied *authz.DeniedError
+		if errors.As(err, &denied) {
+			draft(fmt.Sprintf("not allowed to %s posts; imported as a draft", action))
+		} else if err != nil {
+			return err
+		}
+	}
+	if post.status == models.PostStatusPublished && post.publishedAt == nil {
+		post.publishedAt = &post.createdAt
+	}
+
+	plan.entry.Status = post.status
+	return nil
+}
+
+// planSlug keeps the post's slug if it is free. A taken slug is a conflict:
+// the post is skipped or, when renaming, given the next free slug.
+func (im *importer) planSlug(plan *importPlan) error {
+	source := utils.Slugify(plan.post.slug)
+	slug := source
+	if source == "" {
+		base := utils.Slugify(plan.post.title)
+		if base == "" {
+			base = "post"
+		}
+		var err error
+		if slug, err = im.freeSlug(base); err != nil {
+			return err
+		}
+	} else {
+		taken, err := im.slugTaken(source)
+		if err != nil {
+			return err
+		}
+		if taken {
+			plan.entry.Conflict = fmt.Sprintf("slug %q is already taken", source)
+			if im.onConflict != models.ImportConflictRename {
+				plan.entry.Action = models.ImportActionSkip
+				plan.entry.Slug = source
+				return nil
+			}
+			if slug, err = im.freeSlug(source); err != nil {
+				return err
+			}
+			plan.entry.Action = models.ImportActionRename
+			plan.entry.SourceSlug = source
+		}
+	}
+
+	if plan.entry.Action == "" {
+		plan.entry.Action = models.ImportActionCreate
+	}
+	plan.entry.Slug = slug
+	im.slugs[slug] = true
+	return nil
+}
+
+// planTaxonomy keeps the post's valid tags and the categories that exist
+func (im *importer) planTaxonomy(plan *importPlan) error {
+	for _, name := range plan.post.tags {
+		if _, _, err := tagName(name); err != nil {
+			plan.warn(fmt.Sprintf("tag %q left out: %v", name, err))
+			continue
+		}
+		plan.tags = append(plan.tags, name)
+	}
+
+	if len(plan.post.categories) == 0 {
+		return nil
+	}
+	if err := im.s.db.Where("slug IN ?", plan.post.categories).Find(&plan.categories).Error; err != nil {
+		return fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	found := make(map[string]bool, len(plan.categories))
+	for _, category := range plan.categories {
+		found[category.Slug] = true
+	}
+	for _, slug := range plan.post.categories {
+		if !found[slug] {
+			plan.warn(fmt.Sprintf("category %q does not exist", slug))
+		}
+	}
+	return nil
+}
+
+// planComments keeps the comments by authors who map to users the importing
+// user may import for. Replies to comments left out move up to the nearest
+// ancestor kept, and replies nested deeper than allowed up to the deepest
+// level. Comments are planned parents first.
+func (im *importer) planComments(plan *importPlan) error {
+	comments := plan.post.comments
+	byID := make(map[uint]*importComment, len(comments))
+	for i := range comments {
+		byID[comments[i].id] = &comments[i]
+	}
+
+	kept := make(map[uint]*plannedComment)
+	var keptOrder []*plannedComment
+	notFound, others := 0, 0
+	for i := range comments {
+		comment := &comments[i]
+		if strings.TrimSpace(comment.content) == "" {
+			continue
+		}
+		user, err := im.lookup(comment.author)
+		if err != nil {
+			return err
+		}
+		switch {
+		case user == nil:
+			notFound++
+			continue
+		case user.ID != im.user.ID && !im.forOthers:
+			others++
+			continue
+		}
+		switch comment.status {
+		case models.CommentStatusApproved, models.CommentStatusPending, models.CommentStatusSpam, models.CommentStatusRejected:
+		case "":
+			comment.status = models.CommentStatusApproved
+		default:
+			// Hold comments in an unknown state for moderation
+			comment.status = models.CommentStatusPending
+		}
+		planned := &plannedComment{comment: comment, userID: user.ID}
+		kept[comment.id] = planned
+		keptOrder = append(keptOrder, planned)
+	}
+	if notFound > 0 {
+		plan.warn(fmt.Sprintf("%d comments by authors not found left out", notFound))
+	}
+	if others > 0 {
+		plan.warn(fmt.Sprintf("%d comments by other users left out; only admins can import them", others))
+	}
+
+	// Link each comment to its nearest kept ancestor, giving up on loops
+	children := make(map[*plannedComment][]*plannedComment)
+	for _, planned := range keptOrder {
+		parentID := planned.comment.parent
+		for steps := 0; parentID != 0 && steps <= len(comments); steps++ {
+			if parent, ok := kept[parentID]; ok {
+				planned.parent = parent
+				break
+			}
+			ancestor, ok := byID[parentID]
+			if !ok {
+				break
+			}
+			parentID = ancestor.parent
+		}
+		if planned.parent == planned {
+			planned.parent = nil
+		}
+		children[planned.parent] = append(children[planned.parent], planned)
+	}
+
+	var visit func(planned *plannedComment)
+	visit = func(planned *plannedComment) {
+		if planned.parent != nil {
+			planned.depth = planned.parent.depth + 1
+			if planned.depth > im.s.comments.MaxDepth {
+				planned.parent = planned.parent.parent
+				planned.depth = im.s.comments.MaxDepth
+			}
+		}
+		planned.files = im.planFiles(plan, planned.comment.files)
+		plan.comments = append(plan.comments, planned)
+		for _, child := range children[planned] {
+			visit(child)
+		}
+	}
+	for _, root := range children[nil] {
+		visit(root)
+	}
+
+	plan.entry.Comments = len(plan.comments)
+	return nil
+}
+
+// planFiles keeps the files that may be uploaded, up to the number of
+// attachments allowed on a post or comment
+func (im *importer) planFiles(plan *importPlan, files []importFile) []plannedFile {
+	var planned []plannedFile
+	for _, file := range files {
+		if err := im.s.fileService.CheckFile(file.name, int64(file.file.UncompressedSize64)); err != nil {
+			plan.warn(fmt.Sprintf("attachment %s left out: %v", file.name, err))
+			continue
+		}
+		if max := im.s.attachments.MaxPerTarget; max > 0 && len(planned) >= max {
+			plan.warn(fmt.Sprintf("attachment %s left out: %v", file.name, ErrTooManyAttachments))
+			continue
+		}
+		planned = append(planned, plannedFile{importFile: file})
+	}
+	return planned
+}
+
+// lookup finds the user an imported author maps to by email, then username.
+// It returns nil for authors who are not found.
+func (im *importer) lookup(author importAuthor) (*models.User, error) {
+	if user, ok := im.users[author]; ok {
+		return user, nil
+	}
+
+	var user *models.User
+	for _, match := range []struct{ query, value string }{
+		{"LOWER(email) = LOWER(?)", author.email},
+		{"username = ?", author.username},
+	} {
+		if match.value == "" {
+			continue
+		}
+		var found models.User
+		err := im.s.db.Where(match.query, match.value).First(&found).Error
+		if err == nil {
+			user = &found
+			break
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up author: %w", err)
+		}
+	}
+
+	im.users[author] = user
+	return user, nil
+}
+
+// slugTaken checks if a post has the slug, or an earlier post of the import
+// will
+func (im *importer) slugTaken(slug string) (bool, error) {
+	if im.slugs[slug] {
+		return true, nil
+	}
+	taken, err := im.s.postService.slugTaken(slug, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to check slug: %w", err)
+	}
+	return taken, nil
+}
+
+// freeSlug returns the slug, or the slug with the first numeric suffix that
+// is free
+func (im *importer) freeSlug(base string) (string, error) {
+	slug := base
+	for i := 2; ; i++ {
+		taken, err := im.slugTaken(slug)
+		if err != nil || !taken {
+			return slug, err
+		}
+		slug = utils.NumberedSlug(base, i)
+	}
+}
+
+// warn adds a warning to the post's report entry
+func (p *importPlan) warn(warning string) {
+	p.entry.Warnings = append(p.entry.Warnings, warning)
+}
+
+// create creates a planned post with its comments and attachments and
+// returns its ID. Files are stored first and deleted again if the post
+// cannot be created.
+func (s *ImportService) create(plan *importPlan) (uint, error) {
+	var stored []models.FileUpload
+	err := s.storeFiles(plan.files, plan.authorID, &stored)
+	for _, comment := range plan.comments {
+		if err != nil {
+			break
+		}
+		err = s.storeFiles(comment.files, comment.userID, &stored)
+	}
+
+	var post *models.Post
+	if err == nil {
+		post, err = s.createPost(plan)
+	}
+	if err != nil {
+		if len(stored) == 0 {
+			return 0, err
+		}
+		cleanupErr := s.db.Unscoped().Delete(&stored).Error
+		if cleanupErr == nil {
+			cleanupErr = removeFileBytes(stored)
+		}
+		if cleanupErr != nil {
+			return 0, fmt.Errorf("%w (and failed to delete uploads: %v)", err, cleanupErr)
+		}
+		return 0, err
+	}
+	return post.ID, nil
+}
+
+// storeFiles stores the files as uploads of the user, appending them to
+// stored
+func (s *ImportService) storeFiles(files []plannedFile, userID uint, stored *[]models.FileUpload) error {
+	for i := range files {
+		file := &files[i]
+		r, err := file.open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file.name, err)
+		}
+		upload, err := s.fileService.StoreFile(r, file.name, file.mimeType, userID, models.AttachmentCategory)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", file.name, err)
+		}
+		file.upload = upload.FileUpload
+		*stored = append(*stored, *upload.FileUpload)
+	}
+	return nil
+}
+
+// createPost creates a planned post, its comments and attachments, tags,
+// categories and first revision in one transaction. Content is rendered once
+// links to attached files point at their attachments.
+func (s *ImportService) createPost(plan *importPlan) (*models.Post, error) {
+	source := plan.post
+	post := &models.Post{
+		UserID:      plan.authorID,
+		Title:       source.title,
+		Content:     source.content,
+		Slug:        plan.entry.Slug,
+		Status:      source.status,
+		Published:   source.status == models.PostStatusPublished,
+		PublishAt:   source.publishAt,
+		PublishedAt: source.publishedAt,
+
+		CommentsRequireApproval: source.commentsRequireApproval,
+
+		CreatedAt: source.createdAt,
+		UpdatedAt: time.Now(),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+
+		var links []attachmentLink
+		attach := func(target models.AttachmentTarget, targetID, userID uint, files []plannedFile) error {
+			for i, file := range files {
+				attachment := models.Attachment{
+					FileID:     file.upload.ID,
+					TargetType: target,
+					TargetID:   targetID,
+					PostID:     post.ID,
+					UserID:     userID,
+					Position:   i,
+					Caption:    file.caption,
+				}
+				if err := tx.Create(&attachment).Error; err != nil {
+					return err
+				}
+				links = append(links, attachmentLink{pattern: file.links, url: models.AttachmentURL(attachment.ID)})
+			}
+			return nil
+		}
+		if err := attach(models.AttachmentTargetPost, post.ID, post.UserID, plan.files); err != nil {
+			return err
+		}
+
+		comments := make([]*models.Comment, len(plan.comments))
+		for i, planned := range plan.comments {
+			comment := &models.Comment{
+				Content:   planned.comment.content,
+				UserID:    planned.userID,
+				PostID:    post.ID,
+				Depth:     planned.depth,
+				Status:    planned.comment.status,
+				CreatedAt: planned.comment.createdAt,
+				UpdatedAt: planned.comment.createdAt,
+			}
+			if planned.parent != nil {
+				comment.ParentID = &planned.parent.id
+			}
+			if err := tx.Create(comment).Error; err != nil {
+				return err
+			}
+			planned.id = comment.ID
+			comments[i] = comment
+
+			if err := attach(models.AttachmentTargetComment, comment.ID, comment.UserID, planned.files); err != nil {
+				return err
+			}
+		}
+
+		if err := renderImported(tx, post, &post.Content, &post.ContentHTML, links); err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if err := renderImported(tx, comment, &comment.Content, &comment.ContentHTML, links); err != nil {
+				return err
+			}
+		}
+
+		if len(plan.tags) > 0 {
+			if _, _, err := replacePostTags(tx, post, plan.tags); err != nil {
+				return err
+			}
+		}
+		if len(plan.categories) > 0 {
+			if err := tx.Model(post).Association("Categories").Replace(plan.categories); err != nil {
+				return err
+			}
+		}
+
+		if s.postService.revisionService == nil {
+			return nil
+		}
+		_, err := s.postService.revisionService.Record(tx, post, plan.authorID, "Imported")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// renderImported rewrites the links to attached files in an imported post or
+// comment and stores its content rendered
+func renderImported(tx *gorm.DB, model interface{}, content, contentHTML *string, links []attachmentLink) error {
+	*content = rewriteLinks(*content, links)
+	rendered, err := markdown.Render(*content)
+	if err != nil {
+		return fmt.Errorf("failed to render content: %w", err)
+	}
+	*contentHTML = rendered
+
+	return tx.Model(model).UpdateColumns(map[string]interface{}{
+		"content":        *content,
+		"content_html":   rendered,
+		"render_version": markdown.Version,
+	}).Error
+}
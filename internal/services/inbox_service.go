@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// inAppNotification is the notification type of inbox entries
+const inAppNotification = "in_app"
+
+// InboxService manages the in-app notifications users receive about
+// things that concern them, such as moderation decisions
+type InboxService struct {
+	*CRUDService[models.Notification]
+	db *gorm.DB
+}
+
+// NewInboxService creates a new inbox service
+func NewInboxService(db *gorm.DB) *InboxService {
+	return &InboxService{
+		CRUDService: NewCRUDService[models.Notification](db),
+		db:          db,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *InboxService) WithContext(ctx context.Context) *InboxService {
+	return &InboxService{
+		CRUDService: s.CRUDService.WithContext(ctx),
+		db:          s.db.WithContext(ctx),
+	}
+}
+
+// List pages through a user's notifications, newest first
+func (s *InboxService) List(userID uint, unreadOnly bool, options QueryOptions) (*PaginatedResult[models.Notification], error) {
+	options.Filter.Filters = map[string]interface{}{
+		"user_id": userID,
+		"type":    inAppNotification,
+	}
+	if unreadOnly {
+		options.Filter.Filters["is_read"] = false
+	}
+	options.Sort = []SortOptions{{Field: "created_at", Direction: "desc"}, {Field: "id", Direction: "desc"}}
+
+	return s.GetAll(options)
+}
+
+// UnreadCount counts a user's unread notifications
+func (s *InboxService) UnreadCount(userID uint) (int64, error) {
+	return s.Count(map[string]interface{}{
+		"user_id": userID,
+		"type":    inAppNotification,
+		"is_read": false,
+	})
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *InboxService) MarkRead(userID, notificationID uint) error {
+	var notification models.Notification
+	err := s.db.Where("id = ? AND user_id = ? AND type = ?", notificationID, userID, inAppNotification).
+		First(&notification).Error
+	if err != nil {
+		return err
+	}
+	if notification.IsRead {
+		return nil
+	}
+
+	notification.MarkAsRead()
+	return s.db.Model(&notification).Updates(map[string]interface{}{
+		"is_read": notification.IsRead,
+		"read_at": notification.ReadAt,
+	}).Error
+}
+
+// MarkAllRead marks all of the user's notifications as read and returns how
+// many were unread
+func (s *InboxService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND type = ? AND is_read = ?", userID, inAppNotification, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// notify puts a notification in a user's inbox. data describes what the
+// notification is about for clients to link to.
+func notify(db *gorm.DB, userID uint, title, message string, data map[string]interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	now := time.Now()
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    inAppNotification,
+		Title:   title,
+		Message: message,
+		Data:    string(encoded),
+		SentAt:  &now,
+	}
+	if err := db.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to notify user %d: %w", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/markdown"
+	"go-backend/internal/models"
+	"go-backend/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMentions caps how many users a single post or comment can mention
+const maxMentions = 20
+
+// MentionService records the users @mentioned in posts and comments and
+// notifies them. Mentions are recorded whenever content is saved, and
+// delivered once the content is visible to everyone: the post is published
+// and, for comments, the comment approved. Each mention is delivered once, so
+// editing the content does not notify the same users again.
+type MentionService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	config              config.AppConfig
+	logger              *logger.Logger
+}
+
+// NewMentionService creates a new mention service
+func NewMentionService(db *gorm.DB, notificationService *NotificationService, cfg config.AppConfig, logger *logger.Logger) *MentionService {
+	return &MentionService{
+		db:                  db,
+		notificationService: notificationService,
+		config:              cfg,
+		logger:              logger,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *MentionService) WithContext(ctx context.Context) *MentionService {
+	var notificationService *NotificationService
+	if s.notificationService != nil {
+		notificationService = s.notificationService.WithContext(ctx)
+	}
+	return &MentionService{
+		db:                  s.db.WithContext(ctx),
+		notificationService: notificationService,
+		config:              s.config,
+		logger:              s.logger,
+	}
+}
+
+// Record replaces the mentions of a post or comment with the users mentioned
+// in its content, then delivers them if the content is visible. Users who
+// blocked the author are not recorded. Failures are logged, as the content is
+// saved either way.
+func (s *MentionService) Record(authorID uint, target models.MentionTarget, targetID, postID uint, content string) {
+	if err := s.record(authorID, target, targetID, postID, content); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"target_type": target,
+			"target_id":   targetID,
+		}).Error("Failed to record mentions")
+		return
+	}
+	s.Deliver(target, targetID)
+}
+
+// PostPublished delivers the mentions in a post and its approved comments
+// once the post is published
+func (s *MentionService) PostPublished(post *models.Post) {
+	s.Deliver(models.MentionTargetPost, post.ID)
+
+	var commentIDs []uint
+	err := s.db.Model(&models.Comment{}).
+		Where("post_id = ? AND status = ?", post.ID, models.CommentStatusApproved).
+		Pluck("id", &commentIDs).Error
+	if err != nil {
+		s.logger.WithError(err).WithField("post_id", post.ID).Error("Failed to load comments to deliver mentions")
+		return
+	}
+	if len(commentIDs) > 0 {
+		s.Deliver(models.MentionTargetComment, commentIDs...)
+	}
+}
+
+// Deliver notifies the users mentioned in posts or comments that are visible
+// and have not been notified yet. Users are notified as their mention
+// settings ask, unless they have since blocked the author.
+func (s *MentionService) Deliver(target models.MentionTarget, targetIDs ...uint) {
+	var mentions []models.Mention
+	query := s.db.Preload("Author").Preload("Post").
+		Joins("JOIN posts ON posts.id = mentions.post_id AND posts.deleted_at IS NULL").
+		Where("mentions.target_type = ? AND mentions.target_id IN ? AND mentions.delivered_at IS NULL", target, targetIDs).
+		Where("posts.status = ?", models.PostStatusPublished)
+	if target == models.MentionTargetComment {
+		query = query.Joins("JOIN comments ON comments.id = mentions.target_id AND comments.deleted_at IS NULL").
+			Where("comments.status = ?", models.CommentStatusApproved)
+	}
+	if err := query.Find(&mentions).Error; err != nil {
+		s.logger.WithError(err).WithField("target_type", target).Error("Failed to load mentions to deliver")
+		return
+	}
+
+	for i := range mentions {
+		mention := &mentions[i]
+		if err := s.deliver(mention); err != nil {
+			s.logger.WithError(err).WithField("mention_id", mention.ID).Error("Failed to notify mentioned user")
+		}
+
+		// Failed notifications are not retried, so the user is not notified
+		// twice when only one of their channels failed
+		err := s.db.Model(mention).Update("delivered_at", time.Now()).Error
+		if err != nil {
+			s.logger.WithError(err).WithField("mention_id", mention.ID).Error("Failed to mark mention delivered")
+		}
+	}
+}
+
+// ForUser pages through the delivered mentions of a user, newest first
+func (s *MentionService) ForUser(userID uint, options QueryOptions) (*PaginatedResult[models.MentionResponse], error) {
+	query := s.db.Model(&models.Mention{}).
+		Where("user_id = ? AND delivered_at IS NOT NULL", userID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var mentions []models.Mention
+	err := query.Preload("Author").Preload("Post").
+		Order("delivered_at DESC").
+		Order("id DESC").
+		Offset((options.Pagination.Page - 1) * options.Pagination.PageSize).
+		Limit(options.Pagination.PageSize).
+		Find(&mentions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.MentionResponse, 0, len(mentions))
+	for _, mention := range mentions {
+		response := models.MentionResponse{
+			ID:          mention.ID,
+			TargetType:  mention.TargetType,
+			TargetID:    mention.TargetID,
+			PostID:      mention.PostID,
+			MentionedAt: *mention.DeliveredAt,
+		}
+		if mention.Post != nil {
+			response.PostTitle = mention.Post.Title
+			response.PostSlug = mention.Post.Slug
+		}
+		if mention.Author != nil {
+			response.Author = mention.Author.ToProfile()
+		}
+		responses = append(responses, response)
+	}
+
+	totalPages := int((total + int64(options.Pagination.PageSize) - 1) / int64(options.Pagination.PageSize))
+	return &PaginatedResult[models.MentionResponse]{
+		Data:       responses,
+		Total:      total,
+		Page:       options.Pagination.Page,
+		PageSize:   options.Pagination.PageSize,
+		TotalPages: totalPages,
+		HasNext:    options.Pagination.Page < totalPages,
+		HasPrev:    options.Pagination.Page > 1,
+	}, nil
+}
+
+// record stores the mentions in the content and removes those edited out
+func (s *MentionService) record(authorID uint, target models.MentionTarget, targetID, postID uint, content string) error {
+	usernames := markdown.Mentions(content)
+	if len(usernames) > maxMentions {
+		usernames = usernames[:maxMentions]
+	}
+
+	var userIDs []uint
+	if len(usernames) > 0 {
+		err := s.db.Model(&models.User{}).
+			Where("username IN ? AND is_active = ? AND id <> ?", usernames, true, authorID).
+			Where("id NOT IN (?)", s.db.Model(&models.Block{}).Select("blocker_id").Where("blocked_id = ?", authorID)).
+			Pluck("id", &userIDs).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		removed := tx.Where("target_type = ? AND target_id = ?", target, targetID)
+		if len(userIDs) > 0 {
+			removed = removed.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := removed.Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		mentions := make([]models.Mention, len(userIDs))
+		for i, userID := range userIDs {
+			mentions[i] = models.Mention{
+				UserID:     userID,
+				AuthorID:   authorID,
+				TargetType: target,
+				TargetID:   targetID,
+				PostID:     postID,
+			}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error
+	})
+}
+
+// deliver notifies the mentioned user through the channels they chose
+func (s *MentionService) deliver(mention *models.Mention) error {
+	if s.notificationService == nil || mention.Author == nil || mention.Post == nil {
+		return nil
+	}
+
+	var user models.User
+	if err := s.db.Where("is_active = ?", true).First(&user, mention.UserID).Error; err != nil {
+		return err
+	}
+	setting := user.MentionNotifications
+	if setting == "" {
+		setting = models.MentionsInApp
+	}
+	if setting == models.MentionsOff {
+		return nil
+	}
+
+	var blocks int64
+	err := s.db.Model(&models.Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", mention.UserID, mention.AuthorID).
+		Count(&blocks).Error
+	if err != nil || blocks > 0 {
+		return err
+	}
+
+	where := "a post"
+	if mention.TargetType == models.MentionTargetComment {
+		where = "a comment"
+	}
+	variables := map[string]interface{}{
+		"Author":      mention.Author.Username,
+		"Where":       where,
+		"Title":       mention.Post.Title,
+		"URL":         fmt.Sprintf("%s/posts/%s", s.config.FrontendURL, mention.Post.Slug),
+		"kind":        "mention",
+		"target_type": mention.TargetType,
+		"target_id":   mention.TargetID,
+		"post_id":     mention.PostID,
+		"author_id":   mention.AuthorID,
+	}
+
+	if setting.InApp() {
+		if err := s.notificationService.SendFromTemplate("mention_in_app", user.Username, &user.ID, variables); err != nil {
+			return err
+		}
+	}
+	if setting.Email() && s.notificationService.EmailEnabled() {
+		if err := s.notificationService.SendFromTemplate("mention_email", user.Email, &user.ID, variables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,519 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCannotReportSelf is returned when users report themselves or their own content
+	ErrCannotReportSelf = errors.New("you cannot report yourself or your own content")
+	// ErrAlreadyReported is returned when a user reports the same thing twice while the first report is open
+	ErrAlreadyReported = errors.New("you have already reported this")
+	// ErrInvalidAssignee is returned when assigning a report to someone who cannot moderate
+	ErrInvalidAssignee = errors.New("reports can only be assigned to moderators")
+	// ErrActionTarget is returned when an action names neither a target nor a report, or a target other than the report's
+	ErrActionTarget = errors.New("an action needs a target or a report, and must match the report's target")
+	// ErrCannotHideUser is returned when hiding a user rather than content
+	ErrCannotHideUser = errors.New("only posts and comments can be hidden")
+	// ErrSuspensionDays is returned when suspending without a duration
+	ErrSuspensionDays = errors.New("suspensions need a duration in days")
+)
+
+// openReportStatuses are the statuses of reports still in the queue
+var openReportStatuses = []models.ReportStatus{models.ReportStatusOpen, models.ReportStatusInReview}
+
+// ModerationService handles reports of posts, comments and users, and the
+// actions moderators take on them. Every decision is audited and the users
+// it affects are notified.
+type ModerationService struct {
+	*CRUDService[models.Report]
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(db *gorm.DB, auditService *AuditService) *ModerationService {
+	return &ModerationService{
+		CRUDService:  NewCRUDService[models.Report](db),
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ModerationService) WithContext(ctx context.Context) *ModerationService {
+	var auditService *AuditService
+	if s.auditService != nil {
+		auditService = s.auditService.WithContext(ctx)
+	}
+	return &ModerationService{
+		CRUDService:  s.CRUDService.WithContext(ctx),
+		db:           s.db.WithContext(ctx),
+		auditService: auditService,
+	}
+}
+
+// ReportFilter narrows the report queue. The queue holds open and in-review
+// reports unless a status is given.
+type ReportFilter struct {
+	Status     models.ReportStatus
+	TargetType models.ReportTarget
+	AssigneeID *uint
+}
+
+// moderationTarget is the post, comment or user a report or action is about
+type moderationTarget struct {
+	// UserID is the user concerned: the author of content, or the user
+	UserID uint
+	// Label is how notifications refer to the target
+	Label string
+}
+
+// CreateReport reports a post, comment or user the reporter can see
+func (s *ModerationService) CreateReport(reporterID uint, req *models.ReportCreateRequest) (*models.Report, error) {
+	if err := authorize(s.db, reporterID, "create", authz.Kind("report")); err != nil {
+		return nil, fmt.Errorf("unauthorized to report: %w", err)
+	}
+
+	// Content must be visible to the reporter, as for reactions
+	if req.TargetType != models.ReportTargetUser {
+		reactions := NewReactionService(s.db, config.ReactionsConfig{})
+		if err := reactions.authorizeTarget(reporterID, models.ReactionTarget(req.TargetType), req.TargetID); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := s.findTarget(req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.UserID == reporterID {
+		return nil, ErrCannotReportSelf
+	}
+
+	var open int64
+	err = s.db.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status IN ?",
+			reporterID, req.TargetType, req.TargetID, openReportStatuses).
+		Count(&open).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing reports: %w", err)
+	}
+	if open > 0 {
+		return nil, ErrAlreadyReported
+	}
+
+	report := &models.Report{
+		ReporterID:   reporterID,
+		TargetType:   req.TargetType,
+		TargetID:     req.TargetID,
+		TargetUserID: target.UserID,
+		Reason:       req.Reason,
+		Details:      req.Details,
+		Status:       models.ReportStatusOpen,
+	}
+	if err := s.db.Create(report).Error; err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	s.logChange(reporterID, ActionCreate, "report", report.ID, nil, map[string]interface{}{
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"reason":      report.Reason,
+	})
+
+	return report, nil
+}
+
+// ListReports pages through the report queue, oldest first
+func (s *ModerationService) ListReports(moderatorID uint, filter ReportFilter, options QueryOptions) (*PaginatedResult[models.Report], error) {
+	if err := authorize(s.db, moderatorID, "moderate", authz.Kind("report")); err != nil {
+		return nil, fmt.Errorf("unauthorized to moderate reports: %w", err)
+	}
+
+	filters := make(map[string]interface{})
+	if filter.Status != "" {
+		filters["status"] = string(filter.Status)
+	} else {
+		statuses := make([]interface{}, len(openReportStatuses))
+		for i, status := range openReportStatuses {
+			statuses[i] = string(status)
+		}
+		filters["status"] = statuses
+	}
+	if filter.TargetType != "" {
+		filters["target_type"] = string(filter.TargetType)
+	}
+	if filter.AssigneeID != nil {
+		filters["assignee_id"] = *filter.AssigneeID
+	}
+
+	options.Filter.Filters = filters
+	options.Preload = append(options.Preload, "Reporter", "TargetUser", "Assignee")
+	if len(options.Sort) == 0 {
+		options.Sort = []SortOptions{{Field: "created_at", Direction: "asc"}}
+	}
+
+	return s.GetAll(options)
+}
+
+// GetReport gets a report with the actions taken on it
+func (s *ModerationService) GetReport(moderatorID, reportID uint) (*models.Report, error) {
+	if err := authorize(s.db, moderatorID, "moderate", authz.Kind("report")); err != nil {
+		return nil, fmt.Errorf("unauthorized to moderate reports: %w", err)
+	}
+
+	var report models.Report
+	err := s.db.Preload("Reporter").Preload("TargetUser").Preload("Assignee").
+		Preload("Actions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Actions.Moderator").
+		First(&report, reportID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// UpdateReport assigns a report or changes its status. Assigning an open
+// report puts it in review. The reporter is told when the report is closed.
+func (s *ModerationService) UpdateReport(moderatorID, reportID uint, req *models.ReportUpdateRequest) (*models.Report, error) {
+	if err := authorize(s.db, moderatorID, "moderate", authz.Kind("report")); err != nil {
+		return nil, fmt.Errorf("unauthorized to moderate reports: %w", err)
+	}
+
+	var report models.Report
+	if err := s.db.First(&report, reportID).Error; err != nil {
+		return nil, err
+	}
+
+	oldValues := map[string]interface{}{
+		"status":      report.Status,
+		"assignee_id": report.AssigneeID,
+	}
+	updates := make(map[string]interface{})
+
+	status := report.Status
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == 0 {
+			updates["assignee_id"] = nil
+		} else {
+			if authorize(s.db, *req.AssigneeID, "moderate", authz.Kind("report")) != nil {
+				return nil, ErrInvalidAssignee
+			}
+			updates["assignee_id"] = *req.AssigneeID
+			if status == models.ReportStatusOpen {
+				status = models.ReportStatusInReview
+			}
+		}
+	}
+	if req.Status != nil {
+		status = *req.Status
+	}
+	if req.Resolution != nil {
+		updates["resolution"] = *req.Resolution
+	}
+
+	closing := status.IsClosed() && !report.Status.IsClosed()
+	if status != report.Status {
+		updates["status"] = status
+		if closing {
+			updates["resolved_by"] = moderatorID
+			updates["resolved_at"] = time.Now()
+		} else if !status.IsClosed() {
+			updates["resolved_by"] = nil
+			updates["resolved_at"] = nil
+		}
+	}
+	if len(updates) == 0 {
+		return s.GetReport(moderatorID, reportID)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&report).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update report: %w", err)
+		}
+		if !closing {
+			return nil
+		}
+
+		target, err := s.findTarget(report.TargetType, report.TargetID)
+		if err != nil {
+			target = &moderationTarget{Label: string(report.TargetType)}
+		}
+		return notifyReporters(tx, []models.Report{report}, status, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logChange(moderatorID, ActionModerate, "report", reportID, oldValues, updates)
+
+	return s.GetReport(moderatorID, reportID)
+}
+
+// TakeAction hides content, warns its author or suspends them. Open reports
+// about the same target are resolved by the action. The affected user and
+// the reporters are notified.
+func (s *ModerationService) TakeAction(moderatorID uint, req *models.ModerationActionRequest) (*models.ModerationAction, error) {
+	if err := authorize(s.db, moderatorID, "moderate", authz.Kind("report")); err != nil {
+		return nil, fmt.Errorf("unauthorized to moderate reports: %w", err)
+	}
+
+	targetType, targetID := req.TargetType, req.TargetID
+	if req.ReportID != nil {
+		var report models.Report
+		if err := s.db.First(&report, *req.ReportID).Error; err != nil {
+			return nil, err
+		}
+		if targetType == "" && targetID == 0 {
+			targetType, targetID = report.TargetType, report.TargetID
+		}
+		if targetType != report.TargetType || targetID != report.TargetID {
+			return nil, ErrActionTarget
+		}
+	}
+	if targetType == "" || targetID == 0 {
+		return nil, ErrActionTarget
+	}
+
+	switch req.Action {
+	case models.ModerationHide:
+		if targetType == models.ReportTargetUser {
+			return nil, ErrCannotHideUser
+		}
+	case models.ModerationSuspend:
+		if req.Days == 0 {
+			return nil, ErrSuspensionDays
+		}
+	}
+
+	target, err := s.findTarget(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Staff can only be warned or suspended by admins
+	if req.Action != models.ModerationHide {
+		var user models.User
+		if err := s.db.First(&user, target.UserID).Error; err != nil {
+			return nil, err
+		}
+		if err := authorize(s.db, moderatorID, "moderate", &user); err != nil {
+			return nil, fmt.Errorf("unauthorized to sanction this user: %w", err)
+		}
+	}
+
+	now := time.Now()
+	action := &models.ModerationAction{
+		ModeratorID:  moderatorID,
+		Action:       req.Action,
+		TargetType:   targetType,
+		TargetID:     targetID,
+		TargetUserID: target.UserID,
+		ReportID:     req.ReportID,
+		Reason:       req.Reason,
+	}
+	if req.Action == models.ModerationSuspend {
+		expiresAt := now.AddDate(0, 0, req.Days)
+		action.ExpiresAt = &expiresAt
+	}
+
+	var resolved []models.Report
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyModeration(tx, action, now); err != nil {
+			return err
+		}
+		if err := tx.Create(action).Error; err != nil {
+			return fmt.Errorf("failed to record moderation action: %w", err)
+		}
+
+		// The action settles every open report about the target
+		err := tx.Where("target_type = ? AND target_id = ? AND status IN ?", targetType, targetID, openReportStatuses).
+			Find(&resolved).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch reports: %w", err)
+		}
+		if len(resolved) > 0 {
+			ids := make([]uint, len(resolved))
+			for i, report := range resolved {
+				ids[i] = report.ID
+			}
+			err = tx.Model(&models.Report{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"status":      models.ReportStatusResolved,
+				"resolution":  fmt.Sprintf("%s: %s", req.Action, req.Reason),
+				"resolved_by": moderatorID,
+				"resolved_at": now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to resolve reports: %w", err)
+			}
+		}
+
+		title, message := moderationNotice(action, target)
+		err = notify(tx, target.UserID, title, message, map[string]interface{}{
+			"kind":        "moderation",
+			"action":      action.Action,
+			"target_type": action.TargetType,
+			"target_id":   action.TargetID,
+			"expires_at":  action.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		return notifyReporters(tx, resolved, models.ReportStatusResolved, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedIDs := make([]uint, len(resolved))
+	for i, report := range resolved {
+		resolvedIDs[i] = report.ID
+	}
+	s.logChange(moderatorID, ActionModerate, "moderation_action", action.ID, nil, map[string]interface{}{
+		"action":           action.Action,
+		"target_type":      action.TargetType,
+		"target_id":        action.TargetID,
+		"target_user_id":   action.TargetUserID,
+		"report_id":        action.ReportID,
+		"resolved_reports": resolvedIDs,
+		"reason":           action.Reason,
+		"expires_at":       action.ExpiresAt,
+	})
+
+	return action, nil
+}
+
+// applyModeration carries out an action. Hidden posts are archived and
+// hidden comments rejected; warnings change nothing. Changed records get a
+// new version, so edits based on an earlier fetch fail their If-Match.
+func applyModeration(tx *gorm.DB, action *models.ModerationAction, now time.Time) error {
+	var err error
+	switch action.Action {
+	case models.ModerationHide:
+		if action.TargetType == models.ReportTargetPost {
+			err = tx.Model(&models.Post{}).Where("id = ?", action.TargetID).Updates(map[string]interface{}{
+				"status":     models.PostStatusArchived,
+				"published":  false,
+				"publish_at": nil,
+				"updated_at": now,
+				"version":    gorm.Expr("version + 1"),
+			}).Error
+		} else {
+			err = tx.Model(&models.Comment{}).Where("id = ?", action.TargetID).Updates(map[string]interface{}{
+				"status":       models.CommentStatusRejected,
+				"moderated_by": action.ModeratorID,
+				"moderated_at": now,
+				"updated_at":   now,
+				"version":      gorm.Expr("version + 1"),
+			}).Error
+		}
+	case models.ModerationSuspend:
+		err = tx.Model(&models.User{}).Where("id = ?", action.TargetUserID).Updates(map[string]interface{}{
+			"suspended_until": action.ExpiresAt,
+			"version":         gorm.Expr("version + 1"),
+		}).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action.Action, action.TargetType, err)
+	}
+	return nil
+}
+
+// moderationNotice returns the title and message telling a user about an
+// action against them
+func moderationNotice(action *models.ModerationAction, target *moderationTarget) (string, string) {
+	switch action.Action {
+	case models.ModerationHide:
+		return fmt.Sprintf("Your %s was hidden", action.TargetType),
+			fmt.Sprintf("A moderator hid your %s: %s", target.Label, action.Reason)
+	case models.ModerationSuspend:
+		return "Your account was suspended",
+			fmt.Sprintf("A moderator suspended your account until %s: %s",
+				action.ExpiresAt.UTC().Format(time.RFC1123), action.Reason)
+	}
+	if action.TargetType == models.ReportTargetUser {
+		return "You received a warning", fmt.Sprintf("A moderator warned you: %s", action.Reason)
+	}
+	return "You received a warning",
+		fmt.Sprintf("A moderator warned you about your %s: %s", target.Label, action.Reason)
+}
+
+// notifyReporters tells reporters that their reports were closed
+func notifyReporters(tx *gorm.DB, reports []models.Report, status models.ReportStatus, target *moderationTarget) error {
+	outcome := "took action"
+	if status == models.ReportStatusDismissed {
+		outcome = "found no action was needed"
+	}
+
+	notified := make(map[uint]bool)
+	for _, report := range reports {
+		if notified[report.ReporterID] {
+			continue
+		}
+		notified[report.ReporterID] = true
+
+		err := notify(tx, report.ReporterID, "Your report was reviewed",
+			fmt.Sprintf("A moderator reviewed your report of the %s and %s. Thank you for reporting it.", target.Label, outcome),
+			map[string]interface{}{
+				"kind":      "report",
+				"report_id": report.ID,
+				"status":    status,
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findTarget finds the post, comment or user a report or action is about
+func (s *ModerationService) findTarget(targetType models.ReportTarget, targetID uint) (*moderationTarget, error) {
+	switch targetType {
+	case models.ReportTargetPost:
+		var post models.Post
+		if err := s.db.First(&post, targetID).Error; err != nil {
+			return nil, err
+		}
+		return &moderationTarget{UserID: post.UserID, Label: fmt.Sprintf("post %q", post.Title)}, nil
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := s.db.First(&comment, targetID).Error; err != nil {
+			return nil, err
+		}
+		return &moderationTarget{UserID: comment.UserID, Label: "comment"}, nil
+	case models.ReportTargetUser:
+		var user models.User
+		if err := s.db.First(&user, targetID).Error; err != nil {
+			return nil, err
+		}
+		return &moderationTarget{UserID: user.ID, Label: "account " + user.Username}, nil
+	}
+	return nil, fmt.Errorf("unknown report target: %s", targetType)
+}
+
+// logChange records a moderation decision in the audit trail
+func (s *ModerationService) logChange(actorID uint, action AuditAction, entityType string, entityID uint, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, action, AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
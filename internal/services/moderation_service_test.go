@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationSanctionsSpareStaff(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	moderator := createTestUser(t, db, "moderator", models.RoleModerator)
+	member := createTestUser(t, db, "member", models.RoleUser)
+	staff := createTestUser(t, db, "staff", models.RoleUser)
+	senior := createTestUser(t, db, "senior", "senior")
+	permissions := NewPermissionService(db, nil)
+	service := NewModerationService(db, nil)
+
+	// staff holds moderator through a group, and senior inherits it from
+	// their role's parent
+	models.SetRoleHierarchy(map[models.Role]models.Role{
+		models.RoleAdmin:     models.RoleModerator,
+		"senior":             models.RoleModerator,
+		models.RoleModerator: models.RoleUser,
+		models.RoleUser:      "",
+	})
+	authz.SetRoleResolver(permissions.GroupRoles)
+	t.Cleanup(func() {
+		models.SetRoleHierarchy(map[models.Role]models.Role{
+			models.RoleAdmin:     models.RoleModerator,
+			models.RoleModerator: models.RoleUser,
+			models.RoleUser:      "",
+		})
+		authz.SetRoleResolver(func(uint) ([]models.Role, error) { return nil, nil })
+	})
+	group := &models.Group{Name: "staff"}
+	require.NoError(t, db.Create(group).Error)
+	require.NoError(t, db.Create(&models.GroupRole{GroupID: group.ID, Role: models.RoleModerator}).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: group.ID, UserID: staff.ID}).Error)
+
+	sanction := func(actor, target *models.User, action models.ModerationActionType) error {
+		t.Helper()
+		req := &models.ModerationActionRequest{Action: action, TargetType: models.ReportTargetUser, TargetID: target.ID, Reason: "Rude"}
+		if action == models.ModerationSuspend {
+			req.Days = 7
+		}
+		_, err := service.TakeAction(actor.ID, req)
+		return err
+	}
+
+	var denied *authz.DeniedError
+	for _, target := range []*models.User{staff, senior} {
+		for _, action := range []models.ModerationActionType{models.ModerationWarn, models.ModerationSuspend} {
+			assert.ErrorAs(t, sanction(moderator, target, action), &denied, "%s %s", action, target.Username)
+		}
+	}
+	var count int64
+	require.NoError(t, db.Model(&models.ModerationAction{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Admins may sanction staff, and moderators everyone else
+	require.NoError(t, sanction(admin, staff, models.ModerationWarn))
+	require.NoError(t, sanction(admin, senior, models.ModerationSuspend))
+	require.NoError(t, sanction(moderator, member, models.ModerationSuspend))
+
+	// Suspended users are denied until their suspension ends
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: member.ID}
+	require.NoError(t, db.Create(post).Error)
+	assert.ErrorAs(t, authorize(db, member.ID, "update", post), &denied)
+	assert.ErrorAs(t, authorize(db, member.ID, "create", authz.Kind("post")), &denied)
+	assert.ErrorAs(t, sanction(senior, member, models.ModerationWarn), &denied, "suspended staff cannot moderate")
+
+	require.NoError(t, db.Model(member).Update("suspended_until", nil).Error)
+	assert.NoError(t, authorize(db, member.ID, "update", post))
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -67,6 +68,7 @@ type NotificationTemplate struct {
 // Notification represents a notification record
 type Notification struct {
 	ID          uint                 `json:"id" gorm:"primaryKey"`
+	TenantID    *uint                `json:"tenant_id,omitempty" gorm:"index"`
 	UserID      *uint                `json:"user_id" gorm:"index"`
 	Type        NotificationType     `json:"type" gorm:"not null"`
 	Priority    NotificationPriority `json:"priority" gorm:"default:normal"`
@@ -90,6 +92,34 @@ type Notification struct {
 	User *models.User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// TableName keeps delivery records apart from the in-app inbox, which is
+// stored in the notifications table
+func (Notification) TableName() string {
+	return "notification_deliveries"
+}
+
+// DefaultNotificationTemplates are the templates the application sends
+// notifications with. Missing ones are created by Setup; existing ones are
+// left as admins edited them.
+var DefaultNotificationTemplates = []NotificationTemplate{
+	{
+		Name:      "mention_in_app",
+		Type:      NotificationInApp,
+		Subject:   "{{.Author}} mentioned you",
+		Body:      "{{.Author}} mentioned you in {{.Where}} on {{.Title}}",
+		Variables: `["Author","Where","Title","URL"]`,
+		IsActive:  true,
+	},
+	{
+		Name:      "mention_email",
+		Type:      NotificationEmail,
+		Subject:   "{{.Author}} mentioned you",
+		Body:      `<p>{{.Author}} mentioned you in {{.Where}} on <a href="{{.URL}}">{{.Title}}</a>.</p><p>You can turn off mention notifications in your account settings.</p>`,
+		Variables: `["Author","Where","Title","URL"]`,
+		IsActive:  true,
+	},
+}
+
 // NotificationChannel interface for different notification channels
 type NotificationChannel interface {
 	Send(notification *Notification) error
@@ -156,6 +186,42 @@ func NewNotificationService(db *gorm.DB, config *config.Config, logger *logger.L
 	return service
 }
 
+// Setup creates the notification tables and the missing default templates
+func (ns *NotificationService) Setup() error {
+	if err := ns.db.AutoMigrate(&NotificationTemplate{}, &Notification{}); err != nil {
+		return fmt.Errorf("failed to migrate notification tables: %w", err)
+	}
+
+	for _, tmpl := range DefaultNotificationTemplates {
+		tmpl := tmpl
+		if err := ns.db.Where("name = ?", tmpl.Name).FirstOrCreate(&tmpl).Error; err != nil {
+			return fmt.Errorf("failed to create notification template %s: %w", tmpl.Name, err)
+		}
+	}
+	return nil
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (ns *NotificationService) WithContext(ctx context.Context) *NotificationService {
+	clone := *ns
+	clone.db = ns.db.WithContext(ctx)
+	if ns.audit != nil {
+		clone.audit = ns.audit.WithContext(ctx)
+	}
+
+	// In-app notifications are stored through the service's database
+	clone.channels = make(map[NotificationType]NotificationChannel, len(ns.channels))
+	for notificationType, channel := range ns.channels {
+		clone.channels[notificationType] = channel
+	}
+	clone.channels[NotificationInApp] = &InAppChannel{
+		db:     clone.db,
+		logger: ns.logger,
+	}
+
+	return &clone
+}
+
 // SendNotification sends a notification immediately
 func (ns *NotificationService) SendNotification(notification *Notification) error {
 	// Save notification to database
@@ -242,6 +308,12 @@ func (ns *NotificationService) SendFromTemplate(templateName string, recipient s
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
+	// The variables describe what the notification is about
+	metadata, err := json.Marshal(variables)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification metadata: %w", err)
+	}
+
 	// Create notification
 	notification := &Notification{
 		UserID:    userID,
@@ -250,6 +322,7 @@ func (ns *NotificationService) SendFromTemplate(templateName string, recipient s
 		Subject:   subject,
 		Body:      body,
 		Recipient: recipient,
+		Metadata:  string(metadata),
 	}
 
 	return ns.SendNotification(notification)
@@ -356,6 +429,18 @@ func (ns *NotificationService) MarkAsRead(notificationID uint, userID uint) erro
 	return nil
 }
 
+// EmailEnabled reports whether an SMTP server is configured to send email
+// notifications through
+func (ns *NotificationService) EmailEnabled() bool {
+	return ns.config.Email.Host != ""
+}
+
+// NotifyInApp puts a notification in a user's in-app inbox. data describes
+// what the notification is about for clients to link to.
+func (ns *NotificationService) NotifyInApp(userID uint, title, message string, data map[string]interface{}) error {
+	return notify(ns.db, userID, title, message, data)
+}
+
 // CreateTemplate creates a new notification template
 func (ns *NotificationService) CreateTemplate(template *NotificationTemplate) error {
 	return ns.db.Create(template).Error
@@ -468,8 +553,21 @@ func (sc *SMSChannel) GetType() NotificationType {
 
 // InAppChannel implementation
 func (iac *InAppChannel) Send(notification *Notification) error {
-	// For in-app notifications, we just update the database record
-	// The frontend would poll or use websockets to get new notifications
+	if notification.UserID == nil {
+		return errors.New("in-app notifications need a user")
+	}
+
+	// In-app notifications go to the user's inbox, which the frontend polls
+	var data map[string]interface{}
+	if notification.Metadata != "" {
+		if err := json.Unmarshal([]byte(notification.Metadata), &data); err != nil {
+			return fmt.Errorf("invalid notification metadata: %w", err)
+		}
+	}
+	if err := notify(iac.db, *notification.UserID, notification.Subject, notification.Body, data); err != nil {
+		return err
+	}
+
 	iac.logger.Info("In-app notification created", map[string]interface{}{
 		"notification_id": notification.ID,
 		"user_id":         notification.UserID,
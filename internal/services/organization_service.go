@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// orgSlugPattern restricts organization slugs to values usable as subdomains
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// OrganizationService manages organizations (tenants) and their members
+type OrganizationService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(db *gorm.DB, auditService *AuditService) *OrganizationService {
+	return &OrganizationService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// CreateOrganization creates an organization owned by the user
+func (s *OrganizationService) CreateOrganization(userID uint, req *models.OrganizationCreateRequest) (*models.Organization, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !orgSlugPattern.MatchString(slug) {
+		return nil, errors.New("slug must contain only lowercase letters, digits and '-'")
+	}
+
+	var count int64
+	s.db.Unscoped().Model(&models.Organization{}).Where("slug = ?", slug).Count(&count)
+	if count > 0 {
+		return nil, errors.New("organization slug already taken")
+	}
+
+	org := &models.Organization{
+		Name:     strings.TrimSpace(req.Name),
+		Slug:     slug,
+		IsActive: true,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         userID,
+			Role:           models.OrgRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	s.logChange(org.ID, userID, ActionCreate, "organization", org.ID, nil, map[string]interface{}{
+		"name": org.Name,
+		"slug": org.Slug,
+	})
+
+	return org, nil
+}
+
+// GetOrganization retrieves an organization by ID
+func (s *OrganizationService) GetOrganization(id uint) (*models.Organization, error) {
+	var org models.Organization
+	if err := s.db.First(&org, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &org, nil
+}
+
+// ResolveOrganization finds an active organization by numeric ID or slug
+func (s *OrganizationService) ResolveOrganization(ref string) (*models.Organization, error) {
+	var org models.Organization
+	query := s.db.Where("is_active = ?", true)
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("slug = ?", strings.ToLower(ref))
+	}
+
+	if err := query.First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &org, nil
+}
+
+// ListUserOrganizations lists the organizations the user is a member of
+func (s *OrganizationService) ListUserOrganizations(userID uint) ([]models.OrganizationMember, error) {
+	var memberships []models.OrganizationMember
+	err := s.db.Preload("Organization").
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name ASC").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organizations: %w", err)
+	}
+	return memberships, nil
+}
+
+// UpdateOrganization updates an organization's details
+func (s *OrganizationService) UpdateOrganization(actorID, orgID uint, req *models.OrganizationUpdateRequest) (*models.Organization, error) {
+	if err := s.requireManager(orgID, actorID); err != nil {
+		return nil, err
+	}
+
+	org, err := s.GetOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(org).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update organization: %w", err)
+		}
+		s.logChange(orgID, actorID, ActionUpdate, "organization", orgID, nil, updates)
+	}
+
+	return s.GetOrganization(orgID)
+}
+
+// GetMembership retrieves a user's membership in an organization
+func (s *OrganizationService) GetMembership(orgID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := s.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not a member of this organization")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &member, nil
+}
+
+// GetMemberOrganization retrieves an organization the actor is a member of
+func (s *OrganizationService) GetMemberOrganization(actorID, orgID uint) (*models.Organization, error) {
+	if err := s.requireMember(orgID, actorID); err != nil {
+		return nil, err
+	}
+	return s.GetOrganization(orgID)
+}
+
+// ListMembers lists an organization's members. Only members may list them.
+func (s *OrganizationService) ListMembers(actorID, orgID uint) ([]models.OrganizationMember, error) {
+	if err := s.requireMember(orgID, actorID); err != nil {
+		return nil, err
+	}
+
+	var members []models.OrganizationMember
+	err := s.db.Preload("User").
+		Where("organization_id = ?", orgID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds a user to an organization
+func (s *OrganizationService) AddMember(actorID, orgID uint, req *models.MemberAddRequest) (*models.OrganizationMember, error) {
+	role := req.Role
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+
+	if err := s.requireManager(orgID, actorID); err != nil {
+		return nil, err
+	}
+	if role == models.OrgRoleOwner {
+		if err := s.requireOwner(orgID, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	var user models.User
+	if err := s.db.First(&user, req.UserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if _, err := s.GetMembership(orgID, req.UserID); err == nil {
+		return nil, errors.New("user is already a member")
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         req.UserID,
+		Role:           role,
+	}
+	if err := s.db.Create(member).Error; err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	s.logChange(orgID, actorID, ActionMembershipChange, "organization_member", req.UserID, nil, map[string]interface{}{
+		"user_id": req.UserID,
+		"role":    role,
+	})
+
+	return member, nil
+}
+
+// UpdateMemberRole changes a member's role
+func (s *OrganizationService) UpdateMemberRole(actorID, orgID, userID uint, role models.OrgRole) (*models.OrganizationMember, error) {
+	if err := s.requireManager(orgID, actorID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.GetMembership(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == role {
+		return member, nil
+	}
+
+	// Only owners can grant or take away ownership
+	if role == models.OrgRoleOwner || member.Role == models.OrgRoleOwner {
+		if err := s.requireOwner(orgID, actorID); err != nil {
+			return nil, err
+		}
+	}
+	if member.Role == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(orgID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	oldRole := member.Role
+	err = s.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Update("role", role).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	member.Role = role
+
+	s.logChange(orgID, actorID, ActionMembershipChange, "organization_member", userID,
+		map[string]interface{}{"role": oldRole},
+		map[string]interface{}{"role": role},
+	)
+
+	return member, nil
+}
+
+// RemoveMember removes a user from an organization. Members may remove
+// themselves; the last owner cannot be removed.
+func (s *OrganizationService) RemoveMember(actorID, orgID, userID uint) error {
+	member, err := s.GetMembership(orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	if actorID != userID {
+		if err := s.requireManager(orgID, actorID); err != nil {
+			return err
+		}
+		if member.Role == models.OrgRoleOwner {
+			if err := s.requireOwner(orgID, actorID); err != nil {
+				return err
+			}
+		}
+	}
+	if member.Role == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(orgID, userID); err != nil {
+			return err
+		}
+	}
+
+	err = s.db.Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Delete(&models.OrganizationMember{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	s.logChange(orgID, actorID, ActionMembershipChange, "organization_member", userID,
+		map[string]interface{}{"user_id": userID, "role": member.Role},
+		nil,
+	)
+
+	return nil
+}
+
+// requireMember checks the actor is a member of the organization
+func (s *OrganizationService) requireMember(orgID, actorID uint) error {
+	if s.isPlatformAdmin(actorID) {
+		return nil
+	}
+
+	_, err := s.GetMembership(orgID, actorID)
+	return err
+}
+
+// requireManager checks the actor is an owner or admin of the organization
+func (s *OrganizationService) requireManager(orgID, actorID uint) error {
+	if s.isPlatformAdmin(actorID) {
+		return nil
+	}
+
+	member, err := s.GetMembership(orgID, actorID)
+	if err != nil {
+		return err
+	}
+	if !member.Role.CanManageMembers() {
+		return errors.New("only organization owners and admins can manage members")
+	}
+	return nil
+}
+
+// requireOwner checks the actor is an owner of the organization
+func (s *OrganizationService) requireOwner(orgID, actorID uint) error {
+	if s.isPlatformAdmin(actorID) {
+		return nil
+	}
+
+	member, err := s.GetMembership(orgID, actorID)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.OrgRoleOwner {
+		return errors.New("only organization owners can manage ownership")
+	}
+	return nil
+}
+
+// ensureAnotherOwner checks the organization keeps an owner besides userID
+func (s *OrganizationService) ensureAnotherOwner(orgID, userID uint) error {
+	var count int64
+	s.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ? AND user_id <> ?", orgID, models.OrgRoleOwner, userID).
+		Count(&count)
+	if count == 0 {
+		return errors.New("an organization must have at least one owner")
+	}
+	return nil
+}
+
+// isPlatformAdmin checks if the user is a platform administrator, who can
+// manage every organization
+func (s *OrganizationService) isPlatformAdmin(userID uint) bool {
+	var user models.User
+	if err := s.db.Select("id", "role").First(&user, userID).Error; err != nil {
+		return false
+	}
+	return user.IsAdmin()
+}
+
+// logChange records an organization change in the organization's audit trail
+func (s *OrganizationService) logChange(orgID, actorID uint, action AuditAction, entityType string, entityID uint, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.WithContext(tenant.WithTenant(context.Background(), orgID)).LogEvent(actorID, action, AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PermissionSet is a set of resource:action permission names
+type PermissionSet map[string]bool
+
+// Has checks if the set grants the permission. Grants may use "*" as the
+// resource or action, e.g. "post:*" or "*:*".
+func (p PermissionSet) Has(permission string) bool {
+	if p[permission] {
+		return true
+	}
+
+	resource, _, ok := models.ParsePermission(permission)
+	if !ok {
+		return false
+	}
+
+	return p[resource+":*"] || p["*:*"]
+}
+
+// List returns the permission names in sorted order
+func (p PermissionSet) List() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// maxCachedUsers bounds how many users' group grants are cached. The cache is
+// emptied when it fills up.
+const maxCachedUsers = 10000
+
+// PermissionService manages permissions and role grants stored in the database
+type PermissionService struct {
+	db           *gorm.DB
+	auditService *AuditService
+
+	// In-process caches of role, group and per-user group grants, loaded
+	// lazily and dropped on change. generation counts invalidations, so a
+	// user's grants loaded before one are not cached after it.
+	mu         sync.RWMutex
+	grants     map[models.Role]PermissionSet
+	groups     *groupGrants
+	userGroups map[uint]*groupGrant
+	generation uint64
+}
+
+// EffectiveGrants are the roles and permissions a user holds directly and
+// through group membership
+type EffectiveGrants struct {
+	Roles       []models.Role
+	Permissions PermissionSet
+}
+
+// groupGrant is what a group, or a user through all of their groups, grants
+type groupGrant struct {
+	// groupIDs are the groups a user belongs to, directly or as a member of
+	// a nested group
+	groupIDs    []uint
+	roles       []models.Role
+	permissions PermissionSet
+}
+
+// groupGrants caches the group tree and every group's grants
+type groupGrants struct {
+	parents map[uint]*uint
+	grants  map[uint]*groupGrant
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(db *gorm.DB, auditService *AuditService) *PermissionService {
+	return &PermissionService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// ListPermissions retrieves all permissions
+func (s *PermissionService) ListPermissions() ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := s.db.Order("name ASC").Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// CreatePermission creates a new resource:action permission
+func (s *PermissionService) CreatePermission(actorID uint, req *models.PermissionCreateRequest) (*models.Permission, error) {
+	resource := strings.ToLower(strings.TrimSpace(req.Resource))
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if strings.Contains(resource, ":") || strings.Contains(action, ":") {
+		return nil, errors.New("resource and action must not contain ':'")
+	}
+
+	name := resource + ":" + action
+
+	var count int64
+	s.db.Model(&models.Permission{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("permission already exists")
+	}
+
+	permission := &models.Permission{
+		Name:        name,
+		Description: req.Description,
+		Resource:    resource,
+		Action:      action,
+	}
+
+	if err := s.db.Create(permission).Error; err != nil {
+		return nil, fmt.Errorf("failed to create permission: %w", err)
+	}
+
+	s.logChange(actorID, "permission", permission.Name, nil, map[string]interface{}{
+		"name":        permission.Name,
+		"description": permission.Description,
+	})
+
+	return permission, nil
+}
+
+// DeletePermission deletes a permission and all of its role grants
+func (s *PermissionService) DeletePermission(actorID, id uint) error {
+	var permission models.Permission
+	if err := s.db.First(&permission, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("permission not found")
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", permission.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("permission_id = ?", permission.ID).Delete(&models.GroupPermission{}).Error; err != nil {
+			return err
+		}
+		// Hard delete so the name can be reused
+		return tx.Unscoped().Delete(&permission).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete permission: %w", err)
+	}
+
+	s.InvalidateCache()
+	s.logChange(actorID, "permission", permission.Name, map[string]interface{}{
+		"name": permission.Name,
+	}, nil)
+
+	return nil
+}
+
+// GetRolePermissions retrieves the permissions granted to a role
+func (s *PermissionService) GetRolePermissions(role models.Role) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := s.db.Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role = ?", role).
+		Order("permissions.name ASC").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch role permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// GrantPermission grants a permission to a role
+func (s *PermissionService) GrantPermission(actorID uint, role models.Role, name string) error {
+	permission, err := s.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	grant := &models.RolePermission{Role: role, PermissionID: permission.ID}
+	if err := s.db.Where(grant).FirstOrCreate(grant).Error; err != nil {
+		return fmt.Errorf("failed to grant permission: %w", err)
+	}
+
+	s.InvalidateCache()
+	s.logChange(actorID, "role_permission", string(role), nil, map[string]interface{}{
+		"role":       role,
+		"permission": permission.Name,
+	})
+
+	return nil
+}
+
+// RevokePermission revokes a permission from a role
+func (s *PermissionService) RevokePermission(actorID uint, role models.Role, name string) error {
+	permission, err := s.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Where("role = ? AND permission_id = ?", role, permission.ID).Delete(&models.RolePermission{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke permission: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("role does not have this permission")
+	}
+
+	s.InvalidateCache()
+	s.logChange(actorID, "role_permission", string(role), map[string]interface{}{
+		"role":       role,
+		"permission": permission.Name,
+	}, nil)
+
+	return nil
+}
+
+// PermissionsForRole returns the permissions granted to a role and every
+// role it inherits from
+func (s *PermissionService) PermissionsForRole(role models.Role) (PermissionSet, error) {
+	grants, err := s.loadGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(PermissionSet)
+	for _, ancestor := range role.Ancestors() {
+		for name := range grants[ancestor] {
+			permissions[name] = true
+		}
+	}
+	return permissions, nil
+}
+
+// HasPermission checks if a role has been granted a permission, directly or
+// through inheritance
+func (s *PermissionService) HasPermission(role models.Role, permission string) (bool, error) {
+	permissions, err := s.PermissionsForRole(role)
+	if err != nil {
+		return false, err
+	}
+	return permissions.Has(permission), nil
+}
+
+// PermissionsForUser returns the union of the roles and permissions the user
+// holds through their own role and through their groups
+func (s *PermissionService) PermissionsForUser(userID uint, role models.Role) (*EffectiveGrants, error) {
+	fromGroups, err := s.loadUserGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := &EffectiveGrants{
+		Roles:       []models.Role{role},
+		Permissions: make(PermissionSet),
+	}
+	for _, groupRole := range fromGroups.roles {
+		if groupRole != role {
+			effective.Roles = append(effective.Roles, groupRole)
+		}
+	}
+
+	for _, r := range effective.Roles {
+		permissions, err := s.PermissionsForRole(r)
+		if err != nil {
+			return nil, err
+		}
+		for name := range permissions {
+			effective.Permissions[name] = true
+		}
+	}
+	for name := range fromGroups.permissions {
+		effective.Permissions[name] = true
+	}
+
+	return effective, nil
+}
+
+// GroupRoles returns the roles a user holds through group membership
+func (s *PermissionService) GroupRoles(userID uint) ([]models.Role, error) {
+	fromGroups, err := s.loadUserGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+	return fromGroups.roles, nil
+}
+
+// GroupIDs returns the groups a user belongs to, including the groups their
+// groups are nested under
+func (s *PermissionService) GroupIDs(userID uint) ([]uint, error) {
+	fromGroups, err := s.loadUserGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+	return fromGroups.groupIDs, nil
+}
+
+// InvalidateCache drops the cached grants so they are reloaded on next use
+func (s *PermissionService) InvalidateCache() {
+	s.mu.Lock()
+	s.grants = nil
+	s.groups = nil
+	s.userGroups = nil
+	s.generation++
+	s.mu.Unlock()
+}
+
+// InvalidateUser drops the cached group grants of a single user
+func (s *PermissionService) InvalidateUser(userID uint) {
+	s.mu.Lock()
+	delete(s.userGroups, userID)
+	s.generation++
+	s.mu.Unlock()
+}
+
+// loadUserGroups returns what the user's groups and their ancestor groups
+// grant, loading the user's memberships from the database if needed
+func (s *PermissionService) loadUserGroups(userID uint) (*groupGrant, error) {
+	s.mu.RLock()
+	cached, ok := s.userGroups[userID]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	groups, err := s.loadGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var groupIDs []uint
+	err = s.db.Model(&models.GroupMember{}).
+		Joins("JOIN groups ON groups.id = group_members.group_id AND groups.deleted_at IS NULL").
+		Where("group_members.user_id = ?", userID).
+		Pluck("group_members.group_id", &groupIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load group memberships: %w", err)
+	}
+
+	result := &groupGrant{permissions: make(PermissionSet)}
+	seenGroups := make(map[uint]bool)
+	seenRoles := make(map[models.Role]bool)
+	for _, groupID := range groupIDs {
+		// Members of a nested group inherit the grants of its ancestors
+		for id := &groupID; id != nil && !seenGroups[*id]; id = groups.parents[*id] {
+			seenGroups[*id] = true
+			result.groupIDs = append(result.groupIDs, *id)
+			grant := groups.grants[*id]
+			if grant == nil {
+				continue
+			}
+			for _, role := range grant.roles {
+				if !seenRoles[role] {
+					seenRoles[role] = true
+					result.roles = append(result.roles, role)
+				}
+			}
+			for name := range grant.permissions {
+				result.permissions[name] = true
+			}
+		}
+	}
+
+	// Memberships were loaded without the lock, so they are only cached if
+	// nothing was invalidated in the meantime
+	s.mu.Lock()
+	if s.generation == generation {
+		if s.userGroups == nil || len(s.userGroups) >= maxCachedUsers {
+			s.userGroups = make(map[uint]*groupGrant)
+		}
+		s.userGroups[userID] = result
+	}
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// loadGroups returns the group tree and group grants, loading them from the
+// database if needed
+func (s *PermissionService) loadGroups() (*groupGrants, error) {
+	s.mu.RLock()
+	groups := s.groups
+	s.mu.RUnlock()
+	if groups != nil {
+		return groups, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.groups != nil {
+		return s.groups, nil
+	}
+
+	var rows []models.Group
+	if err := s.db.Select("id", "parent_id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load groups: %w", err)
+	}
+
+	groups = &groupGrants{
+		parents: make(map[uint]*uint, len(rows)),
+		grants:  make(map[uint]*groupGrant),
+	}
+	for _, group := range rows {
+		groups.parents[group.ID] = group.ParentID
+	}
+
+	grantFor := func(groupID uint) *groupGrant {
+		if groups.grants[groupID] == nil {
+			groups.grants[groupID] = &groupGrant{permissions: make(PermissionSet)}
+		}
+		return groups.grants[groupID]
+	}
+
+	var roles []models.GroupRole
+	if err := s.db.Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load group roles: %w", err)
+	}
+	for _, role := range roles {
+		grant := grantFor(role.GroupID)
+		grant.roles = append(grant.roles, role.Role)
+	}
+
+	var permissions []struct {
+		GroupID uint
+		Name    string
+	}
+	err := s.db.Model(&models.GroupPermission{}).
+		Select("group_permissions.group_id, permissions.name").
+		Joins("JOIN permissions ON permissions.id = group_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Scan(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load group permissions: %w", err)
+	}
+	for _, permission := range permissions {
+		grantFor(permission.GroupID).permissions[permission.Name] = true
+	}
+
+	s.groups = groups
+	return groups, nil
+}
+
+// loadGrants returns the role grants, loading them from the database if needed
+func (s *PermissionService) loadGrants() (map[models.Role]PermissionSet, error) {
+	s.mu.RLock()
+	grants := s.grants
+	s.mu.RUnlock()
+	if grants != nil {
+		return grants, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.grants != nil {
+		return s.grants, nil
+	}
+
+	var rows []struct {
+		Role models.Role
+		Name string
+	}
+	err := s.db.Model(&models.RolePermission{}).
+		Select("role_permissions.role, permissions.name").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+
+	grants = make(map[models.Role]PermissionSet)
+	for _, row := range rows {
+		if grants[row.Role] == nil {
+			grants[row.Role] = make(PermissionSet)
+		}
+		grants[row.Role][row.Name] = true
+	}
+
+	s.grants = grants
+	return grants, nil
+}
+
+// FindByName finds a permission by its resource:action name
+func (s *PermissionService) FindByName(name string) (*models.Permission, error) {
+	var permission models.Permission
+	if err := s.db.Where("name = ?", name).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("permission not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &permission, nil
+}
+
+// logChange records a permission change in the audit trail
+func (s *PermissionService) logChange(actorID uint, entityType, entityID string, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, ActionPermissionChange, AuditEventData{
+		EntityType: entityType,
+		EntityID:   entityID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
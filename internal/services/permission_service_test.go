@@ -0,0 +1,210 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPermissionServiceSkipsStaleUserGroups(t *testing.T) {
+	db := newTestDB(t)
+	user := createTestUser(t, db, "member", models.RoleUser)
+	group := &models.Group{Name: "editors"}
+	require.NoError(t, db.Create(group).Error)
+	require.NoError(t, db.Create(&models.GroupRole{GroupID: group.ID, Role: models.RoleModerator}).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: group.ID, UserID: user.ID}).Error)
+	service := NewPermissionService(db, nil)
+
+	// The user leaves the group while their memberships are being loaded
+	invalidate := false
+	err := db.Callback().Query().After("gorm:query").Register("test:invalidate", func(tx *gorm.DB) {
+		if invalidate && tx.Statement.Table == "group_members" {
+			invalidate = false
+			service.InvalidateUser(user.ID)
+		}
+	})
+	require.NoError(t, err)
+	invalidate = true
+
+	roles, err := service.GroupRoles(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Role{models.RoleModerator}, roles)
+	assert.NotContains(t, service.userGroups, user.ID, "grants loaded before an invalidation are not cached")
+
+	roles, err = service.GroupRoles(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Role{models.RoleModerator}, roles)
+	assert.Contains(t, service.userGroups, user.ID)
+}
+
+func TestPermissionServiceBoundsUserGroups(t *testing.T) {
+	db := newTestDB(t)
+	user := createTestUser(t, db, "member", models.RoleUser)
+	service := NewPermissionService(db, nil)
+
+	service.userGroups = make(map[uint]*groupGrant, maxCachedUsers)
+	for id := uint(1); id <= maxCachedUsers; id++ {
+		service.userGroups[user.ID+id] = &groupGrant{}
+	}
+
+	_, err := service.GroupRoles(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, service.userGroups, 1)
+	assert.Contains(t, service.userGroups, user.ID)
+}
+
+func TestPermissionSetHas(t *testing.T) {
+	tests := []struct {
+		name       string
+		granted    PermissionSet
+		permission string
+		want       bool
+	}{
+		{"exact", PermissionSet{"post:create": true}, "post:create", true},
+		{"other action", PermissionSet{"post:create": true}, "post:delete", false},
+		{"resource wildcard", PermissionSet{"post:*": true}, "post:delete", true},
+		{"other resource", PermissionSet{"post:*": true}, "comment:delete", false},
+		{"global wildcard", PermissionSet{"*:*": true}, "user:delete", true},
+		{"malformed", PermissionSet{"*:*": true}, "post", false},
+		{"empty", PermissionSet{}, "post:create", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.granted.Has(tt.permission))
+		})
+	}
+}
+
+func TestPermissionServiceGrantRevoke(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	service := NewPermissionService(db, nil)
+	_, err := service.CreatePermission(admin.ID, &models.PermissionCreateRequest{Resource: "Post", Action: "Publish"})
+	require.NoError(t, err)
+	_, err = service.CreatePermission(admin.ID, &models.PermissionCreateRequest{Resource: "post", Action: "publish"})
+	assert.Error(t, err, "permission names are unique")
+
+	// Prime the cache before every change
+	has := func(role models.Role) bool {
+		t.Helper()
+		ok, err := service.HasPermission(role, "post:publish")
+		require.NoError(t, err)
+		return ok
+	}
+	assert.False(t, has(models.RoleUser))
+
+	require.NoError(t, service.GrantPermission(admin.ID, models.RoleUser, "post:publish"))
+	assert.True(t, has(models.RoleUser))
+	assert.True(t, has(models.RoleAdmin), "roles inherit their parents' grants")
+
+	// A revoke takes effect on the next check
+	require.NoError(t, service.RevokePermission(admin.ID, models.RoleUser, "post:publish"))
+	assert.False(t, has(models.RoleUser))
+	assert.False(t, has(models.RoleAdmin))
+	assert.Error(t, service.RevokePermission(admin.ID, models.RoleUser, "post:publish"))
+
+	require.NoError(t, service.GrantPermission(admin.ID, models.RoleModerator, "post:publish"))
+	assert.True(t, has(models.RoleAdmin))
+	assert.False(t, has(models.RoleUser), "grants do not flow down the hierarchy")
+
+	// Deleting a permission drops its grants
+	permission, err := service.FindByName("post:publish")
+	require.NoError(t, err)
+	require.NoError(t, service.DeletePermission(admin.ID, permission.ID))
+	assert.False(t, has(models.RoleModerator))
+	assert.Error(t, service.GrantPermission(admin.ID, models.RoleUser, "post:publish"))
+}
+
+func TestPermissionServiceRoleHierarchy(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	for _, role := range models.DefaultRoles {
+		definition := &models.RoleDefinition{Name: role.Name, IsSystem: true}
+		if role.Parent != "" {
+			var parent models.RoleDefinition
+			require.NoError(t, db.Where("name = ?", role.Parent).First(&parent).Error)
+			definition.ParentID = &parent.ID
+		}
+		require.NoError(t, db.Create(definition).Error)
+	}
+	t.Cleanup(func() {
+		models.SetRoleHierarchy(map[models.Role]models.Role{
+			models.RoleAdmin:     models.RoleModerator,
+			models.RoleModerator: models.RoleUser,
+			models.RoleUser:      "",
+		})
+	})
+
+	service := NewPermissionService(db, nil)
+	roles := NewRoleService(db, nil, service)
+	_, err := service.CreatePermission(admin.ID, &models.PermissionCreateRequest{Resource: "post", Action: "publish"})
+	require.NoError(t, err)
+	require.NoError(t, service.GrantPermission(admin.ID, models.RoleModerator, "post:publish"))
+
+	editor := models.Role("editor")
+	_, err = roles.CreateRole(admin.ID, &models.RoleCreateRequest{Name: "editor"})
+	require.NoError(t, err)
+	ok, err := service.HasPermission(editor, "post:publish")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Moving the role under moderator drops the cached grants
+	parent := models.RoleModerator
+	_, err = roles.UpdateRole(admin.ID, editor, &models.RoleUpdateRequest{Parent: &parent})
+	require.NoError(t, err)
+	ok, err = service.HasPermission(editor, "post:publish")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPermissionServiceGroupGrants(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	user := createTestUser(t, db, "member", models.RoleUser)
+	service := NewPermissionService(db, nil)
+	groups := NewGroupService(db, nil, service)
+	_, err := service.CreatePermission(admin.ID, &models.PermissionCreateRequest{Resource: "post", Action: "publish"})
+	require.NoError(t, err)
+
+	staff, err := groups.CreateGroup(admin.ID, &models.GroupCreateRequest{Name: "staff"})
+	require.NoError(t, err)
+	editors, err := groups.CreateGroup(admin.ID, &models.GroupCreateRequest{Name: "editors", ParentID: &staff.ID})
+	require.NoError(t, err)
+	require.NoError(t, groups.GrantRole(admin.ID, staff.ID, models.RoleModerator))
+	require.NoError(t, groups.GrantPermission(admin.ID, editors.ID, "post:publish"))
+
+	effective := func() *EffectiveGrants {
+		t.Helper()
+		grants, err := service.PermissionsForUser(user.ID, user.Role)
+		require.NoError(t, err)
+		return grants
+	}
+	assert.Equal(t, []models.Role{models.RoleUser}, effective().Roles)
+
+	// Members of a nested group inherit its ancestors' grants
+	require.NoError(t, groups.AddMember(admin.ID, editors.ID, user.ID))
+	grants := effective()
+	assert.Equal(t, []models.Role{models.RoleUser, models.RoleModerator}, grants.Roles)
+	assert.True(t, grants.Permissions.Has("post:publish"))
+	groupIDs, err := service.GroupIDs(user.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint{editors.ID, staff.ID}, groupIDs)
+
+	require.NoError(t, groups.RevokeRole(admin.ID, staff.ID, models.RoleModerator))
+	grants = effective()
+	assert.Equal(t, []models.Role{models.RoleUser}, grants.Roles)
+	assert.True(t, grants.Permissions.Has("post:publish"))
+
+	require.NoError(t, groups.RevokePermission(admin.ID, editors.ID, "post:publish"))
+	assert.False(t, effective().Permissions.Has("post:publish"))
+
+	require.NoError(t, groups.GrantPermission(admin.ID, editors.ID, "post:publish"))
+	assert.True(t, effective().Permissions.Has("post:publish"))
+	require.NoError(t, groups.RemoveMember(admin.ID, editors.ID, user.ID))
+	assert.False(t, effective().Permissions.Has("post:publish"))
+}
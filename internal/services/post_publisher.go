@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+)
+
+// PostPublisher publishes scheduled posts once their publish time is reached
+type PostPublisher struct {
+	postService *PostService
+	interval    time.Duration
+	logger      *logger.Logger
+}
+
+// NewPostPublisher creates a new scheduled post publisher
+func NewPostPublisher(postService *PostService, interval time.Duration, logger *logger.Logger) *PostPublisher {
+	return &PostPublisher{
+		postService: postService,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+// Run publishes due posts every interval until ctx is done
+func (p *PostPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes the due posts of every tenant
+func (p *PostPublisher) publishDue(ctx context.Context) {
+	count, err := p.postService.WithContext(tenant.WithoutScope(ctx)).PublishDue()
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to publish scheduled posts")
+		return
+	}
+
+	if count > 0 {
+		p.logger.WithField("count", count).Info("Scheduled posts published")
+	}
+}
@@ -1,40 +1,168 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"go-backend/internal/authz"
+	"go-backend/internal/markdown"
 	"go-backend/internal/models"
+	"go-backend/internal/search"
+	"go-backend/internal/tenant"
+	"go-backend/internal/utils"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrSlugTaken is returned when a custom slug is already used by another post
+	ErrSlugTaken = errors.New("slug already taken")
+	// ErrInvalidSlug is returned when a custom slug has no letters or digits
+	ErrInvalidSlug = errors.New("slug must contain letters or digits")
+	// ErrInvalidTransition is returned when a post cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrPublishAtRequired is returned when scheduling a post without a future publish time
+	ErrPublishAtRequired = errors.New("scheduling requires a publish_at time in the future")
+	// ErrPublishRequiresReview is returned when a user who may not publish
+	// sets the deprecated published flag
+	ErrPublishRequiresReview = errors.New("publishing goes through review: set status to in_review and a moderator will publish the post")
+)
+
+// SlugMovedError is returned when a post is requested by one of its previous
+// slugs. Slug is the post's current slug.
+type SlugMovedError struct {
+	Slug string
+}
+
+func (e *SlugMovedError) Error() string {
+	return fmt.Sprintf("post moved to slug %q", e.Slug)
+}
+
+// PostSearchResult is a post matched by a search with its rank and
+// highlighted excerpts
+type PostSearchResult struct {
+	models.Post
+	Rank      float64          `json:"rank"`
+	Highlight search.Highlight `json:"highlight"`
+}
+
 // PostService provides post-specific business logic using the generic CRUD service
 type PostService struct {
 	*CRUDService[models.Post]
-	db           *gorm.DB
-	auditService *AuditService
+	db              *gorm.DB
+	auditService    *AuditService
+	revisionService *RevisionService
+	searchIndex     search.Index
+	followService   *FollowService
+	mentionService  *MentionService
+}
+
+// NewPostService creates a new post service instance. Posts published for
+// the first time are delivered to the author's followers through
+// followService, and users mentioned in posts are notified through
+// mentionService, when they are given.
+func NewPostService(db *gorm.DB, auditService *AuditService, revisionService *RevisionService, searchIndex search.Index, followService *FollowService, mentionService *MentionService) *PostService {
+	return &PostService{
+		CRUDService:     NewCRUDService[models.Post](db),
+		db:              db,
+		auditService:    auditService,
+		revisionService: revisionService,
+		searchIndex:     searchIndex,
+		followService:   followService,
+		mentionService:  mentionService,
+	}
 }
 
-// NewPostService creates a new post service instance
-func NewPostService(db *gorm.DB, auditService *AuditService) *PostService {
+// WithContext returns a copy of the service whose queries use ctx
+func (s *PostService) WithContext(ctx context.Context) *PostService {
+	var auditService *AuditService
+	if s.auditService != nil {
+		auditService = s.auditService.WithContext(ctx)
+	}
+	var revisionService *RevisionService
+	if s.revisionService != nil {
+		revisionService = s.revisionService.WithContext(ctx)
+	}
+	var followService *FollowService
+	if s.followService != nil {
+		followService = s.followService.WithContext(ctx)
+	}
+	var mentionService *MentionService
+	if s.mentionService != nil {
+		mentionService = s.mentionService.WithContext(ctx)
+	}
 	return &PostService{
-		CRUDService:  NewCRUDService[models.Post](db),
-		db:           db,
-		auditService: auditService,
+		CRUDService:     s.CRUDService.WithContext(ctx),
+		db:              s.db.WithContext(ctx),
+		auditService:    auditService,
+		revisionService: revisionService,
+		searchIndex:     s.searchIndex,
+		followService:   followService,
+		mentionService:  mentionService,
 	}
 }
 
 // CreatePost creates a new post with audit logging
-func (s *PostService) CreatePost(userID uint, title, content string) (*models.Post, error) {
+func (s *PostService) CreatePost(userID uint, req *models.PostCreateRequest) (*models.Post, error) {
+	if err := authorize(s.db, userID, "create", authz.Kind("post")); err != nil {
+		return nil, fmt.Errorf("unauthorized to create posts: %w", err)
+	}
+
+	slug, err := s.resolveSlug(req.Slug, req.Title, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	contentHTML, err := markdown.Render(req.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
 	post := &models.Post{
-		UserID:    userID,
-		Title:     title,
-		Content:   content,
+		UserID:        userID,
+		Title:         req.Title,
+		Content:       req.Content,
+		ContentHTML:   contentHTML,
+		RenderVersion: markdown.Version,
+		Slug:          slug,
+		Status:        models.PostStatusDraft,
+
+		CommentsRequireApproval: req.CommentsRequireApproval,
+
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.Create(post); err != nil {
+	// New posts start as drafts and may move straight on to another status
+	status := req.Status
+	if status == "" && req.Published {
+		status = models.PostStatusPublished
+	}
+	if status != "" && status != models.PostStatusDraft {
+		updates, err := s.statusUpdates(post, status, req.PublishAt, userID)
+		if err != nil {
+			return nil, publishError(err, req.Status == "")
+		}
+		post.Status = status
+		post.Published = updates["published"].(bool)
+		post.PublishAt, _ = updates["publish_at"].(*time.Time)
+		post.PublishedAt, _ = updates["published_at"].(*time.Time)
+	}
+
+	// Create the post together with its first revision
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if s.revisionService == nil {
+			return nil
+		}
+		_, err := s.revisionService.Record(tx, post, userID, "")
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -42,56 +170,204 @@ func (s *PostService) CreatePost(userID uint, title, content string) (*models.Po
 	if s.auditService != nil {
 		auditData := AuditEventData{
 			EntityType: "post",
-			EntityID:   string(rune(post.ID)),
+			EntityID:   strconv.FormatUint(uint64(post.ID), 10),
 			NewValues: map[string]interface{}{
-				"title":   post.Title,
-				"content": post.Content,
-				"user_id": post.UserID,
+				"title":     post.Title,
+				"content":   post.Content,
+				"published": post.Published,
+				"status":    post.Status,
+				"user_id":   post.UserID,
 			},
 		}
 		s.auditService.LogEvent(userID, ActionCreate, auditData)
 	}
 
+	if s.mentionService != nil {
+		s.mentionService.Record(userID, models.MentionTargetPost, post.ID, post.ID, post.Content)
+	}
+	if post.Status == models.PostStatusPublished {
+		s.announce(post)
+	}
+
+	return s.GetByID(post.ID, "User")
+}
+
+// GetPostForUser gets a post the user may read. A zero user ID reads as an
+// anonymous visitor.
+func (s *PostService) GetPostForUser(postID, userID uint) (*models.Post, error) {
+	post, err := s.GetByID(postID, "User", "Tags", "Categories")
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user may read the post
+	if err := authorize(s.db, userID, "read", post); err != nil {
+		return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+
+	if err := s.withReactions([]*models.Post{post}); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
-// UpdatePost updates a post with authorization check
-func (s *PostService) UpdatePost(postID, userID uint, title, content *string) (*models.Post, error) {
+// GetPostBySlugForUser gets a post the user may read by its slug. A zero user
+// ID reads as an anonymous visitor. Previous slugs of a post return a
+// *SlugMovedError with the current slug.
+func (s *PostService) GetPostBySlugForUser(slug string, userID uint) (*models.Post, error) {
+	post, err := s.FindOne(map[string]interface{}{"slug": slug}, "User", "Tags", "Categories")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var redirect models.PostSlugRedirect
+		if s.db.Where("slug = ?", slug).First(&redirect).Error != nil {
+			return nil, err
+		}
+
+		post, err = s.GetByID(redirect.PostID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only reveal the current slug to users who may read the post
+		if err := authorize(s.db, userID, "read", post); err != nil {
+			return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+		}
+		return nil, &SlugMovedError{Slug: post.Slug}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user may read the post
+	if err := authorize(s.db, userID, "read", post); err != nil {
+		return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+
+	if err := s.withReactions([]*models.Post{post}); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// UpdatePost updates a post with authorization check. Changes to the title
+// or content are recorded as a new revision.
+func (s *PostService) UpdatePost(postID, userID uint, req *models.PostUpdateRequest) (*models.Post, error) {
+	return s.updatePost(postID, userID, req, "")
+}
+
+// RestoreRevision sets a post's title and content back to those of one of its
+// revisions, recording the result as a new revision
+func (s *PostService) RestoreRevision(postID uint, number int, userID uint) (*models.Post, error) {
+	if s.revisionService == nil {
+		return nil, errors.New("revisions are not enabled")
+	}
+
+	revision, err := s.revisionService.findRevision(postID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &models.PostUpdateRequest{
+		Title:   &revision.Title,
+		Content: &revision.Content,
+	}
+	return s.updatePost(postID, userID, req, fmt.Sprintf("Restored from revision %d", number))
+}
+
+// updatePost applies an update. A non-empty note always records a revision
+// with that note, even when the title and content are unchanged.
+func (s *PostService) updatePost(postID, userID uint, req *models.PostUpdateRequest, note string) (*models.Post, error) {
 	// Get the existing post
 	existingPost, err := s.GetByID(postID, "User")
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if user owns the post or is admin
-	if existingPost.UserID != userID {
-		var user models.User
-		if err := s.db.First(&user, userID).Error; err != nil {
-			return nil, errors.New("unauthorized")
-		}
-		if user.Role != models.RoleAdmin && user.Role != models.RoleModerator {
-			return nil, errors.New("unauthorized to edit this post")
-		}
+	// Check the user may edit the post
+	if err := authorize(s.db, userID, "update", existingPost); err != nil {
+		return nil, fmt.Errorf("unauthorized to edit this post: %w", err)
+	}
+	if err := checkVersion(s.db, existingPost.Version); err != nil {
+		return nil, err
 	}
 
 	// Store old values for audit
 	oldValues := map[string]interface{}{
-		"title":   existingPost.Title,
-		"content": existingPost.Content,
+		"title":     existingPost.Title,
+		"content":   existingPost.Content,
+		"published": existingPost.Published,
+		"status":    existingPost.Status,
 	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
 	newValues := make(map[string]interface{})
 
-	if title != nil {
-		updates["title"] = *title
-		newValues["title"] = *title
+	if req.Title != nil {
+		updates["title"] = *req.Title
+		newValues["title"] = *req.Title
+	}
+
+	if req.Content != nil {
+		contentHTML, err := markdown.Render(*req.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render content: %w", err)
+		}
+		updates["content"] = *req.Content
+		updates["content_html"] = contentHTML
+		updates["render_version"] = markdown.Version
+		newValues["content"] = *req.Content
+	}
+
+	// Publishing moves the post to published; unpublishing back to draft
+	if req.Published != nil {
+		status := models.PostStatusDraft
+		if *req.Published {
+			status = models.PostStatusPublished
+		}
+		if status != existingPost.Status {
+			statusUpdates, err := s.statusUpdates(existingPost, status, nil, userID)
+			if err != nil {
+				return nil, publishError(err, *req.Published)
+			}
+			for field, value := range statusUpdates {
+				updates[field] = value
+				newValues[field] = value
+			}
+		}
+	}
+
+	if req.CommentsRequireApproval != nil {
+		updates["comments_require_approval"] = *req.CommentsRequireApproval
+		newValues["comments_require_approval"] = *req.CommentsRequireApproval
+	}
+
+	// A new title gets a new slug unless a custom slug is given
+	var slug string
+	if req.Slug != nil {
+		if slug, err = s.resolveSlug(*req.Slug, "", postID); err != nil {
+			return nil, err
+		}
+	} else if req.Title != nil && *req.Title != existingPost.Title {
+		if slug, err = s.uniqueSlug(*req.Title, postID); err != nil {
+			return nil, fmt.Errorf("failed to generate slug: %w", err)
+		}
 	}
+	slugChanged := slug != "" && slug != existingPost.Slug
 
-	if content != nil {
-		updates["content"] = *content
-		newValues["content"] = *content
+	// The revision snapshots the post as it is after the update
+	snapshot := *existingPost
+	if req.Title != nil {
+		snapshot.Title = *req.Title
+	}
+	if req.Content != nil {
+		snapshot.Content = *req.Content
+	}
+	recordRevision := s.revisionService != nil &&
+		(note != "" || snapshot.Title != existingPost.Title || snapshot.Content != existingPost.Content)
+	if slugChanged {
+		oldValues["slug"] = existingPost.Slug
+		updates["slug"] = slug
+		newValues["slug"] = slug
 	}
 
 	// Add update timestamp
@@ -99,15 +375,41 @@ func (s *PostService) UpdatePost(postID, userID uint, title, content *string) (*
 
 	// Perform the update
 	if len(updates) > 1 { // More than just updated_at
-		if err := s.Update(postID, updates); err != nil {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := updateVersioned(tx, &models.Post{}, postID, updates); err != nil {
+				return err
+			}
+			if recordRevision {
+				if err := s.recordRevision(tx, existingPost, &snapshot, userID, note); err != nil {
+					return err
+				}
+			}
+			if !slugChanged {
+				return nil
+			}
+
+			// Keep the old slug as a redirect. The post may take back one of
+			// its own previous slugs, which then stops redirecting.
+			if err := tx.Where("slug = ?", slug).Delete(&models.PostSlugRedirect{}).Error; err != nil {
+				return err
+			}
+			if existingPost.Slug == "" {
+				return nil
+			}
+			return tx.Create(&models.PostSlugRedirect{Slug: existingPost.Slug, PostID: postID}).Error
+		})
+		if err != nil {
 			return nil, err
 		}
 
 		// Log the update in audit trail
 		if s.auditService != nil {
+			if note != "" {
+				newValues["note"] = note
+			}
 			auditData := AuditEventData{
 				EntityType: "post",
-				EntityID:   string(rune(postID)),
+				EntityID:   strconv.FormatUint(uint64(postID), 10),
 				OldValues:  oldValues,
 				NewValues:  newValues,
 			}
@@ -116,7 +418,218 @@ func (s *PostService) UpdatePost(postID, userID uint, title, content *string) (*
 	}
 
 	// Get and return the updated post
-	return s.GetByID(postID, "User")
+	post, err := s.GetByID(postID, "User")
+	if err != nil {
+		return nil, err
+	}
+	if s.mentionService != nil && req.Content != nil {
+		s.mentionService.Record(post.UserID, models.MentionTargetPost, post.ID, post.ID, post.Content)
+	}
+	if _, firstPublished := updates["published_at"]; firstPublished {
+		s.announce(post)
+	}
+	return post, nil
+}
+
+// ChangeStatus moves a post through the editorial workflow. Scheduling needs
+// a future publish time; the post is published when it is reached.
+func (s *PostService) ChangeStatus(postID, userID uint, req *models.PostStatusChangeRequest) (*models.Post, error) {
+	existingPost, err := s.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user may see the post before revealing anything about it
+	if err := authorize(s.db, userID, "read", existingPost); err != nil {
+		return nil, fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+
+	updates, err := s.statusUpdates(existingPost, req.Status, req.PublishAt, userID)
+	if err != nil {
+		return nil, err
+	}
+	updates["updated_at"] = time.Now()
+
+	if err := s.Update(postID, updates); err != nil {
+		return nil, err
+	}
+
+	// Log the status change in audit trail
+	if s.auditService != nil {
+		auditData := AuditEventData{
+			EntityType: "post",
+			EntityID:   strconv.FormatUint(uint64(postID), 10),
+			OldValues: map[string]interface{}{
+				"status":     existingPost.Status,
+				"publish_at": existingPost.PublishAt,
+			},
+			NewValues: map[string]interface{}{
+				"status":     req.Status,
+				"publish_at": updates["publish_at"],
+			},
+		}
+		s.auditService.LogEvent(userID, ActionUpdate, auditData)
+	}
+
+	post, err := s.GetByID(postID, "User")
+	if err != nil {
+		return nil, err
+	}
+	if _, firstPublished := updates["published_at"]; firstPublished {
+		s.announce(post)
+	}
+	return post, nil
+}
+
+// PublishDue publishes scheduled posts whose publish time has passed and
+// returns how many were published. It runs outside any user's request, so
+// the service should be scoped with tenant.WithoutScope.
+func (s *PostService) PublishDue() (int64, error) {
+	now := time.Now()
+
+	var due []models.Post
+	err := s.db.Select("id, published_at").
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, now).
+		Find(&due).Error
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+	postIDs := make([]uint, len(due))
+	var firstPublishedIDs []uint
+	for i, post := range due {
+		postIDs[i] = post.ID
+		if post.PublishedAt == nil {
+			firstPublishedIDs = append(firstPublishedIDs, post.ID)
+		}
+	}
+
+	result := s.db.Model(&models.Post{}).
+		Where("id IN ? AND status = ?", postIDs, models.PostStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":       models.PostStatusPublished,
+			"published":    true,
+			"published_at": gorm.Expr("COALESCE(published_at, publish_at)"),
+			"publish_at":   nil,
+			"updated_at":   now,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// Log the publication in audit trail
+	if s.auditService != nil && result.RowsAffected > 0 {
+		auditData := AuditEventData{
+			EntityType: "post",
+			NewValues: map[string]interface{}{
+				"published_ids": postIDs,
+				"status":        models.PostStatusPublished,
+			},
+		}
+		s.auditService.LogSystemEvent(ActionUpdate, auditData)
+	}
+
+	// Deliver the posts to followers and mentioned users within each post's
+	// tenant
+	if len(firstPublishedIDs) > 0 {
+		var published []models.Post
+		err := s.db.Where("id IN ? AND status = ?", firstPublishedIDs, models.PostStatusPublished).
+			Find(&published).Error
+		if err != nil {
+			return result.RowsAffected, fmt.Errorf("failed to load published posts: %w", err)
+		}
+		for i := range published {
+			postService := s
+			if published[i].TenantID != nil {
+				postService = s.WithContext(tenant.ForTenant(s.db.Statement.Context, *published[i].TenantID))
+			}
+			postService.announce(&published[i])
+		}
+	}
+
+	return result.RowsAffected, nil
+}
+
+// publishError explains a denied publish through the deprecated published
+// flag, which only users who may publish can set
+func publishError(err error, published bool) error {
+	var denied *authz.DeniedError
+	if published && errors.As(err, &denied) {
+		return ErrPublishRequiresReview
+	}
+	return err
+}
+
+// statusUpdates checks the user may move the post to a status and returns
+// the column updates for the move
+func (s *PostService) statusUpdates(post *models.Post, to models.PostStatus, publishAt *time.Time, userID uint) (map[string]interface{}, error) {
+	from := post.Status
+	if from == "" {
+		from = models.PostStatusDraft
+	}
+
+	// Rescheduling a scheduled post keeps its status
+	if from != to && !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	if from == to && to != models.PostStatusScheduled {
+		return nil, fmt.Errorf("%w: post is already %s", ErrInvalidTransition, to)
+	}
+
+	action := transitionAction(from, to)
+	if err := authorize(s.db, userID, action, post); err != nil {
+		return nil, fmt.Errorf("unauthorized to move this post to %s: %w", to, err)
+	}
+
+	updates := map[string]interface{}{
+		"status":     to,
+		"published":  to == models.PostStatusPublished,
+		"publish_at": (*time.Time)(nil),
+	}
+
+	switch to {
+	case models.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return nil, ErrPublishAtRequired
+		}
+		updates["publish_at"] = publishAt
+	case models.PostStatusPublished:
+		// Keep the original publication time when republishing
+		if post.PublishedAt == nil {
+			now := time.Now()
+			updates["published_at"] = &now
+		}
+	}
+
+	return updates, nil
+}
+
+// announce delivers a post published for the first time to the author's
+// followers and the users mentioned in it
+func (s *PostService) announce(post *models.Post) {
+	if s.followService != nil {
+		s.followService.PostPublished(post)
+	}
+	if s.mentionService != nil {
+		s.mentionService.PostPublished(post)
+	}
+}
+
+// transitionAction returns the authorization action a status change needs.
+// Authors submit and archive; publishing, scheduling and unpublishing are
+// editorial decisions.
+func transitionAction(from, to models.PostStatus) string {
+	switch to {
+	case models.PostStatusInReview:
+		return "submit"
+	case models.PostStatusArchived:
+		return "archive"
+	case models.PostStatusDraft:
+		if from == models.PostStatusInReview || from == models.PostStatusArchived {
+			return "update"
+		}
+	}
+	return "publish"
 }
 
 // DeletePost deletes a post with authorization check
@@ -127,19 +640,19 @@ func (s *PostService) DeletePost(postID, userID uint) error {
 		return err
 	}
 
-	// Check if user owns the post or is admin
-	if existingPost.UserID != userID {
-		var user models.User
-		if err := s.db.First(&user, userID).Error; err != nil {
-			return errors.New("unauthorized")
-		}
-		if user.Role != models.RoleAdmin && user.Role != models.RoleModerator {
-			return errors.New("unauthorized to delete this post")
-		}
+	// Check the user may delete the post
+	if err := authorize(s.db, userID, "delete", existingPost); err != nil {
+		return fmt.Errorf("unauthorized to delete this post: %w", err)
 	}
 
-	// Delete the post
-	if err := s.Delete(postID); err != nil {
+	// Move the post to the trash, recording who deleted it
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteVersioned(tx, &models.Post{}, postID); err != nil {
+			return err
+		}
+		return recordDeletion(tx, &models.Post{}, []uint{postID}, userID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -147,7 +660,7 @@ func (s *PostService) DeletePost(postID, userID uint) error {
 	if s.auditService != nil {
 		auditData := AuditEventData{
 			EntityType: "post",
-			EntityID:   string(rune(postID)),
+			EntityID:   strconv.FormatUint(uint64(postID), 10),
 			OldValues: map[string]interface{}{
 				"title":   existingPost.Title,
 				"content": existingPost.Content,
@@ -177,11 +690,16 @@ func (s *PostService) GetUserPosts(userID uint, options QueryOptions) (*Paginate
 		options.Preload = append(options.Preload, "User")
 	}
 
-	return s.FindMany(conditions, options)
+	return s.withPageReactions(s.FindMany(conditions, options))
 }
 
 // GetPublishedPosts gets all published posts
 func (s *PostService) GetPublishedPosts(options QueryOptions) (*PaginatedResult[models.Post], error) {
+	if options.Filter.Filters == nil {
+		options.Filter.Filters = make(map[string]interface{})
+	}
+	options.Filter.Filters["status"] = string(models.PostStatusPublished)
+
 	// Add User preload to options if not already present
 	found := false
 	for _, preload := range options.Preload {
@@ -194,26 +712,141 @@ func (s *PostService) GetPublishedPosts(options QueryOptions) (*PaginatedResult[
 		options.Preload = append(options.Preload, "User")
 	}
 
-	return s.GetAll(options)
+	return s.withPageReactions(s.GetAll(options))
 }
 
-// SearchPosts searches posts by title and content
-func (s *PostService) SearchPosts(query string, options QueryOptions) (*PaginatedResult[models.Post], error) {
-	options.Search = query
+// SearchPosts searches the titles and content of published posts using the
+// full-text index, best matches first. See search.Parse for the query syntax.
+func (s *PostService) SearchPosts(query string, options QueryOptions) (*PaginatedResult[PostSearchResult], error) {
+	q, err := search.Parse(query)
+	if err != nil {
+		return nil, err
+	}
 
-	// Add User preload to options if not already present
-	found := false
-	for _, preload := range options.Preload {
-		if preload == "User" {
-			found = true
-			break
+	matches := func() *gorm.DB {
+		published := s.db.Model(&models.Post{}).Where("posts.status = ?", models.PostStatusPublished)
+		return s.searchIndex.Match(published, q)
+	}
+
+	var total int64
+	if err := matches().Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	var hits []search.Hit
+	offset := (options.Pagination.Page - 1) * options.Pagination.PageSize
+	err = s.searchIndex.Rank(matches(), q).
+		Offset(offset).
+		Limit(options.Pagination.PageSize).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+
+	// Load the matched posts and return them in rank order
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	posts := make(map[uint]models.Post, len(hits))
+	if len(ids) > 0 {
+		var found []models.Post
+		if err := s.db.Preload("User").Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch search results: %w", err)
+		}
+		for _, post := range found {
+			posts[post.ID] = post
 		}
 	}
-	if !found {
-		options.Preload = append(options.Preload, "User")
+
+	results := make([]PostSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		post, ok := posts[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, PostSearchResult{
+			Post:      post,
+			Rank:      hit.Rank,
+			Highlight: hit.Highlight(),
+		})
 	}
 
-	return s.GetAll(options)
+	resultPosts := make([]*models.Post, len(results))
+	for i := range results {
+		resultPosts[i] = &results[i].Post
+	}
+	if err := s.withReactions(resultPosts); err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(options.Pagination.PageSize) - 1) / int64(options.Pagination.PageSize))
+	return &PaginatedResult[PostSearchResult]{
+		Data:       results,
+		Total:      total,
+		Page:       options.Pagination.Page,
+		PageSize:   options.Pagination.PageSize,
+		TotalPages: totalPages,
+		HasNext:    options.Pagination.Page < totalPages,
+		HasPrev:    options.Pagination.Page > 1,
+	}, nil
+}
+
+// GetPublishedPostsByTag gets the published posts carrying the tag with the
+// given slug
+func (s *PostService) GetPublishedPostsByTag(slug string, options QueryOptions) (*PaginatedResult[models.Post], error) {
+	var tag models.Tag
+	if err := s.db.Where("slug = ?", slug).First(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	var postIDs []uint
+	if err := s.db.Table("post_tags").Where("tag_id = ?", tag.ID).Pluck("post_id", &postIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tagged posts: %w", err)
+	}
+
+	return s.getPublishedPostsIn(postIDs, options)
+}
+
+// GetPublishedPostsByCategory gets the published posts filed under the
+// category with the given slug or any category below it
+func (s *PostService) GetPublishedPostsByCategory(slug string, options QueryOptions) (*PaginatedResult[models.Post], error) {
+	var category models.Category
+	if err := s.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	categoryIDs, err := NewTaxonomyService(s.db, nil).categoryDescendants(category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var postIDs []uint
+	err = s.db.Table("post_categories").
+		Where("category_id IN ?", categoryIDs).
+		Distinct().
+		Pluck("post_id", &postIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch categorized posts: %w", err)
+	}
+
+	return s.getPublishedPostsIn(postIDs, options)
+}
+
+// getPublishedPostsIn pages through the published posts among postIDs
+func (s *PostService) getPublishedPostsIn(postIDs []uint, options QueryOptions) (*PaginatedResult[models.Post], error) {
+	ids := make([]interface{}, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = id
+	}
+
+	if options.Filter.Filters == nil {
+		options.Filter.Filters = make(map[string]interface{})
+	}
+	options.Filter.Filters["id"] = ids
+	options.Preload = append(options.Preload, "Tags", "Categories")
+
+	return s.GetPublishedPosts(options)
 }
 
 // GetPostStats returns post statistics
@@ -254,6 +887,27 @@ func (s *PostService) GetPostStats() (map[string]interface{}, error) {
 	}
 	stats["top_authors"] = userPostCounts
 
+	// Most viewed published posts, ties broken by reactions
+	var popularPosts []struct {
+		ID        uint   `json:"id"`
+		Title     string `json:"title"`
+		Slug      string `json:"slug"`
+		ViewCount int64  `json:"view_count"`
+		Reactions int64  `json:"reactions"`
+	}
+	err = s.db.Model(&models.Post{}).
+		Select("posts.id, posts.title, posts.slug, posts.view_count, "+
+			"(SELECT COUNT(*) FROM reactions WHERE reactions.target_type = ? AND reactions.target_id = posts.id) AS reactions",
+			models.ReactionTargetPost).
+		Where("posts.status = ?", models.PostStatusPublished).
+		Order("view_count DESC, reactions DESC").
+		Limit(10).
+		Find(&popularPosts).Error
+	if err != nil {
+		return nil, err
+	}
+	stats["popular_posts"] = popularPosts
+
 	return stats, nil
 }
 
@@ -286,13 +940,9 @@ func (s *PostService) GetPostsByDateRange(startDate, endDate time.Time, options
 
 // BulkDeletePosts deletes multiple posts (admin only)
 func (s *PostService) BulkDeletePosts(postIDs []uint, userID uint) error {
-	// Check if user is admin
-	var user models.User
-	if err := s.db.First(&user, userID).Error; err != nil {
-		return errors.New("unauthorized")
-	}
-	if user.Role != models.RoleAdmin {
-		return errors.New("only admins can perform bulk operations")
+	// Check the user may perform bulk operations
+	if err := authorize(s.db, userID, "bulk_delete", authz.Kind("post")); err != nil {
+		return fmt.Errorf("only admins can perform bulk operations: %w", err)
 	}
 
 	// Convert to []interface{} for the generic method
@@ -320,3 +970,120 @@ func (s *PostService) BulkDeletePosts(postIDs []uint, userID uint) error {
 
 	return nil
 }
+
+// withReactions loads the reaction counts of posts in a single query
+func (s *PostService) withReactions(posts []*models.Post) error {
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	counts, err := reactionCounts(s.db, models.ReactionTargetPost, ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+	}
+	return nil
+}
+
+// withPageReactions loads the reaction counts of a page of posts
+func (s *PostService) withPageReactions(page *PaginatedResult[models.Post], err error) (*PaginatedResult[models.Post], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*models.Post, len(page.Data))
+	for i := range page.Data {
+		posts[i] = &page.Data[i]
+	}
+	if err := s.withReactions(posts); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// recordRevision records the updated post as a revision. Posts created before
+// revisions were kept first get their previous state as a baseline revision.
+func (s *PostService) recordRevision(tx *gorm.DB, before, after *models.Post, editorID uint, note string) error {
+	hasRevisions, err := s.revisionService.HasRevisions(tx, before.ID)
+	if err != nil {
+		return err
+	}
+	if !hasRevisions {
+		if _, err := s.revisionService.Record(tx, before, before.UserID, "Initial version"); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.revisionService.Record(tx, after, editorID, note)
+	return err
+}
+
+// resolveSlug returns the custom slug if given and free, or otherwise a
+// unique slug derived from the title. postID is the post the slug is for, or
+// 0 for a new post.
+func (s *PostService) resolveSlug(custom, title string, postID uint) (string, error) {
+	if custom == "" {
+		slug, err := s.uniqueSlug(title, postID)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate slug: %w", err)
+		}
+		return slug, nil
+	}
+
+	slug := utils.Slugify(custom)
+	if slug == "" {
+		return "", ErrInvalidSlug
+	}
+
+	taken, err := s.slugTaken(slug, postID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check slug: %w", err)
+	}
+	if taken {
+		return "", ErrSlugTaken
+	}
+	return slug, nil
+}
+
+// uniqueSlug derives a URL slug from a title, adding a numeric suffix when
+// the slug is already taken
+func (s *PostService) uniqueSlug(title string, postID uint) (string, error) {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = "post"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		taken, err := s.slugTaken(slug, postID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = utils.NumberedSlug(base, i)
+	}
+}
+
+// slugTaken checks if another post uses the slug, currently or as a redirect
+func (s *PostService) slugTaken(slug string, postID uint) (bool, error) {
+	// Slugs are unique across tenants and deleted posts
+	db := s.db.WithContext(tenant.WithoutScope(s.db.Statement.Context))
+
+	var count int64
+	err := db.Unscoped().Model(&models.Post{}).
+		Where("slug = ? AND id <> ?", slug, postID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = db.Model(&models.PostSlugRedirect{}).
+		Where("slug = ? AND post_id <> ?", slug, postID).
+		Count(&count).Error
+	return count > 0, err
+}
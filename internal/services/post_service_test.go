@@ -0,0 +1,120 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostServiceUniqueSlug(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	service := NewPostService(db, nil, nil, nil, nil, nil)
+
+	createPost := func(slug string) *models.Post {
+		post := &models.Post{Title: slug, Content: "content", Slug: slug, UserID: author.ID}
+		require.NoError(t, db.Create(post).Error)
+		return post
+	}
+
+	slug, err := service.uniqueSlug("Hello World", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-world", slug)
+
+	first := createPost("hello-world")
+	slug, err = service.uniqueSlug("Hello, World!", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-world-2", slug)
+
+	createPost("hello-world-2")
+	slug, err = service.uniqueSlug("Hello World", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-world-3", slug)
+
+	// A post keeps its own slug
+	slug, err = service.uniqueSlug("Hello World", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-world", slug)
+
+	// Slugs of deleted posts and old slugs kept as redirects stay taken
+	deleted := createPost("gone")
+	require.NoError(t, db.Delete(deleted).Error)
+	slug, err = service.uniqueSlug("Gone", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "gone-2", slug)
+
+	require.NoError(t, db.Create(&models.PostSlugRedirect{Slug: "old-title", PostID: first.ID}).Error)
+	slug, err = service.uniqueSlug("Old Title", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "old-title-2", slug)
+	slug, err = service.uniqueSlug("Old Title", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "old-title", slug)
+
+	// Suffixes fit within the slug length limit
+	long := createPost(utils.Slugify(strings.Repeat("word ", 30)))
+	slug, err = service.uniqueSlug(long.Title, 0)
+	require.NoError(t, err)
+	assert.Equal(t, utils.MaxSlugLength, utf8.RuneCountInString(slug))
+	assert.Equal(t, long.Slug[:utils.MaxSlugLength-2]+"-2", slug)
+
+	// Titles without letters or digits fall back to "post"
+	slug, err = service.uniqueSlug("!!!", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "post", slug)
+}
+
+func TestPostServiceResolveSlug(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	service := NewPostService(db, nil, nil, nil, nil, nil)
+
+	post := &models.Post{Title: "Taken", Content: "content", Slug: "taken", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+
+	slug, err := service.resolveSlug("Crème Brûlée", "ignored", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "creme-brulee", slug)
+
+	_, err = service.resolveSlug("Taken", "Title", 0)
+	assert.ErrorIs(t, err, ErrSlugTaken)
+
+	slug, err = service.resolveSlug("taken", "Title", post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "taken", slug)
+
+	_, err = service.resolveSlug("???", "Title", 0)
+	assert.ErrorIs(t, err, ErrInvalidSlug)
+
+	slug, err = service.resolveSlug("", "Taken", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "taken-2", slug)
+}
+
+func TestPostServicePublishedFlagRequiresReview(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	moderator := createTestUser(t, db, "moderator", models.RoleModerator)
+	service := NewPostService(db, nil, nil, nil, nil, nil)
+
+	_, err := service.CreatePost(author.ID, &models.PostCreateRequest{Title: "Hello", Content: "content", Published: true})
+	assert.ErrorIs(t, err, ErrPublishRequiresReview)
+
+	post, err := service.CreatePost(author.ID, &models.PostCreateRequest{Title: "Hello", Content: "content", Status: models.PostStatusInReview})
+	require.NoError(t, err)
+	assert.Equal(t, models.PostStatusInReview, post.Status)
+
+	published := true
+	_, err = service.UpdatePost(post.ID, author.ID, &models.PostUpdateRequest{Published: &published})
+	assert.ErrorIs(t, err, ErrPublishRequiresReview)
+
+	post, err = service.CreatePost(moderator.ID, &models.PostCreateRequest{Title: "News", Content: "content", Published: true})
+	require.NoError(t, err)
+	assert.True(t, post.Published)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownReaction is returned when reacting with an emoji outside the
+// configured set
+var ErrUnknownReaction = errors.New("reaction is not one of the allowed emoji")
+
+// ReactionService manages emoji reactions to posts and comments
+type ReactionService struct {
+	db     *gorm.DB
+	config config.ReactionsConfig
+}
+
+// NewReactionService creates a new reaction service
+func NewReactionService(db *gorm.DB, cfg config.ReactionsConfig) *ReactionService {
+	return &ReactionService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ReactionService) WithContext(ctx context.Context) *ReactionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Emoji returns the emoji users can react with
+func (s *ReactionService) Emoji() []string {
+	return s.config.Emoji
+}
+
+// React adds the user's reaction to a post or comment they can see. Adding a
+// reaction the user already made changes nothing. It returns the target's
+// reaction counts.
+func (s *ReactionService) React(userID uint, target models.ReactionTarget, targetID uint, emoji string) (map[string]int64, error) {
+	if !slices.Contains(s.config.Emoji, emoji) {
+		return nil, ErrUnknownReaction
+	}
+	if err := s.authorizeTarget(userID, target, targetID); err != nil {
+		return nil, err
+	}
+
+	reaction := &models.Reaction{
+		UserID:     userID,
+		TargetType: target,
+		TargetID:   targetID,
+		Emoji:      emoji,
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	return s.counts(target, targetID)
+}
+
+// Unreact removes the user's reaction from a post or comment. Removing a
+// reaction the user did not make changes nothing. It returns the target's
+// reaction counts.
+func (s *ReactionService) Unreact(userID uint, target models.ReactionTarget, targetID uint, emoji string) (map[string]int64, error) {
+	if err := s.authorizeTarget(userID, target, targetID); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Where("user_id = ? AND target_type = ? AND target_id = ? AND emoji = ?", userID, target, targetID, emoji).
+		Delete(&models.Reaction{}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	return s.counts(target, targetID)
+}
+
+// counts returns the reaction counts of a single target
+func (s *ReactionService) counts(target models.ReactionTarget, targetID uint) (map[string]int64, error) {
+	counts, err := reactionCounts(s.db, target, []uint{targetID})
+	if err != nil {
+		return nil, err
+	}
+	if counts[targetID] == nil {
+		return map[string]int64{}, nil
+	}
+	return counts[targetID], nil
+}
+
+// authorizeTarget checks the user can see the post or comment. Comments are
+// visible like in their post's thread: approved ones, and the user's own.
+func (s *ReactionService) authorizeTarget(userID uint, target models.ReactionTarget, targetID uint) error {
+	var post models.Post
+	switch target {
+	case models.ReactionTargetPost:
+		if err := s.db.First(&post, targetID).Error; err != nil {
+			return err
+		}
+	case models.ReactionTargetComment:
+		var comment models.Comment
+		if err := s.db.First(&comment, targetID).Error; err != nil {
+			return err
+		}
+		if comment.Status != models.CommentStatusApproved && comment.UserID != userID &&
+			authorize(s.db, userID, "moderate", authz.Kind("comment")) != nil {
+			return gorm.ErrRecordNotFound
+		}
+		if err := s.db.First(&post, comment.PostID).Error; err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown reaction target: %s", target)
+	}
+
+	if err := authorize(s.db, userID, "read", &post); err != nil {
+		return fmt.Errorf("unauthorized to view this post: %w", err)
+	}
+	return nil
+}
+
+// reactionCounts counts the reactions to each target by emoji in a single
+// query. Targets without reactions are absent from the result.
+func reactionCounts(db *gorm.DB, target models.ReactionTarget, targetIDs []uint) (map[uint]map[string]int64, error) {
+	counts := make(map[uint]map[string]int64)
+	if len(targetIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TargetID uint
+		Emoji    string
+		Count    int64
+	}
+	err := db.Model(&models.Reaction{}).
+		Select("target_id, emoji, COUNT(*) AS count").
+		Where("target_type = ? AND target_id IN ?", target, targetIDs).
+		Group("target_id, emoji").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	for _, row := range rows {
+		if counts[row.TargetID] == nil {
+			counts[row.TargetID] = make(map[string]int64)
+		}
+		counts[row.TargetID][row.Emoji] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// Diff modes for comparing revisions
+const (
+	DiffModeUnified = "unified"
+	DiffModeWords   = "words"
+)
+
+// ErrInvalidDiffMode is returned for diff modes other than unified and words
+var ErrInvalidDiffMode = errors.New("diff mode must be unified or words")
+
+// RevisionDiff is the difference between two revisions of a post
+type RevisionDiff struct {
+	PostID  uint                `json:"post_id"`
+	From    int                 `json:"from"`
+	To      int                 `json:"to"`
+	Mode    string              `json:"mode"`
+	Title   []utils.DiffSegment `json:"title"`
+	Unified string              `json:"unified,omitempty"`
+	Words   []utils.DiffSegment `json:"words,omitempty"`
+}
+
+// RevisionService keeps the revision history of posts
+type RevisionService struct {
+	db     *gorm.DB
+	config config.RevisionsConfig
+}
+
+// NewRevisionService creates a new revision service
+func NewRevisionService(db *gorm.DB, cfg config.RevisionsConfig) *RevisionService {
+	return &RevisionService{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *RevisionService) WithContext(ctx context.Context) *RevisionService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Record snapshots the post as its next revision using tx, then prunes old
+// revisions of the post
+func (s *RevisionService) Record(tx *gorm.DB, post *models.Post, editorID uint, note string) (*models.PostRevision, error) {
+	var latest int
+	err := tx.Model(&models.PostRevision{}).
+		Where("post_id = ?", post.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &models.PostRevision{
+		PostID:   post.ID,
+		Number:   latest + 1,
+		Title:    post.Title,
+		Content:  post.Content,
+		EditorID: editorID,
+		Note:     note,
+	}
+	if err := tx.Create(revision).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.prune(tx, post.ID, revision.Number); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// HasRevisions checks if any revision of the post was recorded
+func (s *RevisionService) HasRevisions(tx *gorm.DB, postID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.PostRevision{}).Where("post_id = ?", postID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListRevisions lists the revisions of a post, newest first, without their
+// content
+func (s *RevisionService) ListRevisions(postID, userID uint) ([]models.PostRevision, error) {
+	if err := s.authorizePost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	var revisions []models.PostRevision
+	err := s.db.Select("id", "post_id", "number", "title", "editor_id", "note", "created_at").
+		Preload("Editor").
+		Where("post_id = ?", postID).
+		Order("number DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision gets a revision of a post by its number
+func (s *RevisionService) GetRevision(postID uint, number int, userID uint) (*models.PostRevision, error) {
+	if err := s.authorizePost(postID, userID); err != nil {
+		return nil, err
+	}
+	return s.findRevision(postID, number)
+}
+
+// Diff compares two revisions of a post. The unified mode diffs content by
+// line; the words mode diffs it by word. Titles are always diffed by word.
+func (s *RevisionService) Diff(postID uint, from, to int, mode string, userID uint) (*RevisionDiff, error) {
+	if mode != DiffModeUnified && mode != DiffModeWords {
+		return nil, ErrInvalidDiffMode
+	}
+	if err := s.authorizePost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.findRevision(postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.findRevision(postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{
+		PostID: postID,
+		From:   from,
+		To:     to,
+		Mode:   mode,
+		Title:  utils.WordDiff(fromRevision.Title, toRevision.Title),
+	}
+
+	if mode == DiffModeWords {
+		diff.Words = utils.WordDiff(fromRevision.Content, toRevision.Content)
+		return diff, nil
+	}
+
+	diff.Unified, err = utils.UnifiedDiff(fromRevision.Content, toRevision.Content,
+		fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff revisions: %w", err)
+	}
+	return diff, nil
+}
+
+// findRevision loads a revision of a post by its number
+func (s *RevisionService) findRevision(postID uint, number int) (*models.PostRevision, error) {
+	var revision models.PostRevision
+	err := s.db.Preload("Editor").
+		Where("post_id = ? AND number = ?", postID, number).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// authorizePost checks the user may edit the post, which is required to see
+// its history
+func (s *RevisionService) authorizePost(postID, userID uint) error {
+	var post models.Post
+	if err := s.db.First(&post, postID).Error; err != nil {
+		return err
+	}
+	if err := authorize(s.db, userID, "update", &post); err != nil {
+		return fmt.Errorf("unauthorized to view this post's history: %w", err)
+	}
+	return nil
+}
+
+// prune drops revisions beyond the per-post limit and revisions older than
+// the maximum age. The latest revision is always kept.
+func (s *RevisionService) prune(tx *gorm.DB, postID uint, latest int) error {
+	if s.config.MaxPerPost > 0 {
+		err := tx.Where("post_id = ? AND number <= ?", postID, latest-s.config.MaxPerPost).
+			Delete(&models.PostRevision{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.config.MaxAge > 0 {
+		err := tx.Where("post_id = ? AND number < ? AND created_at < ?", postID, latest, time.Now().Add(-s.config.MaxAge)).
+			Delete(&models.PostRevision{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// roleNamePattern restricts role names to lowercase identifiers
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// RoleService manages roles stored in the roles table and keeps the in-process
+// role hierarchy used by models.Role in sync with it
+type RoleService struct {
+	db                *gorm.DB
+	auditService      *AuditService
+	permissionService *PermissionService
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(db *gorm.DB, auditService *AuditService, permissionService *PermissionService) *RoleService {
+	return &RoleService{
+		db:                db,
+		auditService:      auditService,
+		permissionService: permissionService,
+	}
+}
+
+// LoadHierarchy reads the roles table into the in-process role hierarchy
+func (s *RoleService) LoadHierarchy() error {
+	roles, err := s.ListRoles()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[uint]models.Role, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	parents := make(map[models.Role]models.Role, len(roles))
+	for _, role := range roles {
+		parents[role.Name] = ""
+		if role.ParentID != nil {
+			parents[role.Name] = names[*role.ParentID]
+		}
+	}
+
+	models.SetRoleHierarchy(parents)
+
+	// Inherited grants depend on the hierarchy
+	if s.permissionService != nil {
+		s.permissionService.InvalidateCache()
+	}
+
+	return nil
+}
+
+// ListRoles retrieves all roles
+func (s *RoleService) ListRoles() ([]models.RoleDefinition, error) {
+	var roles []models.RoleDefinition
+	if err := s.db.Preload("Parent").Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRole retrieves a role by name
+func (s *RoleService) GetRole(name models.Role) (*models.RoleDefinition, error) {
+	var role models.RoleDefinition
+	if err := s.db.Preload("Parent").Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &role, nil
+}
+
+// CreateRole creates a new role. Roles without an explicit parent inherit
+// from the built-in user role.
+func (s *RoleService) CreateRole(actorID uint, req *models.RoleCreateRequest) (*models.RoleDefinition, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("role name must start with a letter and contain only lowercase letters, digits, '-' or '_'")
+	}
+
+	var count int64
+	s.db.Model(&models.RoleDefinition{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		return nil, errors.New("role already exists")
+	}
+
+	parentName := req.Parent
+	if parentName == "" {
+		parentName = models.RoleUser
+	}
+
+	parent, err := s.GetRole(parentName)
+	if err != nil {
+		return nil, fmt.Errorf("parent role: %w", err)
+	}
+
+	role := &models.RoleDefinition{
+		Name:        models.Role(name),
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		ParentID:    &parent.ID,
+	}
+
+	if err := s.db.Create(role).Error; err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	if err := s.LoadHierarchy(); err != nil {
+		return nil, err
+	}
+
+	s.logChange(actorID, role.Name, nil, map[string]interface{}{
+		"name":   role.Name,
+		"parent": parent.Name,
+	})
+
+	role.Parent = parent
+	return role, nil
+}
+
+// UpdateRole updates a role's details or parent
+func (s *RoleService) UpdateRole(actorID uint, name models.Role, req *models.RoleUpdateRequest) (*models.RoleDefinition, error) {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	oldValues := make(map[string]interface{})
+	newValues := make(map[string]interface{})
+
+	if req.DisplayName != nil {
+		updates["display_name"] = *req.DisplayName
+	}
+
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if req.Parent != nil {
+		if role.IsSystem {
+			return nil, errors.New("cannot change the parent of a system role")
+		}
+
+		// The new parent must not be the role itself or one of its descendants
+		if req.Parent.Includes(role.Name) {
+			return nil, errors.New("role hierarchy cannot contain cycles")
+		}
+
+		parent, err := s.GetRole(*req.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("parent role: %w", err)
+		}
+
+		updates["parent_id"] = parent.ID
+		if role.Parent != nil {
+			oldValues["parent"] = role.Parent.Name
+		}
+		newValues["parent"] = parent.Name
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.RoleDefinition{}).Where("id = ?", role.ID).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update role: %w", err)
+		}
+	}
+
+	if len(newValues) > 0 {
+		if err := s.LoadHierarchy(); err != nil {
+			return nil, err
+		}
+		s.logChange(actorID, role.Name, oldValues, newValues)
+	}
+
+	return s.GetRole(name)
+}
+
+// DeleteRole deletes a role that is not a system role and is not in use
+func (s *RoleService) DeleteRole(actorID uint, name models.Role) error {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return err
+	}
+
+	if role.IsSystem {
+		return errors.New("system roles cannot be deleted")
+	}
+
+	var count int64
+	s.db.Model(&models.User{}).Where("role = ?", role.Name).Count(&count)
+	if count > 0 {
+		return fmt.Errorf("role is assigned to %d user(s)", count)
+	}
+
+	s.db.Model(&models.RoleDefinition{}).Where("parent_id = ?", role.ID).Count(&count)
+	if count > 0 {
+		return fmt.Errorf("role is the parent of %d role(s)", count)
+	}
+
+	s.db.Model(&models.GroupRole{}).Where("role = ?", role.Name).Count(&count)
+	if count > 0 {
+		return fmt.Errorf("role is granted to %d group(s)", count)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role.Name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	if err := s.LoadHierarchy(); err != nil {
+		return err
+	}
+
+	s.logChange(actorID, role.Name, map[string]interface{}{
+		"name": role.Name,
+	}, nil)
+
+	return nil
+}
+
+// logChange records a role change in the audit trail
+func (s *RoleService) logChange(actorID uint, name models.Role, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, ActionRoleChange, AuditEventData{
+		EntityType: "role",
+		EntityID:   string(name),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/search"
+
+	"gorm.io/gorm"
+)
+
+// Types of global search hits
+const (
+	SearchTypeUser    = "user"
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+	SearchTypeFile    = "file"
+)
+
+// SearchTypes lists the types global search covers, in facet order
+var SearchTypes = []string{SearchTypeUser, SearchTypePost, SearchTypeComment, SearchTypeFile}
+
+// ErrUnknownSearchType is returned when filtering global search by a type it
+// does not cover
+var ErrUnknownSearchType = errors.New("unknown search type")
+
+// Global search considers at most this many matches of each type, so facet
+// counts are capped at it too
+const maxSearchCandidates = 200
+
+// searchSnippetWidth is the length in characters of hit snippets
+const searchSnippetWidth = 160
+
+// SearchHit is a user, post, comment or file matched by a global search
+type SearchHit struct {
+	Type      string           `json:"type"`
+	ID        uint             `json:"id"`
+	Score     float64          `json:"score"`
+	Title     string           `json:"title"`
+	Highlight search.Highlight `json:"highlight"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+// SearchResults is a page of global search hits with the number of hits of
+// each type
+type SearchResults struct {
+	PaginatedResult[SearchHit]
+	Facets map[string]int64 `json:"facets"`
+}
+
+// accessCheck checks if the searching user may perform an action on a
+// resource
+type accessCheck func(action string, resource interface{}) bool
+
+// SearchService searches users, posts, comments and files at once, showing
+// each caller only what they may read
+type SearchService struct {
+	db          *gorm.DB
+	searchIndex search.Index
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(db *gorm.DB, searchIndex search.Index) *SearchService {
+	return &SearchService{
+		db:          db,
+		searchIndex: searchIndex,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *SearchService) WithContext(ctx context.Context) *SearchService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// Search finds the hits for query the user may read, best matches first. A
+// zero user ID searches as an anonymous visitor. Types limits the hits to
+// those types; facets always count every type.
+func (s *SearchService) Search(userID uint, query string, types []string, options PaginationOptions) (*SearchResults, error) {
+	for _, t := range types {
+		if !slices.Contains(SearchTypes, t) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSearchType, t)
+		}
+	}
+
+	q, err := search.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	if userID != 0 {
+		user = &models.User{}
+		if err := s.db.First(user, userID).Error; err != nil {
+			return nil, errors.New("unauthorized")
+		}
+	}
+	can := func(action string, resource interface{}) bool {
+		return authz.Can(s.db.Statement.Context, user, action, resource)
+	}
+
+	searchers := map[string]func(*search.Query, *models.User, accessCheck) ([]SearchHit, error){
+		SearchTypeUser:    s.searchUsers,
+		SearchTypePost:    s.searchPosts,
+		SearchTypeComment: s.searchComments,
+		SearchTypeFile:    s.searchFiles,
+	}
+
+	facets := make(map[string]int64)
+	var hits []SearchHit
+	for _, t := range SearchTypes {
+		typeHits, err := searchers[t](q, user, can)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %ss: %w", t, err)
+		}
+		if typeHits == nil {
+			// The user may not search this type at all
+			continue
+		}
+
+		facets[t] = int64(len(typeHits))
+		if len(types) == 0 || slices.Contains(types, t) {
+			hits = append(hits, typeHits...)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+
+	total := int64(len(hits))
+	start := min((options.Page-1)*options.PageSize, len(hits))
+	end := min(start+options.PageSize, len(hits))
+	totalPages := int((total + int64(options.PageSize) - 1) / int64(options.PageSize))
+
+	return &SearchResults{
+		PaginatedResult: PaginatedResult[SearchHit]{
+			Data:       hits[start:end],
+			Total:      total,
+			Page:       options.Page,
+			PageSize:   options.PageSize,
+			TotalPages: totalPages,
+			HasNext:    options.Page < totalPages,
+			HasPrev:    options.Page > 1,
+		},
+		Facets: facets,
+	}, nil
+}
+
+// searchUsers finds users by name and email. Only users who may read other
+// users' accounts, such as admins and moderators, can search them.
+func (s *SearchService) searchUsers(q *search.Query, user *models.User, can accessCheck) ([]SearchHit, error) {
+	if user == nil || !can("read", authz.Kind("user")) {
+		return nil, nil
+	}
+
+	var users []models.User
+	err := q.Like(s.db.Model(&models.User{}), "username", "email", "first_name", "last_name").
+		Order("created_at DESC").
+		Limit(maxSearchCandidates).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []SearchHit{}
+	for _, u := range users {
+		details := strings.TrimSpace(u.FirstName+" "+u.LastName) + " <" + u.Email + ">"
+		hits = append(hits, SearchHit{
+			Type:  SearchTypeUser,
+			ID:    u.ID,
+			Score: searchScore(q, u.Username, details),
+			Title: u.Username,
+			Highlight: search.Highlight{
+				Title:   q.Mark(u.Username, 0),
+				Snippet: q.Mark(details, searchSnippetWidth),
+			},
+			CreatedAt: u.CreatedAt,
+			Data:      u,
+		})
+	}
+	return hits, nil
+}
+
+// searchPosts finds posts through the full-text index, keeping those the
+// user may read
+func (s *SearchService) searchPosts(q *search.Query, _ *models.User, can accessCheck) ([]SearchHit, error) {
+	var matches []search.Hit
+	err := s.searchIndex.Rank(s.searchIndex.Match(s.db.Model(&models.Post{}), q), q).
+		Limit(maxSearchCandidates).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	posts := make(map[uint]models.Post, len(matches))
+	if len(ids) > 0 {
+		var found []models.Post
+		if err := s.db.Preload("User").Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, post := range found {
+			posts[post.ID] = post
+		}
+	}
+
+	hits := []SearchHit{}
+	for _, match := range matches {
+		post, ok := posts[match.ID]
+		if !ok || !can("read", &post) {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Type:      SearchTypePost,
+			ID:        post.ID,
+			Score:     searchScore(q, post.Title, post.Content),
+			Title:     post.Title,
+			Highlight: match.Highlight(),
+			CreatedAt: post.CreatedAt,
+			Data:      post,
+		})
+	}
+	return hits, nil
+}
+
+// searchComments finds comments by content. Like a post's comment thread,
+// it shows approved comments and the user's own pending ones on posts the
+// user may read; comment moderators see comments of any status.
+func (s *SearchService) searchComments(q *search.Query, user *models.User, can accessCheck) ([]SearchHit, error) {
+	query := q.Like(s.db.Model(&models.Comment{}), "content")
+	switch {
+	case user == nil:
+		query = query.Where("status = ?", models.CommentStatusApproved)
+	case !can("moderate", authz.Kind("comment")):
+		query = query.Where("(status = ? OR (status = ? AND user_id = ?))",
+			models.CommentStatusApproved, models.CommentStatusPending, user.ID)
+	}
+
+	var comments []models.Comment
+	err := query.Preload("User").Preload("Post").
+		Order("created_at DESC").
+		Limit(maxSearchCandidates).
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[uint]bool)
+	hits := []SearchHit{}
+	for _, comment := range comments {
+		if comment.Post == nil {
+			continue
+		}
+		visible, checked := readable[comment.PostID]
+		if !checked {
+			visible = can("read", comment.Post)
+			readable[comment.PostID] = visible
+		}
+		if !visible {
+			continue
+		}
+
+		hits = append(hits, SearchHit{
+			Type:  SearchTypeComment,
+			ID:    comment.ID,
+			Score: searchScore(q, "", comment.Content),
+			Title: comment.Post.Title,
+			Highlight: search.Highlight{
+				Title:   q.Mark(comment.Post.Title, 0),
+				Snippet: q.Mark(comment.Content, searchSnippetWidth),
+			},
+			CreatedAt: comment.CreatedAt,
+			Data:      comment,
+		})
+	}
+	return hits, nil
+}
+
+// searchFiles finds files by name, category and type, keeping those the
+// user may read
+func (s *SearchService) searchFiles(q *search.Query, _ *models.User, can accessCheck) ([]SearchHit, error) {
+	var files []models.FileUpload
+	err := q.Like(s.db.Model(&models.FileUpload{}), "original_name", "category", "file_type", "mime_type").
+		Preload("User").
+		Order("created_at DESC").
+		Limit(maxSearchCandidates).
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []SearchHit{}
+	for _, file := range files {
+		if !can("read", &file) {
+			continue
+		}
+
+		var details []string
+		for _, detail := range []string{file.Category, file.FileType, file.MimeType} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		hits = append(hits, SearchHit{
+			Type:  SearchTypeFile,
+			ID:    file.ID,
+			Score: searchScore(q, file.OriginalName, strings.Join(details, " ")),
+			Title: file.OriginalName,
+			Highlight: search.Highlight{
+				Title:   q.Mark(file.OriginalName, 0),
+				Snippet: q.Mark(strings.Join(details, " · "), 0),
+			},
+			CreatedAt: file.CreatedAt,
+			Data:      file,
+		})
+	}
+	return hits, nil
+}
+
+// searchScore rates a hit by how often the query's words appear in it, with
+// title matches counting double. Every hit scores at least 1, as full-text
+// matches on word stems are not counted.
+func searchScore(q *search.Query, title, body string) float64 {
+	return float64(1 + 2*q.Count(title) + q.Count(body))
+}
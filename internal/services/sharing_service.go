@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// SharingService manages per-resource shares with users and groups and
+// share links for posts and files
+type SharingService struct {
+	db                *gorm.DB
+	auditService      *AuditService
+	permissionService *PermissionService
+}
+
+// SharedItem is a shared resource together with the share or link granting
+// access to it
+type SharedItem struct {
+	Share *models.ResourceShare `json:"share,omitempty"`
+	Link  *models.ShareLink     `json:"link,omitempty"`
+	Post  *models.Post          `json:"post,omitempty"`
+	File  *models.FileUpload    `json:"file,omitempty"`
+}
+
+// NewSharingService creates a new sharing service
+func NewSharingService(db *gorm.DB, auditService *AuditService, permissionService *PermissionService) *SharingService {
+	return &SharingService{
+		db:                db,
+		auditService:      auditService,
+		permissionService: permissionService,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *SharingService) WithContext(ctx context.Context) *SharingService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// ShareResource grants a user or group access to a post or file. Sharing the
+// same resource with the same grantee again replaces the previous grant.
+func (s *SharingService) ShareResource(actorID uint, req *models.ShareCreateRequest) (*models.ResourceShare, error) {
+	if err := s.authorizeShare(actorID, req.ResourceType, req.ResourceID); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	switch req.GranteeType {
+	case models.ShareGranteeUser:
+		if req.GranteeID == actorID {
+			return nil, errors.New("cannot share a resource with yourself")
+		}
+		var user models.User
+		if err := s.db.Select("id").First(&user, req.GranteeID).Error; err != nil {
+			return nil, errors.New("user not found")
+		}
+	case models.ShareGranteeGroup:
+		var group models.Group
+		if err := s.db.Select("id").First(&group, req.GranteeID).Error; err != nil {
+			return nil, errors.New("group not found")
+		}
+	}
+
+	share := &models.ResourceShare{
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		GranteeType:  req.GranteeType,
+		GranteeID:    req.GranteeID,
+	}
+	err := s.db.Where(share).FirstOrInit(share).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var oldValues interface{}
+	if share.ID != 0 {
+		oldValues = map[string]interface{}{
+			"permission": share.Permission,
+			"expires_at": share.ExpiresAt,
+		}
+	}
+
+	share.Permission = req.Permission
+	share.ExpiresAt = req.ExpiresAt
+	share.GrantedBy = actorID
+	if err := s.db.Save(share).Error; err != nil {
+		return nil, fmt.Errorf("failed to share resource: %w", err)
+	}
+
+	s.logChange(actorID, "resource_share", share.ID, oldValues, map[string]interface{}{
+		"resource_type": share.ResourceType,
+		"resource_id":   share.ResourceID,
+		"grantee_type":  share.GranteeType,
+		"grantee_id":    share.GranteeID,
+		"permission":    share.Permission,
+		"expires_at":    share.ExpiresAt,
+	})
+
+	return share, nil
+}
+
+// ListShares lists the shares and links of a resource the actor may share
+func (s *SharingService) ListShares(actorID uint, resourceType string, resourceID uint) ([]models.ResourceShare, []models.ShareLink, error) {
+	if err := s.authorizeShare(actorID, resourceType, resourceID); err != nil {
+		return nil, nil, err
+	}
+
+	var shares []models.ResourceShare
+	err := s.db.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Order("created_at ASC").
+		Find(&shares).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch shares: %w", err)
+	}
+
+	var links []models.ShareLink
+	err = s.db.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Order("created_at ASC").
+		Find(&links).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch share links: %w", err)
+	}
+
+	return shares, links, nil
+}
+
+// RevokeShare removes a share
+func (s *SharingService) RevokeShare(actorID, shareID uint) error {
+	var share models.ResourceShare
+	if err := s.db.First(&share, shareID).Error; err != nil {
+		return errors.New("share not found")
+	}
+
+	if err := s.authorizeShare(actorID, share.ResourceType, share.ResourceID); err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(&share).Error; err != nil {
+		return fmt.Errorf("failed to revoke share: %w", err)
+	}
+
+	s.logChange(actorID, "resource_share", share.ID, map[string]interface{}{
+		"resource_type": share.ResourceType,
+		"resource_id":   share.ResourceID,
+		"grantee_type":  share.GranteeType,
+		"grantee_id":    share.GranteeID,
+		"permission":    share.Permission,
+	}, nil)
+
+	return nil
+}
+
+// CreateLink creates a share link granting read access to anyone holding
+// its token
+func (s *SharingService) CreateLink(actorID uint, req *models.ShareLinkCreateRequest) (*models.ShareLink, error) {
+	if err := s.authorizeShare(actorID, req.ResourceType, req.ResourceID); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	link := &models.ShareLink{
+		Token:        token,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		CreatedBy:    actorID,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := s.db.Create(link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	s.logChange(actorID, "share_link", link.ID, nil, map[string]interface{}{
+		"resource_type": link.ResourceType,
+		"resource_id":   link.ResourceID,
+		"expires_at":    link.ExpiresAt,
+	})
+
+	return link, nil
+}
+
+// RevokeLink deletes a share link
+func (s *SharingService) RevokeLink(actorID, linkID uint) error {
+	var link models.ShareLink
+	if err := s.db.First(&link, linkID).Error; err != nil {
+		return errors.New("share link not found")
+	}
+
+	if err := s.authorizeShare(actorID, link.ResourceType, link.ResourceID); err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(&link).Error; err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	s.logChange(actorID, "share_link", link.ID, map[string]interface{}{
+		"resource_type": link.ResourceType,
+		"resource_id":   link.ResourceID,
+	}, nil)
+
+	return nil
+}
+
+// ResolveLink returns the resource a share link points to. Expired links are
+// treated as missing. The token grants access on its own, so without a tenant
+// in the context the resource is loaded outside any tenant scope; within a
+// tenant, links to another tenant's resources are treated as missing.
+func (s *SharingService) ResolveLink(token string) (*SharedItem, error) {
+	var link models.ShareLink
+	if err := s.db.Where("token = ?", token).First(&link).Error; err != nil || link.IsExpired() {
+		return nil, errors.New("share link not found")
+	}
+
+	resolver := s
+	if _, ok := tenant.FromContext(s.db.Statement.Context); !ok {
+		resolver = s.WithContext(tenant.WithoutScope(s.db.Statement.Context))
+	}
+	resource, err := resolver.loadResource(link.ResourceType, link.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &SharedItem{Link: &link}
+	switch r := resource.(type) {
+	case *models.Post:
+		item.Post = r
+	case *models.FileUpload:
+		item.File = r
+	}
+	return item, nil
+}
+
+// SharedWithUser lists the unexpired shares granted to the user directly or
+// through their groups, with the shared resources
+func (s *SharingService) SharedWithUser(userID uint) ([]SharedItem, error) {
+	query, err := s.granteeQuery(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []models.ResourceShare
+	err = query.Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&shares).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shares: %w", err)
+	}
+
+	var postIDs, fileIDs []uint
+	for _, share := range shares {
+		switch share.ResourceType {
+		case models.ShareResourcePost:
+			postIDs = append(postIDs, share.ResourceID)
+		case models.ShareResourceFile:
+			fileIDs = append(fileIDs, share.ResourceID)
+		}
+	}
+
+	posts := make(map[uint]*models.Post)
+	if len(postIDs) > 0 {
+		var rows []models.Post
+		if err := s.db.Preload("User").Where("id IN ?", postIDs).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch shared posts: %w", err)
+		}
+		for i := range rows {
+			posts[rows[i].ID] = &rows[i]
+		}
+	}
+
+	files := make(map[uint]*models.FileUpload)
+	if len(fileIDs) > 0 {
+		var rows []models.FileUpload
+		if err := s.db.Preload("User").Where("id IN ?", fileIDs).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch shared files: %w", err)
+		}
+		for i := range rows {
+			files[rows[i].ID] = &rows[i]
+		}
+	}
+
+	// A resource shared both directly and through a group is listed once.
+	// Deleted resources and resources outside the current tenant are skipped.
+	seen := make(map[string]bool)
+	items := make([]SharedItem, 0, len(shares))
+	for i := range shares {
+		share := &shares[i]
+		key := share.ResourceType + ":" + strconv.FormatUint(uint64(share.ResourceID), 10)
+		if seen[key] {
+			continue
+		}
+
+		item := SharedItem{Share: share}
+		switch share.ResourceType {
+		case models.ShareResourcePost:
+			item.Post = posts[share.ResourceID]
+		case models.ShareResourceFile:
+			item.File = files[share.ResourceID]
+		}
+		if item.Post == nil && item.File == nil {
+			continue
+		}
+
+		seen[key] = true
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Relation resolves the "relation" attributes used by the authorization
+// policy. relation.share is the highest permission any unexpired share grants
+// the subject on the resource.
+func (s *SharingService) Relation(ctx context.Context, subject *authz.Subject, resource authz.Resource) authz.Attributes {
+	resourceType := resource.AuthzType()
+	if resourceType != models.ShareResourcePost && resourceType != models.ShareResourceFile {
+		return nil
+	}
+	resourceID, ok := resource.AuthzAttributes()["id"].(uint)
+	if !ok || resourceID == 0 {
+		return nil
+	}
+
+	query, err := s.granteeQuery(subject.ID)
+	if err != nil {
+		return nil
+	}
+
+	var permissions []models.SharePermission
+	err = query.WithContext(ctx).Model(&models.ResourceShare{}).
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Pluck("permission", &permissions).Error
+	if err != nil || len(permissions) == 0 {
+		return nil
+	}
+
+	best := permissions[0]
+	for _, permission := range permissions[1:] {
+		if permission.Includes(best) {
+			best = permission
+		}
+	}
+	return authz.Attributes{"share": string(best)}
+}
+
+// granteeQuery selects the shares granted to the user or any of their groups
+func (s *SharingService) granteeQuery(userID uint) (*gorm.DB, error) {
+	groupIDs, err := s.permissionService.GroupIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grantee := s.db.Where("grantee_type = ? AND grantee_id = ?", models.ShareGranteeUser, userID)
+	if len(groupIDs) > 0 {
+		grantee = grantee.Or("grantee_type = ? AND grantee_id IN ?", models.ShareGranteeGroup, groupIDs)
+	}
+	return s.db.Where(grantee), nil
+}
+
+// authorizeShare checks the resource exists and the actor may share it
+func (s *SharingService) authorizeShare(actorID uint, resourceType string, resourceID uint) error {
+	resource, err := s.loadResource(resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorize(s.db, actorID, "share", resource); err != nil {
+		return fmt.Errorf("unauthorized to share this %s: %w", resourceType, err)
+	}
+	return nil
+}
+
+// loadResource loads a shareable post or file
+func (s *SharingService) loadResource(resourceType string, resourceID uint) (interface{}, error) {
+	switch resourceType {
+	case models.ShareResourcePost:
+		var post models.Post
+		if err := s.db.First(&post, resourceID).Error; err != nil {
+			return nil, errors.New("post not found")
+		}
+		return &post, nil
+	case models.ShareResourceFile:
+		var file models.FileUpload
+		if err := s.db.First(&file, resourceID).Error; err != nil {
+			return nil, errors.New("file not found")
+		}
+		return &file, nil
+	}
+	return nil, fmt.Errorf("unsupported resource type %q", resourceType)
+}
+
+// logChange records a sharing change in the audit trail
+func (s *SharingService) logChange(actorID uint, entityType string, entityID uint, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, ActionShareChange, AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
+
+// generateShareToken generates a random share link token
+func generateShareToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useShareRelations makes the authorization policy see the shares of the
+// sharing service for the rest of the test
+func useShareRelations(t *testing.T, sharing *SharingService) {
+	t.Helper()
+	authz.SetRelationResolver(sharing.Relation)
+	t.Cleanup(func() {
+		authz.SetRelationResolver(func(context.Context, *authz.Subject, authz.Resource) authz.Attributes { return nil })
+	})
+}
+
+func TestSharingPermissions(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	viewer := createTestUser(t, db, "viewer", models.RoleUser)
+	editor := createTestUser(t, db, "editor", models.RoleUser)
+	member := createTestUser(t, db, "member", models.RoleUser)
+	outsider := createTestUser(t, db, "outsider", models.RoleUser)
+	permissions := NewPermissionService(db, nil)
+	groups := NewGroupService(db, nil, permissions)
+	sharing := NewSharingService(db, nil, permissions)
+	useShareRelations(t, sharing)
+
+	post := &models.Post{Title: "Draft", Content: "content", Slug: "draft", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+	share := func(granteeType string, granteeID uint, permission models.SharePermission) *models.ResourceShare {
+		t.Helper()
+		created, err := sharing.ShareResource(author.ID, &models.ShareCreateRequest{
+			ResourceType: models.ShareResourcePost,
+			ResourceID:   post.ID,
+			GranteeType:  granteeType,
+			GranteeID:    granteeID,
+			Permission:   permission,
+		})
+		require.NoError(t, err)
+		return created
+	}
+
+	share(models.ShareGranteeUser, viewer.ID, models.SharePermissionViewer)
+	share(models.ShareGranteeUser, editor.ID, models.SharePermissionEditor)
+	group, err := groups.CreateGroup(author.ID, &models.GroupCreateRequest{Name: "reviewers"})
+	require.NoError(t, err)
+	require.NoError(t, groups.AddMember(author.ID, group.ID, member.ID))
+	share(models.ShareGranteeGroup, group.ID, models.SharePermissionViewer)
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		read   bool
+		update bool
+	}{
+		{"viewer", viewer, true, false},
+		{"editor", editor, true, true},
+		{"group member", member, true, false},
+		{"outsider", outsider, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.read, authorize(db, tt.user.ID, "read", post) == nil)
+			assert.Equal(t, tt.update, authorize(db, tt.user.ID, "update", post) == nil)
+			assert.Error(t, authorize(db, tt.user.ID, "delete", post))
+			assert.Error(t, authorize(db, tt.user.ID, "share", post))
+		})
+	}
+
+	// Sharing again replaces the grant, and the strongest grant wins
+	share(models.ShareGranteeUser, editor.ID, models.SharePermissionViewer)
+	assert.Error(t, authorize(db, editor.ID, "update", post))
+	share(models.ShareGranteeUser, member.ID, models.SharePermissionEditor)
+	assert.NoError(t, authorize(db, member.ID, "update", post))
+
+	// Only those who may share the resource can share it
+	_, err = sharing.ShareResource(viewer.ID, &models.ShareCreateRequest{
+		ResourceType: models.ShareResourcePost,
+		ResourceID:   post.ID,
+		GranteeType:  models.ShareGranteeUser,
+		GranteeID:    outsider.ID,
+		Permission:   models.SharePermissionViewer,
+	})
+	var denied *authz.DeniedError
+	assert.ErrorAs(t, err, &denied)
+
+	items, err := sharing.SharedWithUser(member.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1, "a resource shared directly and through a group is listed once")
+	assert.Equal(t, post.ID, items[0].Post.ID)
+}
+
+func TestSharingExpiryAndRevocation(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	reader := createTestUser(t, db, "reader", models.RoleUser)
+	sharing := NewSharingService(db, nil, NewPermissionService(db, nil))
+	useShareRelations(t, sharing)
+
+	post := &models.Post{Title: "Draft", Content: "content", Slug: "draft", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+
+	past := time.Now().Add(-time.Hour)
+	_, err := sharing.ShareResource(author.ID, &models.ShareCreateRequest{
+		ResourceType: models.ShareResourcePost,
+		ResourceID:   post.ID,
+		GranteeType:  models.ShareGranteeUser,
+		GranteeID:    reader.ID,
+		Permission:   models.SharePermissionViewer,
+		ExpiresAt:    &past,
+	})
+	assert.Error(t, err, "expiry must be in the future")
+
+	share, err := sharing.ShareResource(author.ID, &models.ShareCreateRequest{
+		ResourceType: models.ShareResourcePost,
+		ResourceID:   post.ID,
+		GranteeType:  models.ShareGranteeUser,
+		GranteeID:    reader.ID,
+		Permission:   models.SharePermissionViewer,
+	})
+	require.NoError(t, err)
+	require.NoError(t, authorize(db, reader.ID, "read", post))
+
+	// Expired shares grant nothing
+	require.NoError(t, db.Model(share).Update("expires_at", past).Error)
+	assert.Error(t, authorize(db, reader.ID, "read", post))
+	items, err := sharing.SharedWithUser(reader.ID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// Revoked shares grant nothing
+	require.NoError(t, db.Model(share).Update("expires_at", nil).Error)
+	require.NoError(t, authorize(db, reader.ID, "read", post))
+	assert.Error(t, sharing.RevokeShare(reader.ID, share.ID), "grantees cannot revoke shares")
+	require.NoError(t, sharing.RevokeShare(author.ID, share.ID))
+	assert.Error(t, authorize(db, reader.ID, "read", post))
+
+	// Links resolve until they expire or are revoked
+	link, err := sharing.CreateLink(author.ID, &models.ShareLinkCreateRequest{ResourceType: models.ShareResourcePost, ResourceID: post.ID})
+	require.NoError(t, err)
+	item, err := sharing.ResolveLink(link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, post.ID, item.Post.ID)
+
+	require.NoError(t, db.Model(link).Update("expires_at", past).Error)
+	_, err = sharing.ResolveLink(link.Token)
+	assert.Error(t, err)
+
+	require.NoError(t, db.Model(link).Update("expires_at", nil).Error)
+	require.NoError(t, sharing.RevokeLink(author.ID, link.ID))
+	_, err = sharing.ResolveLink(link.Token)
+	assert.Error(t, err)
+	_, err = sharing.ResolveLink("unknown")
+	assert.Error(t, err)
+}
+
+func TestSharingLinksStayInTheirTenant(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.Use(tenant.NewPlugin()))
+	author := createTestUser(t, db, "author", models.RoleUser)
+	sharing := NewSharingService(db, nil, NewPermissionService(db, nil))
+	acme := tenant.WithTenant(context.Background(), 1)
+	globex := tenant.WithTenant(context.Background(), 2)
+
+	post := &models.Post{Title: "Globex", Content: "content", Slug: "globex", UserID: author.ID}
+	require.NoError(t, db.WithContext(globex).Create(post).Error)
+
+	// Another tenant's resources cannot be shared
+	_, err := sharing.WithContext(acme).CreateLink(author.ID, &models.ShareLinkCreateRequest{ResourceType: models.ShareResourcePost, ResourceID: post.ID})
+	assert.Error(t, err)
+
+	link, err := sharing.WithContext(globex).CreateLink(author.ID, &models.ShareLinkCreateRequest{ResourceType: models.ShareResourcePost, ResourceID: post.ID})
+	require.NoError(t, err)
+
+	// Within a tenant, links only reach that tenant's resources
+	_, err = sharing.WithContext(acme).ResolveLink(link.Token)
+	assert.Error(t, err)
+	item, err := sharing.WithContext(globex).ResolveLink(link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, post.ID, item.Post.ID)
+
+	// Outside any tenant the token alone grants access
+	item, err = sharing.WithContext(context.Background()).ResolveLink(link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, post.ID, item.Post.ID)
+}
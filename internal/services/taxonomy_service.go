@@ -0,0 +1,539 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// TaxonomyService manages post tags and the category tree
+type TaxonomyService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewTaxonomyService creates a new taxonomy service
+func NewTaxonomyService(db *gorm.DB, auditService *AuditService) *TaxonomyService {
+	return &TaxonomyService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *TaxonomyService) WithContext(ctx context.Context) *TaxonomyService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// ListTags lists all tags with the number of published posts carrying each,
+// most used first
+func (s *TaxonomyService) ListTags() ([]models.Tag, error) {
+	var rows []struct {
+		models.Tag
+		Count int64 `gorm:"column:post_count"`
+	}
+	err := s.db.Model(&models.Tag{}).
+		Select("tags.*, COUNT(posts.id) AS post_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.status = ? AND posts.deleted_at IS NULL", models.PostStatusPublished).
+		Group("tags.id").
+		Order("post_count DESC, tags.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	tags := make([]models.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = row.Tag
+		tags[i].PostCount = &rows[i].Count
+	}
+	return tags, nil
+}
+
+// GetTag retrieves a tag by ID
+func (s *TaxonomyService) GetTag(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := s.db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &tag, nil
+}
+
+// CreateTag creates a new tag
+func (s *TaxonomyService) CreateTag(actorID uint, req *models.TagCreateRequest) (*models.Tag, error) {
+	name, slug, err := tagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if s.slugExists(&models.Tag{}, slug, 0) {
+		return nil, errors.New("tag already exists")
+	}
+
+	tag := &models.Tag{Name: name, Slug: slug}
+	if err := s.db.Create(tag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	s.logChange(actorID, ActionCreate, "tag", tag.ID, nil, map[string]interface{}{
+		"name": tag.Name,
+	})
+
+	return tag, nil
+}
+
+// RenameTag renames a tag. Renaming to the name of another tag fails; merge
+// the tags instead.
+func (s *TaxonomyService) RenameTag(actorID, id uint, req *models.TagUpdateRequest) (*models.Tag, error) {
+	tag, err := s.GetTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, slug, err := tagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if s.slugExists(&models.Tag{}, slug, id) {
+		return nil, errors.New("another tag already has this name, merge the tags instead")
+	}
+
+	updates := map[string]interface{}{"name": name, "slug": slug}
+	if err := s.db.Model(&models.Tag{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	s.logChange(actorID, ActionUpdate, "tag", id, map[string]interface{}{
+		"name": tag.Name,
+	}, updates)
+
+	return s.GetTag(id)
+}
+
+// DeleteTag deletes a tag and removes it from all posts
+func (s *TaxonomyService) DeleteTag(actorID, id uint) error {
+	tag, err := s.GetTag(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	s.logChange(actorID, ActionDelete, "tag", id, map[string]interface{}{
+		"name": tag.Name,
+	}, nil)
+
+	return nil
+}
+
+// MergeTags moves the posts of the source tags to the target tag and
+// deletes the source tags
+func (s *TaxonomyService) MergeTags(actorID uint, req *models.TagMergeRequest) (*models.Tag, error) {
+	target, err := s.GetTag(req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []models.Tag
+	if err := s.db.Where("id IN ? AND id <> ?", req.SourceIDs, req.TargetID).Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no tags to merge")
+	}
+
+	sourceIDs := make([]uint, len(sources))
+	sourceNames := make([]string, len(sources))
+	for i, source := range sources {
+		sourceIDs[i] = source.ID
+		sourceNames[i] = source.Name
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Tag posts with the target unless they already have it
+		err := tx.Exec(`INSERT INTO post_tags (post_id, tag_id)
+			SELECT DISTINCT post_id, ? FROM post_tags
+			WHERE tag_id IN ? AND post_id NOT IN (SELECT post_id FROM post_tags WHERE tag_id = ?)`,
+			target.ID, sourceIDs, target.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id IN ?", sourceIDs).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", sourceIDs).Delete(&models.Tag{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge tags: %w", err)
+	}
+
+	s.logChange(actorID, ActionUpdate, "tag", target.ID, map[string]interface{}{
+		"merged_ids":   sourceIDs,
+		"merged_names": sourceNames,
+	}, map[string]interface{}{
+		"name": target.Name,
+	})
+
+	return target, nil
+}
+
+// SetPostTags replaces the tags of a post the user may edit, creating tags
+// that do not exist yet
+func (s *TaxonomyService) SetPostTags(postID, userID uint, names []string) ([]models.Tag, error) {
+	post, err := s.authorizePost(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, slugs, err := replacePostTags(s.db, post, names)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logChange(userID, ActionUpdate, "post", postID, nil, map[string]interface{}{
+		"tags": slugs,
+	})
+
+	return tags, nil
+}
+
+// replacePostTags replaces the tags of a post with the named ones, creating
+// tags that do not exist yet, and returns them with their slugs
+func replacePostTags(db *gorm.DB, post *models.Post, names []string) ([]models.Tag, []string, error) {
+	// Normalize the names, dropping duplicates
+	bySlug := make(map[string]string)
+	var slugs []string
+	for _, raw := range names {
+		name, slug, err := tagName(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := bySlug[slug]; !ok {
+			bySlug[slug] = name
+			slugs = append(slugs, slug)
+		}
+	}
+
+	var tags []models.Tag
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(slugs) > 0 {
+			if err := tx.Where("slug IN ?", slugs).Find(&tags).Error; err != nil {
+				return err
+			}
+		}
+
+		existing := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			existing[tag.Slug] = true
+		}
+		for _, slug := range slugs {
+			if existing[slug] {
+				continue
+			}
+			tag := models.Tag{Name: bySlug[slug], Slug: slug}
+			if err := tx.Create(&tag).Error; err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+
+		return tx.Model(post).Association("Tags").Replace(tags)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set post tags: %w", err)
+	}
+	return tags, slugs, nil
+}
+
+// GetCategoryTree returns all categories nested under their parents
+func (s *TaxonomyService) GetCategoryTree() ([]models.Category, error) {
+	var categories []models.Category
+	if err := s.db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		var parentID uint
+		if category.ParentID != nil {
+			parentID = *category.ParentID
+		}
+		children[parentID] = append(children[parentID], category)
+	}
+
+	var attach func(parentID uint) []models.Category
+	attach = func(parentID uint) []models.Category {
+		nodes := children[parentID]
+		for i := range nodes {
+			nodes[i].Children = attach(nodes[i].ID)
+		}
+		return nodes
+	}
+
+	tree := attach(0)
+	if tree == nil {
+		tree = []models.Category{}
+	}
+	return tree, nil
+}
+
+// GetCategory retrieves a category by ID
+func (s *TaxonomyService) GetCategory(id uint) (*models.Category, error) {
+	var category models.Category
+	if err := s.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &category, nil
+}
+
+// CreateCategory creates a new category, optionally under a parent category
+func (s *TaxonomyService) CreateCategory(actorID uint, req *models.CategoryCreateRequest) (*models.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return nil, errors.New("category name must contain letters or digits")
+	}
+	if s.slugExists(&models.Category{}, slug, 0) {
+		return nil, errors.New("category already exists")
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.GetCategory(*req.ParentID); err != nil {
+			return nil, fmt.Errorf("parent category: %w", err)
+		}
+	}
+
+	category := &models.Category{
+		Name:        name,
+		Slug:        slug,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := s.db.Create(category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	s.logChange(actorID, ActionCreate, "category", category.ID, nil, map[string]interface{}{
+		"name":      category.Name,
+		"parent_id": category.ParentID,
+	})
+
+	return category, nil
+}
+
+// UpdateCategory updates a category's details or moves it under another parent
+func (s *TaxonomyService) UpdateCategory(actorID, id uint, req *models.CategoryUpdateRequest) (*models.Category, error) {
+	category, err := s.GetCategory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, errors.New("category name must contain letters or digits")
+		}
+		if s.slugExists(&models.Category{}, slug, id) {
+			return nil, errors.New("category already exists")
+		}
+		updates["name"] = name
+		updates["slug"] = slug
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if _, err := s.GetCategory(*req.ParentID); err != nil {
+				return nil, fmt.Errorf("parent category: %w", err)
+			}
+			descendants, err := s.categoryDescendants(id)
+			if err != nil {
+				return nil, err
+			}
+			for _, descendant := range descendants {
+				if descendant == *req.ParentID {
+					return nil, errors.New("category hierarchy cannot contain cycles")
+				}
+			}
+			updates["parent_id"] = *req.ParentID
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update category: %w", err)
+		}
+
+		s.logChange(actorID, ActionUpdate, "category", id, map[string]interface{}{
+			"name":      category.Name,
+			"parent_id": category.ParentID,
+		}, updates)
+	}
+
+	return s.GetCategory(id)
+}
+
+// DeleteCategory deletes a category and removes it from all posts. Child
+// categories are moved up to the deleted category's parent.
+func (s *TaxonomyService) DeleteCategory(actorID, id uint) error {
+	category, err := s.GetCategory(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_categories WHERE category_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	s.logChange(actorID, ActionDelete, "category", id, map[string]interface{}{
+		"name": category.Name,
+	}, nil)
+
+	return nil
+}
+
+// SetPostCategories replaces the categories of a post the user may edit
+func (s *TaxonomyService) SetPostCategories(postID, userID uint, categoryIDs []uint) ([]models.Category, error) {
+	post, err := s.authorizePost(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []models.Category{}
+	if len(categoryIDs) > 0 {
+		if err := s.db.Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+	}
+	found := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		found[category.ID] = true
+	}
+	for _, id := range categoryIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("category %d not found", id)
+		}
+	}
+
+	if err := s.db.Model(post).Association("Categories").Replace(categories); err != nil {
+		return nil, fmt.Errorf("failed to set post categories: %w", err)
+	}
+
+	s.logChange(userID, ActionUpdate, "post", postID, nil, map[string]interface{}{
+		"category_ids": categoryIDs,
+	})
+
+	return categories, nil
+}
+
+// categoryDescendants returns the ID of a category and every category nested
+// anywhere below it
+func (s *TaxonomyService) categoryDescendants(id uint) ([]uint, error) {
+	var categories []models.Category
+	if err := s.db.Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// authorizePost loads a post and checks the user may edit it
+func (s *TaxonomyService) authorizePost(postID, userID uint) (*models.Post, error) {
+	var post models.Post
+	if err := s.db.First(&post, postID).Error; err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "update", &post); err != nil {
+		return nil, fmt.Errorf("unauthorized to edit this post: %w", err)
+	}
+	return &post, nil
+}
+
+// slugExists checks if a tag or category other than excludeID has the slug
+func (s *TaxonomyService) slugExists(model interface{}, slug string, excludeID uint) bool {
+	var count int64
+	s.db.Model(model).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count)
+	return count > 0
+}
+
+// logChange records a tag or category change in the audit trail
+func (s *TaxonomyService) logChange(actorID uint, action AuditAction, entityType string, entityID uint, oldValues, newValues interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	s.auditService.LogEvent(actorID, action, AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		OldValues:  oldValues,
+		NewValues:  newValues,
+	})
+}
+
+// tagName normalizes a tag name and returns it with its slug
+func tagName(raw string) (string, string, error) {
+	name := strings.Join(strings.Fields(raw), " ")
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return "", "", fmt.Errorf("tag %q must contain letters or digits", raw)
+	}
+	return name, slug, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+)
+
+// TrashPurger purges items that have been in the trash longer than the
+// retention period
+type TrashPurger struct {
+	trashService *TrashService
+	interval     time.Duration
+	logger       *logger.Logger
+}
+
+// NewTrashPurger creates a new trash purger
+func NewTrashPurger(trashService *TrashService, interval time.Duration, logger *logger.Logger) *TrashPurger {
+	return &TrashPurger{
+		trashService: trashService,
+		interval:     interval,
+		logger:       logger,
+	}
+}
+
+// Run purges expired items every interval until ctx is done
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purgeExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpired purges the expired items of every tenant
+func (p *TrashPurger) purgeExpired(ctx context.Context) {
+	count, err := p.trashService.WithContext(tenant.WithoutScope(ctx)).PurgeExpired()
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to purge expired trash")
+	}
+
+	if count > 0 {
+		p.logger.WithField("count", count).Info("Expired trash purged")
+	}
+}
@@ -0,0 +1,643 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrParentInTrash is returned when restoring a comment whose post or
+	// parent comment is still in the trash
+	ErrParentInTrash = errors.New("restore the post or comment this comment belongs to first")
+	// ErrRestoreConflict is returned when restoring a user whose email or
+	// username has since been taken by another user
+	ErrRestoreConflict = errors.New("another user has the same email or username")
+)
+
+// TrashService lists, restores and purges deleted posts, comments, files and
+// users. Users see and manage the items they deleted themselves; moderators
+// and admins can restore anyone's, including what they removed from authors.
+// Items are purged for good once they have been in the trash longer than the
+// retention period, and purging a file removes it from disk.
+type TrashService struct {
+	db           *gorm.DB
+	postService  *PostService
+	auditService *AuditService
+	config       config.TrashConfig
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(db *gorm.DB, postService *PostService, auditService *AuditService, cfg config.TrashConfig) *TrashService {
+	return &TrashService{
+		db:           db,
+		postService:  postService,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *TrashService) WithContext(ctx context.Context) *TrashService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	if s.postService != nil {
+		clone.postService = s.postService.WithContext(ctx)
+	}
+	if s.auditService != nil {
+		clone.auditService = s.auditService.WithContext(ctx)
+	}
+	return &clone
+}
+
+// Posts pages through the deleted posts the user can restore, most recently
+// deleted first
+func (s *TrashService) Posts(userID uint, options QueryOptions) (*PaginatedResult[models.TrashedItem[models.Post]], error) {
+	query := s.trash(&models.Post{}, userID, "post")
+	return pageTrash(query, options, s.config.Retention, func(post *models.Post) (models.Post, gorm.DeletedAt) {
+		return *post, post.DeletedAt
+	}, "User")
+}
+
+// Comments pages through the deleted comments the user can restore, most
+// recently deleted first
+func (s *TrashService) Comments(userID uint, options QueryOptions) (*PaginatedResult[models.TrashedItem[models.Comment]], error) {
+	query := s.trash(&models.Comment{}, userID, "comment")
+	return pageTrash(query, options, s.config.Retention, func(comment *models.Comment) (models.Comment, gorm.DeletedAt) {
+		return *comment, comment.DeletedAt
+	}, "User")
+}
+
+// Files pages through the deleted files the user can restore, most recently
+// deleted first
+func (s *TrashService) Files(userID uint, options QueryOptions) (*PaginatedResult[models.TrashedItem[models.FileUpload]], error) {
+	query := s.trash(&models.FileUpload{}, userID, "file")
+	return pageTrash(query, options, s.config.Retention, func(file *models.FileUpload) (models.FileUpload, gorm.DeletedAt) {
+		return *file, file.DeletedAt
+	})
+}
+
+// Users pages through the deleted users, most recently deleted first
+func (s *TrashService) Users(adminID uint, options QueryOptions) (*PaginatedResult[models.TrashedItem[models.UserResponse]], error) {
+	if err := authorize(s.db, adminID, "restore", authz.Kind("user")); err != nil {
+		return nil, fmt.Errorf("unauthorized to view deleted users: %w", err)
+	}
+
+	query := s.db.Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	return pageTrash(query, options, s.config.Retention, func(user *models.User) (models.UserResponse, gorm.DeletedAt) {
+		return user.ToResponse(), user.DeletedAt
+	})
+}
+
+// RestorePost takes a post out of the trash. The post gets a new slug if its
+// own has since been taken.
+func (s *TrashService) RestorePost(userID, postID uint) (*models.Post, error) {
+	var post models.Post
+	if err := s.trashed(&post, postID); err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "restore", &post); err != nil {
+		return nil, fmt.Errorf("unauthorized to restore this post: %w", err)
+	}
+
+	updates := map[string]interface{}{"deleted_at": nil}
+	taken, err := s.postService.slugTaken(post.Slug, post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check slug: %w", err)
+	}
+	if taken {
+		slug, err := s.postService.uniqueSlug(post.Title, post.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate slug: %w", err)
+		}
+		updates["slug"] = slug
+	}
+	changes := map[string]interface{}{"deleted_by": nil, "version": gorm.Expr("version + 1")}
+	for column, value := range updates {
+		changes[column] = value
+	}
+	if err := s.db.Unscoped().Model(&post).Updates(changes).Error; err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, ActionUpdate, "post", postID, updates)
+	return s.postService.GetByID(postID, "User")
+}
+
+// RestoreComment takes a comment out of the trash along with the replies
+// deleted with it. The comment's post and parent comment must be restored
+// first.
+func (s *TrashService) RestoreComment(userID, commentID uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.trashed(&comment, commentID); err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "restore", &comment); err != nil {
+		return nil, fmt.Errorf("unauthorized to restore this comment: %w", err)
+	}
+
+	if err := s.db.First(&models.Post{}, comment.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrParentInTrash
+		}
+		return nil, err
+	}
+	if comment.ParentID != nil {
+		if err := s.db.First(&models.Comment{}, *comment.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrParentInTrash
+			}
+			return nil, err
+		}
+	}
+
+	// Replies deleted along with the comment were deleted at the same time
+	ids := []uint{comment.ID}
+	parents := ids
+	for len(parents) > 0 {
+		var children []uint
+		err := s.db.Unscoped().Model(&models.Comment{}).
+			Where("parent_id IN ? AND deleted_at = ?", parents, comment.DeletedAt).
+			Pluck("id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+	if err := s.db.Unscoped().Model(&models.Comment{}).Where("id IN ?", ids).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, ActionUpdate, "comment", commentID, map[string]interface{}{
+		"deleted_at":   nil,
+		"restored_ids": ids,
+	})
+
+	var restored models.Comment
+	if err := s.db.Preload("User").First(&restored, commentID).Error; err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// RestoreFile takes a file out of the trash. Its attachments were kept while it
+// was in the trash, so it reappears wherever it was attached.
+func (s *TrashService) RestoreFile(userID, fileID uint) (*models.FileUpload, error) {
+	var file models.FileUpload
+	if err := s.trashed(&file, fileID); err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, userID, "restore", &file); err != nil {
+		return nil, fmt.Errorf("unauthorized to restore this file: %w", err)
+	}
+
+	if err := s.db.Unscoped().Model(&file).Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil}).Error; err != nil {
+		return nil, err
+	}
+
+	s.audit(userID, ActionUpdate, "file_upload", fileID, map[string]interface{}{"deleted_at": nil})
+	return &file, nil
+}
+
+// RestoreUser takes a user out of the trash, unless another user has since
+// taken their email or username
+func (s *TrashService) RestoreUser(adminID, userID uint) (*models.User, error) {
+	var user models.User
+	if err := s.trashed(&user, userID); err != nil {
+		return nil, err
+	}
+	if err := authorize(s.db, adminID, "restore", &user); err != nil {
+		return nil, fmt.Errorf("unauthorized to restore this user: %w", err)
+	}
+
+	var count int64
+	err := s.db.Model(&models.User{}).
+		Where("(email = ? OR username = ?) AND id <> ?", user.Email, user.Username, user.ID).
+		Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrRestoreConflict
+	}
+
+	err = s.db.Unscoped().Model(&user).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(adminID, ActionUpdate, "user", userID, map[string]interface{}{"deleted_at": nil})
+
+	var restored models.User
+	if err := s.db.First(&restored, userID).Error; err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// PurgePost permanently deletes a post in the trash with its comments,
+// attachments and everything else recorded about it
+func (s *TrashService) PurgePost(userID, postID uint) error {
+	var post models.Post
+	if err := s.trashed(&post, postID); err != nil {
+		return err
+	}
+	if err := authorize(s.db, userID, "purge", &post); err != nil {
+		return fmt.Errorf("unauthorized to purge this post: %w", err)
+	}
+
+	if err := s.purge(s.db, purgePosts, []uint{postID}); err != nil {
+		return err
+	}
+
+	s.audit(userID, ActionDelete, "post", postID, map[string]interface{}{"purged": true})
+	return nil
+}
+
+// PurgeComment permanently deletes a comment in the trash with its replies
+// and attachments
+func (s *TrashService) PurgeComment(userID, commentID uint) error {
+	var comment models.Comment
+	if err := s.trashed(&comment, commentID); err != nil {
+		return err
+	}
+	if err := authorize(s.db, userID, "purge", &comment); err != nil {
+		return fmt.Errorf("unauthorized to purge this comment: %w", err)
+	}
+
+	if err := s.purge(s.db, purgeComments, []uint{commentID}); err != nil {
+		return err
+	}
+
+	s.audit(userID, ActionDelete, "comment", commentID, map[string]interface{}{"purged": true})
+	return nil
+}
+
+// PurgeFile permanently deletes a file in the trash, removing it from disk and
+// from the posts and comments it is attached to
+func (s *TrashService) PurgeFile(userID, fileID uint) error {
+	var file models.FileUpload
+	if err := s.trashed(&file, fileID); err != nil {
+		return err
+	}
+	if err := authorize(s.db, userID, "purge", &file); err != nil {
+		return fmt.Errorf("unauthorized to purge this file: %w", err)
+	}
+
+	if err := s.purge(s.db, purgeFiles, []uint{fileID}); err != nil {
+		return err
+	}
+
+	s.audit(userID, ActionDelete, "file_upload", fileID, map[string]interface{}{"purged": true})
+	return nil
+}
+
+// PurgeUser permanently deletes a user in the trash with everything they
+// posted and uploaded in every tenant
+func (s *TrashService) PurgeUser(adminID, userID uint) error {
+	var user models.User
+	if err := s.trashed(&user, userID); err != nil {
+		return err
+	}
+	if err := authorize(s.db, adminID, "purge", &user); err != nil {
+		return fmt.Errorf("unauthorized to purge this user: %w", err)
+	}
+
+	db := s.db.WithContext(tenant.WithoutScope(s.db.Statement.Context))
+	if err := s.purge(db, purgeUsers, []uint{userID}); err != nil {
+		return err
+	}
+
+	s.audit(adminID, ActionDelete, "user", userID, map[string]interface{}{
+		"email":    user.Email,
+		"username": user.Username,
+		"purged":   true,
+	})
+	return nil
+}
+
+// PurgeExpired purges everything that has been in the trash longer than the
+// retention period and returns how many items were purged. Users are purged
+// first, taking their content with them.
+func (s *TrashService) PurgeExpired() (int, error) {
+	if s.config.Retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-s.config.Retention)
+
+	kinds := []struct {
+		model interface{}
+		purge purgeFunc
+	}{
+		{&models.User{}, purgeUsers},
+		{&models.Post{}, purgePosts},
+		{&models.Comment{}, purgeComments},
+		{&models.FileUpload{}, purgeFiles},
+	}
+
+	purged := 0
+	for _, kind := range kinds {
+		var ids []uint
+		err := s.db.Unscoped().Model(kind.model).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := s.purge(s.db, kind.purge, ids); err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+	}
+	return purged, nil
+}
+
+// trash returns a query for the deleted rows of model the user can restore:
+// everyone's for moderators and admins, otherwise the user's own that they
+// deleted themselves
+func (s *TrashService) trash(model interface{}, userID uint, kind string) *gorm.DB {
+	query := s.db.Unscoped().Model(model).Where("deleted_at IS NOT NULL")
+	if authorize(s.db, userID, "restore", authz.Kind(kind)) != nil {
+		query = query.Where("user_id = ? AND deleted_by = ?", userID, userID)
+	}
+	return query
+}
+
+// recordDeletion records who moved rows of model to the trash
+func recordDeletion(tx *gorm.DB, model interface{}, ids []uint, userID uint) error {
+	return tx.Unscoped().Model(model).Where("id IN ?", ids).UpdateColumn("deleted_by", userID).Error
+}
+
+// trashed loads a row that is in the trash
+func (s *TrashService) trashed(dest interface{}, id uint) error {
+	return s.db.Unscoped().Where("deleted_at IS NOT NULL").First(dest, id).Error
+}
+
+// purge runs a purge in a transaction, then removes the purged files from
+// disk
+func (s *TrashService) purge(db *gorm.DB, purge purgeFunc, ids []uint) error {
+	var files []models.FileUpload
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		files, err = purge(tx.Unscoped().Session(&gorm.Session{}), ids)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := removeFileBytes(files); err != nil {
+		return fmt.Errorf("failed to remove purged files: %w", err)
+	}
+	return nil
+}
+
+// audit logs a restore or purge in the audit trail
+func (s *TrashService) audit(userID uint, action AuditAction, entityType string, entityID uint, values map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	auditData := AuditEventData{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		NewValues:  values,
+	}
+	s.auditService.LogEvent(userID, action, auditData)
+}
+
+// pageTrash pages through the deleted rows the query matches, most recently
+// deleted first, converting each with item
+func pageTrash[T, R any](query *gorm.DB, options QueryOptions, retention time.Duration, item func(*T) (R, gorm.DeletedAt), preload ...string) (*PaginatedResult[models.TrashedItem[R]], error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []T
+	find := query
+	for _, association := range preload {
+		find = find.Preload(association)
+	}
+	err := find.Order("deleted_at DESC").
+		Order("id DESC").
+		Offset((options.Pagination.Page - 1) * options.Pagination.PageSize).
+		Limit(options.Pagination.PageSize).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.TrashedItem[R], 0, len(rows))
+	for i := range rows {
+		value, deletedAt := item(&rows[i])
+		trashed := models.TrashedItem[R]{
+			Item:      value,
+			DeletedAt: deletedAt.Time,
+		}
+		if retention > 0 {
+			purgeAt := deletedAt.Time.Add(retention)
+			trashed.PurgeAt = &purgeAt
+		}
+		items = append(items, trashed)
+	}
+
+	totalPages := int((total + int64(options.Pagination.PageSize) - 1) / int64(options.Pagination.PageSize))
+	return &PaginatedResult[models.TrashedItem[R]]{
+		Data:       items,
+		Total:      total,
+		Page:       options.Pagination.Page,
+		PageSize:   options.Pagination.PageSize,
+		TotalPages: totalPages,
+		HasNext:    options.Pagination.Page < totalPages,
+		HasPrev:    options.Pagination.Page > 1,
+	}, nil
+}
+
+// purgeFunc permanently deletes rows by ID in a transaction that ignores the
+// trash, and returns the files whose bytes must be removed once it commits
+type purgeFunc func(tx *gorm.DB, ids []uint) ([]models.FileUpload, error)
+
+// purgeRows deletes the rows of a model matching a query
+type purgeRows struct {
+	model interface{}
+	query string
+	args  []interface{}
+}
+
+// deleteRows deletes the rows of each model matching its query
+func deleteRows(tx *gorm.DB, rows []purgeRows) error {
+	for _, r := range rows {
+		if err := tx.Where(r.query, r.args...).Delete(r.model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgePosts deletes posts with their comments, attachments, revisions,
+// reactions, views, mentions, shares and taxonomy links
+func purgePosts(tx *gorm.DB, postIDs []uint) ([]models.FileUpload, error) {
+	var commentIDs []uint
+	if err := tx.Model(&models.Comment{}).Where("post_id IN ?", postIDs).Pluck("id", &commentIDs).Error; err != nil {
+		return nil, err
+	}
+	var files []models.FileUpload
+	if len(commentIDs) > 0 {
+		commentFiles, err := purgeComments(tx, commentIDs)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, commentFiles...)
+	}
+
+	postFiles, err := purgeAttachments(tx, "post_id IN ?", postIDs)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, postFiles...)
+
+	err = deleteRows(tx, []purgeRows{
+		{&models.Reaction{}, "target_type = ? AND target_id IN ?", []interface{}{models.ReactionTargetPost, postIDs}},
+		{&models.Mention{}, "post_id IN ?", []interface{}{postIDs}},
+		{&models.PostRevision{}, "post_id IN ?", []interface{}{postIDs}},
+		{&models.PostSlugRedirect{}, "post_id IN ?", []interface{}{postIDs}},
+		{&models.PostView{}, "post_id IN ?", []interface{}{postIDs}},
+		{&models.TimelineEntry{}, "post_id IN ?", []interface{}{postIDs}},
+		{&models.ResourceShare{}, "resource_type = ? AND resource_id IN ?", []interface{}{models.ShareResourcePost, postIDs}},
+		{&models.ShareLink{}, "resource_type = ? AND resource_id IN ?", []interface{}{models.ShareResourcePost, postIDs}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", postIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM post_categories WHERE post_id IN ?", postIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", postIDs).Delete(&models.Post{}).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// purgeComments deletes comments and all their replies with their
+// attachments, reactions and mentions
+func purgeComments(tx *gorm.DB, commentIDs []uint) ([]models.FileUpload, error) {
+	ids := commentIDs
+	parents := commentIDs
+	for len(parents) > 0 {
+		var children []uint
+		if err := tx.Model(&models.Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+
+	files, err := purgeAttachments(tx, "target_type = ? AND target_id IN ?", models.AttachmentTargetComment, ids)
+	if err != nil {
+		return nil, err
+	}
+	err = deleteRows(tx, []purgeRows{
+		{&models.Reaction{}, "target_type = ? AND target_id IN ?", []interface{}{models.ReactionTargetComment, ids}},
+		{&models.Mention{}, "target_type = ? AND target_id IN ?", []interface{}{models.MentionTargetComment, ids}},
+		{&models.Comment{}, "id IN ?", []interface{}{ids}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// purgeFiles deletes files and their attachments and shares
+func purgeFiles(tx *gorm.DB, fileIDs []uint) ([]models.FileUpload, error) {
+	var files []models.FileUpload
+	if err := tx.Where("id IN ?", fileIDs).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	err := deleteRows(tx, []purgeRows{
+		{&models.Attachment{}, "file_id IN ?", []interface{}{fileIDs}},
+		{&models.ResourceShare{}, "resource_type = ? AND resource_id IN ?", []interface{}{models.ShareResourceFile, fileIDs}},
+		{&models.ShareLink{}, "resource_type = ? AND resource_id IN ?", []interface{}{models.ShareResourceFile, fileIDs}},
+		{&models.FileUpload{}, "id IN ?", []interface{}{fileIDs}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// purgeUsers deletes users with their posts, comments and files, and their
+// follows, blocks, reactions, mentions, notifications and memberships. The
+// audit trail and moderation history are kept.
+func purgeUsers(tx *gorm.DB, userIDs []uint) ([]models.FileUpload, error) {
+	var files []models.FileUpload
+	owned := []struct {
+		model interface{}
+		purge purgeFunc
+	}{
+		{&models.Post{}, purgePosts},
+		{&models.Comment{}, purgeComments},
+		{&models.FileUpload{}, purgeFiles},
+	}
+	for _, kind := range owned {
+		var ids []uint
+		if err := tx.Model(kind.model).Where("user_id IN ?", userIDs).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		purged, err := kind.purge(tx, ids)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, purged...)
+	}
+
+	err := deleteRows(tx, []purgeRows{
+		{&models.Reaction{}, "user_id IN ?", []interface{}{userIDs}},
+		{&models.Follow{}, "follower_id IN ? OR followee_id IN ?", []interface{}{userIDs, userIDs}},
+		{&models.Block{}, "blocker_id IN ? OR blocked_id IN ?", []interface{}{userIDs, userIDs}},
+		{&models.TimelineEntry{}, "user_id IN ? OR author_id IN ?", []interface{}{userIDs, userIDs}},
+		{&models.Mention{}, "user_id IN ? OR author_id IN ?", []interface{}{userIDs, userIDs}},
+		{&models.Notification{}, "user_id IN ?", []interface{}{userIDs}},
+		{&models.OrganizationMember{}, "user_id IN ?", []interface{}{userIDs}},
+		{&models.GroupMember{}, "user_id IN ?", []interface{}{userIDs}},
+		{&models.ResourceShare{}, "grantee_type = ? AND grantee_id IN ?", []interface{}{models.ShareGranteeUser, userIDs}},
+		{&models.User{}, "id IN ?", []interface{}{userIDs}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// removeFileBytes removes purged files from disk
+func removeFileBytes(files []models.FileUpload) error {
+	var errs []error
+	for _, file := range files {
+		if err := os.Remove(file.FilePath); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
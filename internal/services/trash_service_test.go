@@ -0,0 +1,201 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-backend/internal/authz"
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTrashOwnersRestoreOnlyTheirOwnDeletions(t *testing.T) {
+	db := newTestDB(t)
+	moderator := createTestUser(t, db, "moderator", models.RoleModerator)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	postService := NewPostService(db, nil, nil, nil, nil, nil)
+	commentService := NewCommentService(db, nil, nil, config.CommentsConfig{MaxDepth: 3})
+	trash := NewTrashService(db, postService, nil, config.TrashConfig{})
+
+	deleted := &models.Post{Title: "Deleted", Content: "content", Slug: "deleted", UserID: author.ID}
+	removed := &models.Post{Title: "Removed", Content: "content", Slug: "removed", UserID: author.ID}
+	require.NoError(t, db.Create(deleted).Error)
+	require.NoError(t, db.Create(removed).Error)
+	comment := &models.Comment{Content: "Spam", UserID: author.ID, PostID: deleted.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+
+	require.NoError(t, commentService.DeleteComment(comment.ID, moderator.ID))
+	require.NoError(t, postService.DeletePost(deleted.ID, author.ID))
+	require.NoError(t, postService.DeletePost(removed.ID, moderator.ID))
+
+	// The author only sees and restores what they deleted themselves
+	posts, err := trash.Posts(author.ID, QueryOptions{Pagination: PaginationOptions{Page: 1, PageSize: 10}})
+	require.NoError(t, err)
+	require.Len(t, posts.Data, 1)
+	assert.Equal(t, deleted.ID, posts.Data[0].Item.ID)
+
+	var denied *authz.DeniedError
+	_, err = trash.RestorePost(author.ID, removed.ID)
+	assert.ErrorAs(t, err, &denied)
+	restored, err := trash.RestorePost(author.ID, deleted.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedBy)
+
+	_, err = trash.RestoreComment(author.ID, comment.ID)
+	assert.ErrorAs(t, err, &denied)
+
+	// Staff restore what they removed
+	_, err = trash.RestorePost(moderator.ID, removed.ID)
+	require.NoError(t, err)
+	_, err = trash.RestoreComment(moderator.ID, comment.ID)
+	require.NoError(t, err)
+}
+
+func TestTrashListingScope(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	moderator := createTestUser(t, db, "moderator", models.RoleModerator)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	other := createTestUser(t, db, "other", models.RoleUser)
+	postService := NewPostService(db, nil, nil, nil, nil, nil)
+	fileService := NewFileService(db, FileUploadConfig{UploadPath: t.TempDir()}, nil)
+	trash := NewTrashService(db, postService, nil, config.TrashConfig{})
+	page := QueryOptions{Pagination: PaginationOptions{Page: 1, PageSize: 10}}
+
+	for _, user := range []*models.User{author, other} {
+		post := &models.Post{Title: user.Username, Content: "content", Slug: user.Username, UserID: user.ID}
+		require.NoError(t, db.Create(post).Error)
+		require.NoError(t, postService.DeletePost(post.ID, user.ID))
+		file := &models.FileUpload{UserID: user.ID, OriginalName: "a.txt", FileName: user.Username + ".txt", FilePath: user.Username + ".txt"}
+		require.NoError(t, db.Create(file).Error)
+		require.NoError(t, fileService.DeleteFile(file.ID, user.ID))
+	}
+	require.NoError(t, db.Create(&models.Post{Title: "Live", Content: "content", Slug: "live", UserID: author.ID}).Error)
+
+	tests := []struct {
+		name  string
+		user  *models.User
+		posts int
+		files int
+	}{
+		{"author sees their own", author, 1, 1},
+		{"moderator sees every post but only their own files", moderator, 2, 0},
+		{"admin sees everything", admin, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := trash.Posts(tt.user.ID, page)
+			require.NoError(t, err)
+			assert.Len(t, posts.Data, tt.posts)
+			assert.Equal(t, int64(tt.posts), posts.Total)
+			files, err := trash.Files(tt.user.ID, page)
+			require.NoError(t, err)
+			assert.Len(t, files.Data, tt.files)
+		})
+	}
+
+	var denied *authz.DeniedError
+	_, err := trash.Users(moderator.ID, page)
+	assert.ErrorAs(t, err, &denied)
+}
+
+func TestTrashRestoreConflicts(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	postService := NewPostService(db, nil, nil, nil, nil, nil)
+	commentService := NewCommentService(db, nil, nil, config.CommentsConfig{MaxDepth: 3})
+	trash := NewTrashService(db, postService, nil, config.TrashConfig{})
+
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+	comment := &models.Comment{Content: "First", UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+	reply := &models.Comment{Content: "Reply", UserID: author.ID, PostID: post.ID, ParentID: &comment.ID, Depth: 1, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(reply).Error)
+
+	require.NoError(t, commentService.DeleteComment(comment.ID, author.ID))
+	require.NoError(t, postService.DeletePost(post.ID, author.ID))
+
+	// Comments wait for their post
+	_, err := trash.RestoreComment(author.ID, comment.ID)
+	assert.ErrorIs(t, err, ErrParentInTrash)
+
+	// Another post took over the slug while this one was in the trash
+	renamed := &models.Post{Title: "Renamed", Content: "content", Slug: "renamed", UserID: author.ID}
+	require.NoError(t, db.Create(renamed).Error)
+	require.NoError(t, db.Create(&models.PostSlugRedirect{Slug: "hello", PostID: renamed.ID}).Error)
+	restoredPost, err := trash.RestorePost(author.ID, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-2", restoredPost.Slug)
+	assert.Equal(t, post.Version+1, restoredPost.Version)
+
+	// The reply deleted with the comment comes back with it
+	restoredComment, err := trash.RestoreComment(author.ID, comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, comment.Version+1, restoredComment.Version)
+	var restoredReply models.Comment
+	require.NoError(t, db.First(&restoredReply, reply.ID).Error)
+	assert.Equal(t, reply.Version+1, restoredReply.Version)
+
+	_, err = trash.RestoreComment(author.ID, comment.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "only trashed comments can be restored")
+}
+
+func TestTrashPurgeCascades(t *testing.T) {
+	db := newTestDB(t)
+	admin := createTestUser(t, db, "admin", models.RoleAdmin)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	reader := createTestUser(t, db, "reader", models.RoleUser)
+	postService := NewPostService(db, nil, nil, nil, nil, nil)
+	trash := NewTrashService(db, postService, nil, config.TrashConfig{})
+
+	// A post with a comment thread, an attached upload, a reaction and a
+	// revision
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+	comment := &models.Comment{Content: "First", UserID: reader.ID, PostID: post.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+	reply := &models.Comment{Content: "Reply", UserID: author.ID, PostID: post.ID, ParentID: &comment.ID, Depth: 1, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(reply).Error)
+	filePath := filepath.Join(t.TempDir(), "photo.jpg")
+	require.NoError(t, os.WriteFile(filePath, []byte("jpeg"), 0o600))
+	file := &models.FileUpload{UserID: author.ID, OriginalName: "photo.jpg", FileName: "photo.jpg", FilePath: filePath, Category: models.AttachmentCategory}
+	require.NoError(t, db.Create(file).Error)
+	require.NoError(t, db.Create(&models.Attachment{FileID: file.ID, TargetType: models.AttachmentTargetComment, TargetID: reply.ID, PostID: post.ID, UserID: author.ID}).Error)
+	require.NoError(t, db.Create(&models.Reaction{UserID: reader.ID, TargetType: models.ReactionTargetPost, TargetID: post.ID, Emoji: "👍"}).Error)
+	require.NoError(t, db.Create(&models.Reaction{UserID: author.ID, TargetType: models.ReactionTargetComment, TargetID: comment.ID, Emoji: "👍"}).Error)
+	require.NoError(t, db.Create(&models.PostRevision{PostID: post.ID, Number: 1, Title: "Hello", EditorID: author.ID}).Error)
+
+	// Only trashed posts can be purged
+	assert.ErrorIs(t, trash.PurgePost(author.ID, post.ID), gorm.ErrRecordNotFound)
+	require.NoError(t, postService.DeletePost(post.ID, author.ID))
+	require.NoError(t, trash.PurgePost(author.ID, post.ID))
+
+	for _, model := range []interface{}{&models.Post{}, &models.Comment{}, &models.FileUpload{}, &models.Attachment{}, &models.Reaction{}, &models.PostRevision{}} {
+		var count int64
+		require.NoError(t, db.Unscoped().Model(model).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+	_, err := os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err), "purged uploads are removed from disk")
+
+	// Purging a user takes everything they posted with them
+	other := &models.Post{Title: "Other", Content: "content", Slug: "other", UserID: reader.ID}
+	require.NoError(t, db.Create(other).Error)
+	require.NoError(t, db.Create(&models.Comment{Content: "Mine", UserID: reader.ID, PostID: other.ID, Status: models.CommentStatusApproved}).Error)
+	require.NoError(t, NewUserService(db, nil).DeleteUser(reader.ID))
+	require.NoError(t, trash.PurgeUser(admin.ID, reader.ID))
+
+	for _, model := range []interface{}{&models.Post{}, &models.Comment{}} {
+		var count int64
+		require.NoError(t, db.Unscoped().Model(model).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+	assert.ErrorIs(t, db.Unscoped().First(&models.User{}, reader.ID).Error, gorm.ErrRecordNotFound)
+	require.NoError(t, db.First(&models.User{}, author.ID).Error)
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go-backend/internal/models"
 	"go-backend/internal/utils"
@@ -10,6 +12,17 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrAccountNotFound is returned when a token's account no longer exists
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountDeactivated is returned when a deactivated account signs in
+	// or uses a token issued before it was deactivated
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	// ErrAccountSuspended is returned when a suspended account signs in or
+	// uses a token issued before it was suspended
+	ErrAccountSuspended = errors.New("account is suspended")
+)
+
 // UserService handles user-related business logic
 type UserService struct {
 	db         *gorm.DB
@@ -24,6 +37,14 @@ func NewUserService(db *gorm.DB, jwtService *utils.JWTService) *UserService {
 	}
 }
 
+// WithContext returns a copy of the service whose queries use ctx
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	return &UserService{
+		db:         s.db.WithContext(ctx),
+		jwtService: s.jwtService,
+	}
+}
+
 // Register creates a new user account
 func (s *UserService) Register(req *models.UserCreateRequest) (*models.LoginResponse, error) {
 	// Check if user already exists
@@ -79,8 +100,8 @@ func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 	}
 
 	// Check if user is active
-	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+	if err := accountActive(&user); err != nil {
+		return nil, err
 	}
 
 	// Verify password
@@ -112,6 +133,31 @@ func (s *UserService) GetUserByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// CheckActive checks that the user's account still exists and is neither
+// deactivated nor suspended
+func (s *UserService) CheckActive(id uint) error {
+	var user models.User
+	err := s.db.Select("id", "is_active", "suspended_until").First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountNotFound
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return accountActive(&user)
+}
+
+// accountActive returns why the user cannot use their account, if they cannot
+func accountActive(user *models.User) error {
+	if !user.IsActive {
+		return ErrAccountDeactivated
+	}
+	if user.IsSuspended() {
+		return fmt.Errorf("%w until %s", ErrAccountSuspended, user.SuspendedUntil.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
 // GetAllUsers retrieves all users with pagination
 func (s *UserService) GetAllUsers(page, limit int) ([]models.User, int64, error) {
 	var users []models.User
@@ -142,6 +188,9 @@ func (s *UserService) UpdateUser(id uint, req *models.UserUpdateRequest) (*model
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
+	if err := checkVersion(s.db, user.Version); err != nil {
+		return nil, err
+	}
 
 	// Update fields if provided
 	updates := make(map[string]interface{})
@@ -180,9 +229,18 @@ func (s *UserService) UpdateUser(id uint, req *models.UserUpdateRequest) (*model
 		updates["is_active"] = *req.IsActive
 	}
 
-	// Perform update
-	if err := s.db.Model(&user).Updates(updates).Error; err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	if req.MentionNotifications != nil {
+		updates["mention_notifications"] = *req.MentionNotifications
+	}
+
+	// Perform update, unless the user changed since the request fetched it
+	if len(updates) > 0 {
+		if err := updateVersioned(s.db, &user, id, updates); err != nil {
+			if errors.Is(err, ErrPreconditionFailed) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
 	}
 
 	// Fetch updated user
@@ -203,7 +261,13 @@ func (s *UserService) DeleteUser(id uint) error {
 		return fmt.Errorf("database error: %w", err)
 	}
 
-	if err := s.db.Delete(&user).Error; err != nil {
+	if err := checkVersion(s.db, user.Version); err != nil {
+		return err
+	}
+
+	// The user may have changed since they were loaded, so the version is
+	// checked again as they are deleted
+	if err := deleteVersioned(s.db, &models.User{}, user.ID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
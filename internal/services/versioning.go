@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"go-backend/internal/etag"
+
+	"gorm.io/gorm"
+)
+
+// ErrPreconditionFailed is returned when a request names, in If-Match, a
+// version the resource is no longer at
+var ErrPreconditionFailed = errors.New("resource has changed since it was fetched")
+
+// checkVersion checks a loaded resource is at a version the request expects,
+// if the request is conditional
+func checkVersion(db *gorm.DB, version uint) error {
+	expected, ok := etag.Expected(db.Statement.Context)
+	if ok && !slices.Contains(expected, version) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// updateVersioned updates the record with id and bumps its version. A
+// conditional request only updates the record at the versions it expects,
+// so a change made since it was fetched is not overwritten.
+func updateVersioned(db *gorm.DB, model interface{}, id interface{}, updates map[string]interface{}) error {
+	values := maps.Clone(updates)
+	values["version"] = gorm.Expr("version + 1")
+
+	query := db.Model(model).Where("id = ?", id)
+	expected, conditional := etag.Expected(db.Statement.Context)
+	if conditional {
+		query = query.Where("version IN ?", expected)
+	}
+
+	result := query.Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if conditional && result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// deleteVersioned soft deletes the record with id. A conditional request
+// only deletes the record at the versions it expects.
+func deleteVersioned(db *gorm.DB, model interface{}, id interface{}) error {
+	query := db.Where("id = ?", id)
+	expected, conditional := etag.Expected(db.Statement.Context)
+	if conditional {
+		query = query.Where("version IN ?", expected)
+	}
+
+	result := query.Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if conditional && result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
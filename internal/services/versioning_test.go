@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/etag"
+	"go-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// expecting returns a context for a request sending If-Match with versions
+func expecting(versions ...uint) context.Context {
+	return etag.WithExpected(context.Background(), versions)
+}
+
+func TestCheckVersion(t *testing.T) {
+	db := newTestDB(t)
+
+	assert.NoError(t, checkVersion(db, 3))
+	assert.NoError(t, checkVersion(db.WithContext(expecting(2, 3)), 3))
+	assert.ErrorIs(t, checkVersion(db.WithContext(expecting(2)), 3), ErrPreconditionFailed)
+	assert.ErrorIs(t, checkVersion(db.WithContext(expecting()), 3), ErrPreconditionFailed)
+}
+
+func TestDeleteVersioned(t *testing.T) {
+	db := newTestDB(t)
+	user := createTestUser(t, db, "author", models.RoleUser)
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: user.ID}
+	require.NoError(t, db.Create(post).Error)
+
+	// The post changed after the request fetched it at version 1
+	require.NoError(t, db.Model(post).Update("version", 2).Error)
+	err := deleteVersioned(db.WithContext(expecting(1)), &models.Post{}, post.ID)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	require.NoError(t, db.First(&models.Post{}, post.ID).Error)
+
+	require.NoError(t, deleteVersioned(db.WithContext(expecting(2)), &models.Post{}, post.ID))
+	assert.ErrorIs(t, db.First(&models.Post{}, post.ID).Error, gorm.ErrRecordNotFound)
+}
+
+func TestDeleteUserIsConditional(t *testing.T) {
+	db := newTestDB(t)
+	user := createTestUser(t, db, "target", models.RoleUser)
+	service := NewUserService(db, nil)
+
+	err := service.WithContext(expecting(user.Version + 1)).DeleteUser(user.ID)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	require.NoError(t, db.First(&models.User{}, user.ID).Error)
+
+	require.NoError(t, service.WithContext(expecting(user.Version)).DeleteUser(user.ID))
+	assert.ErrorIs(t, db.First(&models.User{}, user.ID).Error, gorm.ErrRecordNotFound)
+}
+
+func TestDeleteCommentIsConditional(t *testing.T) {
+	db := newTestDB(t)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: author.ID}
+	require.NoError(t, db.Create(post).Error)
+	comment := &models.Comment{Content: "First", UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+	reply := &models.Comment{Content: "Reply", UserID: author.ID, PostID: post.ID, ParentID: &comment.ID, Depth: 1, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(reply).Error)
+	service := NewCommentService(db, nil, nil, config.CommentsConfig{MaxDepth: 3})
+
+	err := service.WithContext(expecting(comment.Version+1)).DeleteComment(comment.ID, author.ID)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	var count int64
+	require.NoError(t, db.Model(&models.Comment{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	require.NoError(t, service.WithContext(expecting(comment.Version)).DeleteComment(comment.ID, author.ID))
+	require.NoError(t, db.Model(&models.Comment{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// The thread is deleted at one time so that it is restored together
+	var deleted []models.Comment
+	require.NoError(t, db.Unscoped().Order("id").Find(&deleted).Error)
+	require.Len(t, deleted, 2)
+	assert.True(t, deleted[0].DeletedAt.Time.Equal(deleted[1].DeletedAt.Time))
+}
+
+func TestApplyModerationBumpsVersion(t *testing.T) {
+	db := newTestDB(t)
+	moderator := createTestUser(t, db, "moderator", models.RoleModerator)
+	author := createTestUser(t, db, "author", models.RoleUser)
+	post := &models.Post{Title: "Hello", Content: "content", Slug: "hello", UserID: author.ID, Status: models.PostStatusPublished, Published: true}
+	require.NoError(t, db.Create(post).Error)
+	comment := &models.Comment{Content: "First", UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(comment).Error)
+
+	now := time.Now()
+	until := now.Add(time.Hour)
+	for _, action := range []*models.ModerationAction{
+		{ModeratorID: moderator.ID, Action: models.ModerationHide, TargetType: models.ReportTargetPost, TargetID: post.ID, TargetUserID: author.ID},
+		{ModeratorID: moderator.ID, Action: models.ModerationHide, TargetType: models.ReportTargetComment, TargetID: comment.ID, TargetUserID: author.ID},
+		{ModeratorID: moderator.ID, Action: models.ModerationSuspend, TargetType: models.ReportTargetUser, TargetID: author.ID, TargetUserID: author.ID, ExpiresAt: &until},
+	} {
+		require.NoError(t, applyModeration(db, action, now))
+	}
+
+	var hiddenPost models.Post
+	require.NoError(t, db.First(&hiddenPost, post.ID).Error)
+	assert.Equal(t, models.PostStatusArchived, hiddenPost.Status)
+	assert.Equal(t, post.Version+1, hiddenPost.Version)
+
+	var hiddenComment models.Comment
+	require.NoError(t, db.First(&hiddenComment, comment.ID).Error)
+	assert.Equal(t, models.CommentStatusRejected, hiddenComment.Status)
+	assert.Equal(t, comment.Version+1, hiddenComment.Version)
+
+	var suspended models.User
+	require.NoError(t, db.First(&suspended, author.ID).Error)
+	assert.True(t, suspended.IsSuspended())
+	assert.Equal(t, author.Version+1, suspended.Version)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/tenant"
+	"go-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cache keys of buffered view counting
+const (
+	viewsSeenKey    = "views:seen"
+	viewsPendingKey = "views:pending"
+)
+
+// ViewService counts post views. Repeat views of a post by the same viewer
+// within the deduplication window count once. Views are written to the
+// database as they happen, or buffered in the cache and flushed periodically.
+type ViewService struct {
+	db     *gorm.DB
+	cache  *CacheService
+	config config.ViewsConfig
+}
+
+// NewViewService creates a new view service. Views are only buffered when
+// buffering is enabled and a cache is given.
+func NewViewService(db *gorm.DB, cache *CacheService, cfg config.ViewsConfig) *ViewService {
+	if !cfg.Buffered {
+		cache = nil
+	}
+	return &ViewService{
+		db:     db,
+		cache:  cache,
+		config: cfg,
+	}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ViewService) WithContext(ctx context.Context) *ViewService {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+// RecordView counts a view of a post unless the viewer already viewed it
+// within the deduplication window. The viewer key identifies a user or an
+// anonymous visitor.
+func (s *ViewService) RecordView(postID uint, viewerKey string) (bool, error) {
+	if s.cache != nil {
+		return s.bufferView(postID, viewerKey)
+	}
+
+	now := time.Now()
+	counted := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Insert the viewer's record, or refresh it if the window has passed.
+		// No row is affected for a repeat view within the window.
+		view := &models.PostView{PostID: postID, ViewerKey: viewerKey, LastViewedAt: now}
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "post_id"}, {Name: "viewer_key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"last_viewed_at": now}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Lt{Column: clause.Column{Table: "post_views", Name: "last_viewed_at"}, Value: now.Add(-s.config.DedupWindow)},
+			}},
+		}).Create(view)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		counted = true
+		return tx.Model(&models.Post{}).Where("id = ?", postID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record view: %w", err)
+	}
+	return counted, nil
+}
+
+// bufferView counts a view in the cache
+func (s *ViewService) bufferView(postID uint, viewerKey string) (bool, error) {
+	ctx := s.context()
+	id := strconv.FormatUint(uint64(postID), 10)
+
+	first, err := s.cache.SetNX(ctx, viewsSeenKey+":"+id+":"+viewerKey, true, s.config.DedupWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to record view: %w", err)
+	}
+	if !first {
+		return false, nil
+	}
+
+	if _, err := s.cache.HashIncrement(ctx, viewsPendingKey, id, 1); err != nil {
+		return false, fmt.Errorf("failed to record view: %w", err)
+	}
+	return true, nil
+}
+
+// Flush writes buffered view counts to the database and returns how many
+// views were written. Without buffering it drops view records that have
+// left the deduplication window instead.
+func (s *ViewService) Flush() (int64, error) {
+	if s.cache == nil {
+		result := s.db.Where("last_viewed_at < ?", time.Now().Add(-s.config.DedupWindow)).
+			Delete(&models.PostView{})
+		return 0, result.Error
+	}
+
+	ctx := s.context()
+	pending, err := s.cache.HashGetAll(ctx, viewsPendingKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read buffered views: %w", err)
+	}
+
+	var total int64
+	for field, value := range pending {
+		postID, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+
+		err = s.db.Model(&models.Post{}).Where("id = ?", postID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", count)).Error
+		if err != nil {
+			return total, fmt.Errorf("failed to write views of post %d: %w", postID, err)
+		}
+
+		// Subtract rather than delete, keeping views buffered meanwhile
+		if _, err := s.cache.HashIncrement(ctx, viewsPendingKey, field, -count); err != nil {
+			return total, fmt.Errorf("failed to clear buffered views: %w", err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// context returns the context of the service's queries
+func (s *ViewService) context() context.Context {
+	if ctx := s.db.Statement.Context; ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// ViewFlusher periodically flushes buffered post views, or prunes view
+// records when views are not buffered
+type ViewFlusher struct {
+	viewService *ViewService
+	interval    time.Duration
+	logger      *logger.Logger
+}
+
+// NewViewFlusher creates a new post view flusher
+func NewViewFlusher(viewService *ViewService, interval time.Duration, logger *logger.Logger) *ViewFlusher {
+	return &ViewFlusher{
+		viewService: viewService,
+		interval:    interval,
+		logger:      logger,
+	}
+}
+
+// Run flushes views every interval until ctx is done. Views still buffered
+// when it stops stay in the cache for the next run.
+func (f *ViewFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		f.flush(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// flush flushes the buffered views of every tenant
+func (f *ViewFlusher) flush(ctx context.Context) {
+	count, err := f.viewService.WithContext(tenant.WithoutScope(ctx)).Flush()
+	if err != nil {
+		f.logger.WithError(err).Error("Failed to flush post views")
+		return
+	}
+
+	if count > 0 {
+		f.logger.WithField("count", count).Info("Post views flushed")
+	}
+}
@@ -0,0 +1,114 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// fieldName is the model field that marks a model as tenant-scoped
+const fieldName = "TenantID"
+
+// Plugin scopes every query, update and delete on models with a TenantID
+// field to the tenant in the statement context, and stamps the tenant on
+// created records. Statements on tenant-scoped models fail with ErrNoTenant
+// when the context carries no tenant, unless it was marked WithoutScope.
+// Raw SQL is not scoped.
+type Plugin struct{}
+
+// NewPlugin creates the tenant scoping plugin
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name returns the plugin name
+func (p *Plugin) Name() string {
+	return "tenant"
+}
+
+// Initialize registers the scoping callbacks
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", stampTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+// tenantField returns the TenantID field of the statement's model, if any
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(fieldName)
+}
+
+// scopeTenant restricts the statement to the current tenant's rows
+func scopeTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || IsUnscoped(db.Statement.Context) {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  tenantID,
+		},
+	}})
+}
+
+// stampTenant sets the tenant on records being created
+func stampTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || IsUnscoped(db.Statement.Context) {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+		return
+	}
+
+	ctx := db.Statement.Context
+	stamp := func(record reflect.Value) {
+		if value, isZero := field.ValueOf(ctx, record); !isZero {
+			if current, ok := value.(*uint); !ok || *current != tenantID {
+				_ = db.AddError(ErrTenantMismatch)
+				return
+			}
+		}
+		id := tenantID
+		if err := field.Set(ctx, record, &id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			stamp(reflect.Indirect(db.Statement.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(db.Statement.ReflectValue)
+	}
+}
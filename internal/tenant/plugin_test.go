@@ -0,0 +1,164 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// note is a tenant-scoped model
+type note struct {
+	ID       uint
+	TenantID *uint
+	Body     string
+}
+
+// setting is a model shared by every tenant
+type setting struct {
+	ID   uint
+	Name string
+}
+
+// newScopedDB returns an in-memory database with the plugin and one note in
+// each of tenants 1 and 2
+func newScopedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	// Every connection to :memory: opens a separate database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.Use(NewPlugin()))
+	require.NoError(t, db.AutoMigrate(&note{}, &setting{}))
+
+	for _, tenantID := range []uint{1, 2} {
+		ctx := WithTenant(context.Background(), tenantID)
+		require.NoError(t, db.WithContext(ctx).Create(&note{Body: "hello"}).Error)
+	}
+	return db
+}
+
+// tenantNotes returns the bodies of every note in a tenant, ignoring scoping
+func tenantNotes(t *testing.T, db *gorm.DB, tenantID uint) []string {
+	t.Helper()
+	var bodies []string
+	err := db.WithContext(WithoutScope(context.Background())).Model(&note{}).
+		Where("tenant_id = ?", tenantID).Order("id").Pluck("body", &bodies).Error
+	require.NoError(t, err)
+	return bodies
+}
+
+func TestPluginScopesQueries(t *testing.T) {
+	db := newScopedDB(t)
+	scoped := db.WithContext(WithTenant(context.Background(), 1))
+
+	var notes []note
+	require.NoError(t, scoped.Find(&notes).Error)
+	require.Len(t, notes, 1)
+	assert.Equal(t, uint(1), *notes[0].TenantID)
+
+	var count int64
+	require.NoError(t, scoped.Model(&note{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Another tenant's rows are not found even by ID
+	var other note
+	err := scoped.First(&other, notes[0].ID+1).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var bodies []string
+	require.NoError(t, scoped.Model(&note{}).Pluck("body", &bodies).Error)
+	assert.Len(t, bodies, 1)
+}
+
+func TestPluginScopesUpdatesAndDeletes(t *testing.T) {
+	db := newScopedDB(t)
+	scoped := db.WithContext(WithTenant(context.Background(), 1))
+
+	result := scoped.Model(&note{}).Where("body = ?", "hello").Update("body", "changed")
+	require.NoError(t, result.Error)
+	assert.Equal(t, int64(1), result.RowsAffected)
+	assert.Equal(t, []string{"changed"}, tenantNotes(t, db, 1))
+	assert.Equal(t, []string{"hello"}, tenantNotes(t, db, 2))
+
+	result = scoped.Where("1 = 1").Delete(&note{})
+	require.NoError(t, result.Error)
+	assert.Equal(t, int64(1), result.RowsAffected)
+	assert.Empty(t, tenantNotes(t, db, 1))
+	assert.Equal(t, []string{"hello"}, tenantNotes(t, db, 2))
+}
+
+func TestPluginStampsCreates(t *testing.T) {
+	db := newScopedDB(t)
+	scoped := db.WithContext(WithTenant(context.Background(), 2))
+
+	created := &note{Body: "new"}
+	require.NoError(t, scoped.Create(created).Error)
+	require.NotNil(t, created.TenantID)
+	assert.Equal(t, uint(2), *created.TenantID)
+
+	batch := []note{{Body: "a"}, {Body: "b"}}
+	require.NoError(t, scoped.Create(&batch).Error)
+	for _, n := range batch {
+		assert.Equal(t, uint(2), *n.TenantID)
+	}
+
+	// Records cannot be created in another tenant
+	one := uint(1)
+	err := scoped.Create(&note{TenantID: &one, Body: "smuggled"}).Error
+	assert.ErrorIs(t, err, ErrTenantMismatch)
+	assert.Equal(t, []string{"hello"}, tenantNotes(t, db, 1))
+}
+
+func TestPluginRequiresTenant(t *testing.T) {
+	db := newScopedDB(t).WithContext(context.Background())
+
+	assert.ErrorIs(t, db.Find(&[]note{}).Error, ErrNoTenant)
+	assert.ErrorIs(t, db.Model(&note{}).Count(new(int64)).Error, ErrNoTenant)
+	assert.ErrorIs(t, db.Create(&note{Body: "new"}).Error, ErrNoTenant)
+	assert.ErrorIs(t, db.Model(&note{}).Where("1 = 1").Update("body", "changed").Error, ErrNoTenant)
+	assert.ErrorIs(t, db.Where("1 = 1").Delete(&note{}).Error, ErrNoTenant)
+
+	// A zero tenant is no tenant
+	zero := db.WithContext(WithTenant(context.Background(), 0))
+	assert.ErrorIs(t, zero.Find(&[]note{}).Error, ErrNoTenant)
+
+	// Models without a tenant are not scoped
+	require.NoError(t, db.Create(&setting{Name: "theme"}).Error)
+	var settings []setting
+	require.NoError(t, db.Find(&settings).Error)
+	assert.Len(t, settings, 1)
+}
+
+func TestPluginWithoutScope(t *testing.T) {
+	db := newScopedDB(t)
+	unscoped := db.WithContext(WithoutScope(context.Background()))
+
+	var notes []note
+	require.NoError(t, unscoped.Find(&notes).Error)
+	assert.Len(t, notes, 2)
+
+	// Creates keep whatever tenant they were given
+	require.NoError(t, unscoped.Create(&note{Body: "platform"}).Error)
+	var count int64
+	require.NoError(t, unscoped.Model(&note{}).Where("tenant_id IS NULL").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// ForTenant scopes a system job to one tenant again
+	forTenant := db.WithContext(ForTenant(WithoutScope(context.Background()), 2))
+	notes = nil
+	require.NoError(t, forTenant.Find(&notes).Error)
+	require.Len(t, notes, 1)
+	assert.Equal(t, uint(2), *notes[0].TenantID)
+}
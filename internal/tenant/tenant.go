@@ -0,0 +1,57 @@
+// Package tenant carries the current organization through request contexts
+// and scopes database access to it.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNoTenant is returned when tenant-scoped data is accessed without a
+	// tenant in the context
+	ErrNoTenant = errors.New("tenant: no tenant in context")
+
+	// ErrTenantMismatch is returned when creating a record that belongs to a
+	// different tenant than the one in the context
+	ErrTenantMismatch = errors.New("tenant: record belongs to another tenant")
+)
+
+type tenantKey struct{}
+
+type unscopedKey struct{}
+
+// WithTenant returns a context scoped to the tenant
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant the context is scoped to
+func FromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// WithoutScope returns a context that bypasses tenant scoping. It is meant
+// for system jobs and platform-level operations that span all tenants.
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped reports whether the context bypasses tenant scoping
+func IsUnscoped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// ForTenant returns a context scoped to the tenant even when ctx bypasses
+// tenant scoping, for system jobs acting on one tenant's behalf
+func ForTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(WithTenant(ctx, tenantID), unscopedKey{}, false)
+}
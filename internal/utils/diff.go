@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffOp is the kind of change a diff segment represents
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffSegment is a run of text that is unchanged, inserted or deleted
+type DiffSegment struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// wordTokens matches runs of whitespace and runs of other characters
+var wordTokens = regexp.MustCompile(`\s+|\S+`)
+
+// UnifiedDiff returns a line-based unified diff from a to b with three lines
+// of context
+func UnifiedDiff(a, b, fromName, toName string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// splitLines splits text into lines ending in a newline. Unlike
+// difflib.SplitLines it adds no empty line after a final newline.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n"
+	return lines
+}
+
+// WordDiff returns the word-level changes from a to b. Joining the equal and
+// delete segments gives a; joining the equal and insert segments gives b.
+func WordDiff(a, b string) []DiffSegment {
+	from := wordTokens.FindAllString(a, -1)
+	to := wordTokens.FindAllString(b, -1)
+
+	var segments []DiffSegment
+	add := func(op DiffOp, tokens []string) {
+		if len(tokens) == 0 {
+			return
+		}
+		text := strings.Join(tokens, "")
+		// Merge with the previous segment of the same kind
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: text})
+	}
+
+	matcher := difflib.NewMatcherWithJunk(from, to, false, nil)
+	for _, code := range matcher.GetOpCodes() {
+		switch code.Tag {
+		case 'e':
+			add(DiffEqual, from[code.I1:code.I2])
+		case 'd':
+			add(DiffDelete, from[code.I1:code.I2])
+		case 'i':
+			add(DiffInsert, to[code.J1:code.J2])
+		case 'r':
+			add(DiffDelete, from[code.I1:code.I2])
+			add(DiffInsert, to[code.J1:code.J2])
+		}
+	}
+	return segments
+}
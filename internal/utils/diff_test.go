@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffSegment
+	}{
+		{
+			name: "identical",
+			a:    "the quick fox",
+			b:    "the quick fox",
+			want: []DiffSegment{{Op: DiffEqual, Text: "the quick fox"}},
+		},
+		{
+			name: "insert",
+			a:    "the fox",
+			b:    "the quick fox",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "the "},
+				{Op: DiffInsert, Text: "quick "},
+				{Op: DiffEqual, Text: "fox"},
+			},
+		},
+		{
+			name: "delete",
+			a:    "the quick brown fox",
+			b:    "the fox",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "the "},
+				{Op: DiffDelete, Text: "quick brown "},
+				{Op: DiffEqual, Text: "fox"},
+			},
+		},
+		{
+			name: "replace",
+			a:    "the quick fox",
+			b:    "the slow fox",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "the "},
+				{Op: DiffDelete, Text: "quick"},
+				{Op: DiffInsert, Text: "slow"},
+				{Op: DiffEqual, Text: " fox"},
+			},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "new text",
+			want: []DiffSegment{{Op: DiffInsert, Text: "new text"}},
+		},
+		{
+			name: "both empty",
+			a:    "",
+			b:    "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := WordDiff(tt.a, tt.b)
+			assert.Equal(t, tt.want, segments)
+
+			// The segments rebuild both sides
+			var a, b strings.Builder
+			for _, segment := range segments {
+				if segment.Op != DiffInsert {
+					a.WriteString(segment.Text)
+				}
+				if segment.Op != DiffDelete {
+					b.WriteString(segment.Text)
+				}
+			}
+			assert.Equal(t, tt.a, a.String())
+			assert.Equal(t, tt.b, b.String())
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	diff, err := UnifiedDiff("one\ntwo\nthree\n", "one\n2\nthree\nfour\n", "revision 1", "revision 2")
+	require.NoError(t, err)
+	assert.Equal(t, `--- revision 1
++++ revision 2
+@@ -1,3 +1,4 @@
+ one
+-two
++2
+ three
++four
+`, diff)
+
+	diff, err = UnifiedDiff("same\n", "same\n", "revision 1", "revision 2")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestUnifiedDiffWithoutFinalNewline(t *testing.T) {
+	diff, err := UnifiedDiff("one\ntwo", "one\nthree", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,2 +1,2 @@\n one\n-two\n+three\n", diff)
+
+	diff, err = UnifiedDiff("", "new", "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n", diff)
+}
@@ -17,6 +17,8 @@ type JWTClaims struct {
 	Email    string      `json:"email"`
 	Username string      `json:"username"`
 	Role     models.Role `json:"role"`
+	// TenantID is the organization the token is scoped to, if any
+	TenantID *uint `json:"tenant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -36,11 +38,22 @@ func NewJWTService(cfg *config.Config) *JWTService {
 
 // GenerateToken generates a new JWT token for a user
 func (j *JWTService) GenerateToken(user *models.User) (string, error) {
+	return j.generate(user, nil)
+}
+
+// GenerateTenantToken generates a JWT token for a user scoped to an organization
+func (j *JWTService) GenerateTenantToken(user *models.User, tenantID uint) (string, error) {
+	return j.generate(user, &tenantID)
+}
+
+// generate signs a token for the user with an optional tenant claim
+func (j *JWTService) generate(user *models.User, tenantID *uint) (string, error) {
 	claims := &JWTClaims{
 		UserID:   user.ID,
 		Email:    user.Email,
 		Username: user.Username,
 		Role:     user.Role,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -87,6 +100,7 @@ func (j *JWTService) RefreshToken(tokenString string) (string, error) {
 		Email:    claims.Email,
 		Username: claims.Username,
 		Role:     claims.Role,
+		TenantID: claims.TenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
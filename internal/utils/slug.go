@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength is the maximum number of characters in a generated slug
+const MaxSlugLength = 100
+
+// Slugify converts text to a lowercase URL slug. Accents are stripped from
+// Latin letters, letters and digits of other scripts are kept, and anything
+// else collapses into single hyphens, e.g. "Crème Brûlée!" becomes
+// "creme-brulee" and "Привет, мир" becomes "привет-мир".
+func Slugify(text string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	latinBase := false
+	length := 0
+
+	for _, r := range norm.NFKD.String(text) {
+		switch {
+		case unicode.In(r, unicode.M):
+			// Drop accents on Latin letters, keep marks other scripts need
+			if latinBase || b.Len() == 0 || pendingHyphen {
+				continue
+			}
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			hyphen := pendingHyphen && b.Len() > 0
+			if hyphen && length+2 > MaxSlugLength || length >= MaxSlugLength {
+				return norm.NFC.String(b.String())
+			}
+			if hyphen {
+				b.WriteByte('-')
+				length++
+			}
+			pendingHyphen = false
+			latinBase = unicode.Is(unicode.Latin, r)
+			b.WriteRune(unicode.ToLower(r))
+			length++
+		default:
+			pendingHyphen = true
+			latinBase = false
+		}
+	}
+
+	return norm.NFC.String(b.String())
+}
+
+// NumberedSlug appends "-n" to a slug, trimming the slug so the result stays
+// within MaxSlugLength characters
+func NumberedSlug(slug string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	if runes := []rune(slug); len(runes)+len(suffix) > MaxSlugLength {
+		slug = strings.TrimRight(string(runes[:MaxSlugLength-len(suffix)]), "-")
+	}
+	return slug + suffix
+}
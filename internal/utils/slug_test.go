@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"ascii", "Hello World", "hello-world"},
+		{"accents", "Crème Brûlée!", "creme-brulee"},
+		{"german", "Über Größe", "uber-große"},
+		{"cyrillic", "Привет, мир", "привет-мир"},
+		{"cjk", "東京 タワー", "東京-タワー"},
+		{"devanagari marks", "नमस्ते दुनिया", "नमस्ते-दुनिया"},
+		{"punctuation collapses", "  --Go!!  is   fun?? ", "go-is-fun"},
+		{"digits", "Top 10 tips (2024)", "top-10-tips-2024"},
+		{"ligature", "ﬁle ﬂow", "file-flow"},
+		{"empty", "", ""},
+		{"only punctuation", "!?-- ..", ""},
+		{"only marks", "́́", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Slugify(tt.text))
+		})
+	}
+}
+
+func TestSlugifyMaxLength(t *testing.T) {
+	slug := Slugify(strings.Repeat("word ", 50))
+	assert.LessOrEqual(t, utf8.RuneCountInString(slug), MaxSlugLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+
+	slug = Slugify(strings.Repeat("ж", MaxSlugLength+20))
+	assert.Equal(t, MaxSlugLength, utf8.RuneCountInString(slug))
+}
+
+func TestNumberedSlug(t *testing.T) {
+	assert.Equal(t, "hello-world-2", NumberedSlug("hello-world", 2))
+
+	long := Slugify(strings.Repeat("ж", MaxSlugLength))
+	slug := NumberedSlug(long, 12)
+	assert.Equal(t, MaxSlugLength, utf8.RuneCountInString(slug))
+	assert.True(t, strings.HasSuffix(slug, "ж-12"))
+
+	// The suffix never follows a hyphen left by trimming
+	slug = NumberedSlug(strings.Repeat("a", MaxSlugLength-3)+"-bc", 2)
+	assert.Equal(t, strings.Repeat("a", MaxSlugLength-3)+"-2", slug)
+}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"strings"
 
+	"go-backend/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
@@ -16,8 +18,15 @@ type Validator struct {
 
 // NewValidator creates a new validator instance
 func NewValidator() *Validator {
+	v := validator.New()
+
+	// role checks the value against the roles known from the roles table
+	v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		return models.Role(fl.Field().String()).IsValid()
+	})
+
 	return &Validator{
-		validator: validator.New(),
+		validator: v,
 	}
 }
 
@@ -83,6 +92,8 @@ func getErrorMessage(err validator.FieldError) string {
 		return fmt.Sprintf("Must be one of: %s", err.Param())
 	case "unique":
 		return "This value already exists"
+	case "role":
+		return "Must be an existing role"
 	default:
 		return fmt.Sprintf("Invalid value for %s", err.Field())
 	}
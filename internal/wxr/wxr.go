@@ -0,0 +1,133 @@
+// Package wxr parses WordPress eXtended RSS (WXR), the format of WordPress
+// exports. Only what is needed to import posts is read: authors, posts and
+// pages with their terms and comments, and attachments.
+package wxr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Post types
+const (
+	TypePost       = "post"
+	TypePage       = "page"
+	TypeAttachment = "attachment"
+)
+
+// Term domains of item categories
+const (
+	DomainCategory = "category"
+	DomainTag      = "post_tag"
+)
+
+// dateLayout is the layout of WordPress dates
+const dateLayout = "2006-01-02 15:04:05"
+
+// Export is a parsed WordPress export. Other elements are matched by their
+// local name, whatever version of the WXR namespace the export uses.
+type Export struct {
+	Authors []Author `xml:"channel>author"`
+	Items   []Item   `xml:"channel>item"`
+}
+
+// Author is a WordPress user
+type Author struct {
+	ID          uint   `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+// Item is a post, page, attachment or any other WordPress post type. The
+// content namespace is matched in full, as excerpts are "encoded" too.
+type Item struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Creator       string     `xml:"creator"`
+	Content       string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	ID            uint       `xml:"post_id"`
+	Date          string     `xml:"post_date"`
+	DateGMT       string     `xml:"post_date_gmt"`
+	Name          string     `xml:"post_name"`
+	Status        string     `xml:"status"`
+	Parent        uint       `xml:"post_parent"`
+	Type          string     `xml:"post_type"`
+	AttachmentURL string     `xml:"attachment_url"`
+	Categories    []Category `xml:"category"`
+	Comments      []Comment  `xml:"comment"`
+}
+
+// Category is a category or tag of an item
+type Category struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// Comment is a comment, pingback or trackback on an item
+type Comment struct {
+	ID          uint   `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Date        string `xml:"comment_date"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      uint   `xml:"comment_parent"`
+	UserID      uint   `xml:"comment_user_id"`
+}
+
+// Parse parses a WordPress export
+func Parse(r io.Reader) (*Export, error) {
+	var export Export
+	decoder := xml.NewDecoder(r)
+	// Exports declare UTF-8, but older ones may use another charset
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "us-ascii") {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+		return input, nil
+	}
+	if err := decoder.Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid WordPress export: %w", err)
+	}
+	return &export, nil
+}
+
+// Terms returns the item's categories in a domain
+func (i *Item) Terms(domain string) []Category {
+	var terms []Category
+	for _, category := range i.Categories {
+		if category.Domain == domain {
+			terms = append(terms, category)
+		}
+	}
+	return terms
+}
+
+// Time returns when the item was written
+func (i *Item) Time() (time.Time, bool) {
+	return parseDate(i.DateGMT, i.Date)
+}
+
+// Time returns when the comment was written
+func (c *Comment) Time() (time.Time, bool) {
+	return parseDate(c.DateGMT, c.Date)
+}
+
+// parseDate parses a WordPress date, preferring the GMT one. Drafts have a
+// zero GMT date, so the local date is read as UTC instead.
+func parseDate(gmt, local string) (time.Time, bool) {
+	if t, err := time.Parse(dateLayout, strings.TrimSpace(gmt)); err == nil && t.Year() > 1 {
+		return t, true
+	}
+	if t, err := time.Parse(dateLayout, strings.TrimSpace(local)); err == nil && t.Year() > 1 {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,143 @@
+package wxr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Blog</title>
+	<wp:author>
+		<wp:author_id>2</wp:author_id>
+		<wp:author_login><![CDATA[ann]]></wp:author_login>
+		<wp:author_email><![CDATA[ann@example.com]]></wp:author_email>
+		<wp:author_display_name><![CDATA[Ann Author]]></wp:author_display_name>
+	</wp:author>
+	<item>
+		<title>Hello &amp; welcome</title>
+		<dc:creator><![CDATA[ann]]></dc:creator>
+		<content:encoded><![CDATA[<p>Hello <b>world</b></p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[Short]]></excerpt:encoded>
+		<wp:post_id>10</wp:post_id>
+		<wp:post_date><![CDATA[2024-03-01 10:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2024-03-01 09:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[hello-welcome]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_parent>0</wp:post_parent>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<wp:comment>
+			<wp:comment_id>5</wp:comment_id>
+			<wp:comment_author><![CDATA[Visitor]]></wp:comment_author>
+			<wp:comment_author_email><![CDATA[visitor@example.com]]></wp:comment_author_email>
+			<wp:comment_date><![CDATA[2024-03-02 08:00:00]]></wp:comment_date>
+			<wp:comment_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:comment_date_gmt>
+			<wp:comment_content><![CDATA[Nice]]></wp:comment_content>
+			<wp:comment_approved><![CDATA[1]]></wp:comment_approved>
+			<wp:comment_type><![CDATA[comment]]></wp:comment_type>
+			<wp:comment_parent>0</wp:comment_parent>
+			<wp:comment_user_id>0</wp:comment_user_id>
+		</wp:comment>
+	</item>
+	<item>
+		<title>photo</title>
+		<wp:post_id>11</wp:post_id>
+		<wp:post_parent>10</wp:post_parent>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://blog.example.com/wp-content/uploads/2024/03/photo.jpg]]></wp:attachment_url>
+	</item>
+</channel>
+</rss>`
+
+func TestParse(t *testing.T) {
+	export, err := Parse(strings.NewReader(sampleExport))
+	require.NoError(t, err)
+
+	require.Len(t, export.Authors, 1)
+	assert.Equal(t, Author{ID: 2, Login: "ann", Email: "ann@example.com", DisplayName: "Ann Author"}, export.Authors[0])
+
+	require.Len(t, export.Items, 2)
+	post := export.Items[0]
+	assert.Equal(t, "Hello & welcome", post.Title)
+	assert.Equal(t, "ann", post.Creator)
+	assert.Equal(t, "<p>Hello <b>world</b></p>", post.Content, "excerpts must not be read as content")
+	assert.Equal(t, uint(10), post.ID)
+	assert.Equal(t, "hello-welcome", post.Name)
+	assert.Equal(t, "publish", post.Status)
+	assert.Equal(t, TypePost, post.Type)
+	assert.Equal(t, []Category{{Domain: DomainCategory, Nicename: "news", Name: "News"}}, post.Terms(DomainCategory))
+	assert.Equal(t, []Category{{Domain: DomainTag, Nicename: "go", Name: "Go"}}, post.Terms(DomainTag))
+
+	date, ok := post.Time()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), date)
+
+	require.Len(t, post.Comments, 1)
+	comment := post.Comments[0]
+	assert.Equal(t, uint(5), comment.ID)
+	assert.Equal(t, "visitor@example.com", comment.AuthorEmail)
+	assert.Equal(t, "1", comment.Approved)
+	date, ok = comment.Time()
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC), date, "a zero GMT date falls back to the local one")
+
+	attachment := export.Items[1]
+	assert.Equal(t, TypeAttachment, attachment.Type)
+	assert.Equal(t, uint(10), attachment.Parent)
+	assert.Equal(t, "https://blog.example.com/wp-content/uploads/2024/03/photo.jpg", attachment.AttachmentURL)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not XML", "hello"},
+		{"truncated", `<rss><channel><item><title>Hello`},
+		{"unsupported charset", `<?xml version="1.0" encoding="ISO-8859-1"?><rss><channel></channel></rss>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid WordPress export")
+		})
+	}
+}
+
+func TestItemTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		gmt   string
+		local string
+		want  time.Time
+		ok    bool
+	}{
+		{"gmt", "2024-01-02 03:04:05", "2024-01-02 05:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{"draft", "0000-00-00 00:00:00", "2024-01-02 05:04:05", time.Date(2024, 1, 2, 5, 4, 5, 0, time.UTC), true},
+		{"padded", " 2024-01-02 03:04:05 ", "", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{"missing", "", "", time.Time{}, false},
+		{"malformed", "yesterday", "0000-00-00 00:00:00", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Item{DateGMT: tt.gmt, Date: tt.local}
+			got, ok := item.Time()
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}